@@ -2,6 +2,7 @@ package global
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
@@ -10,6 +11,9 @@ import (
 // Conf 全局变量，用来保存程序的所有配置信息
 var Conf = new(AppConfig)
 
+// configChangeHooks 配置文件修改并重新读取后执行的函数
+var configChangeHooks []func()
+
 // 定义环境常量
 const (
 	ModeDev  = "dev"
@@ -24,11 +28,28 @@ type AppConfig struct {
 	MachineID int64  `mapstructure:"machine_id"`
 	Port      int    `mapstructure:"port"`
 
+	// 受信任的反向代理地址（IP 或 CIDR），只有来自这些地址的请求才使用 X-Forwarded-For/X-Real-IP 中的客户端 IP
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
 	*LogConfig   `mapstructure:"log"`
 	*MySQLConfig `mapstructure:"mysql"`
 	*RedisConfig `mapstructure:"redis"`
-	Avatar       AvatarConfig  `mapstructure:"avatar"`
-	Swagger      SwaggerConfig `mapstructure:"swagger"`
+	Avatar       AvatarConfig       `mapstructure:"avatar"`
+	Swagger      SwaggerConfig      `mapstructure:"swagger"`
+	Archive      ArchiveConfig      `mapstructure:"archive"`
+	Ranking      RankingConfig      `mapstructure:"ranking"`
+	CommentTree  CommentTreeConfig  `mapstructure:"comment_tree"`
+	Password     PasswordConfig     `mapstructure:"password"`
+	JWT          JWTConfig          `mapstructure:"jwt"`
+	Mail         MailConfig         `mapstructure:"mail"`
+	Account      AccountConfig      `mapstructure:"account"`
+	LoginGuard   LoginGuardConfig   `mapstructure:"login_guard"`
+	RateLimit    RateLimitConfig    `mapstructure:"rate_limit"`
+	ContentGuard ContentGuardConfig `mapstructure:"content_guard"`
+	Sensitive    SensitiveConfig    `mapstructure:"sensitive"`
+	Notification NotificationConfig `mapstructure:"notification"`
+	Push         PushConfig         `mapstructure:"push"`
+	Feed         FeedConfig         `mapstructure:"feed"`
 }
 
 type LogConfig struct {
@@ -74,6 +95,153 @@ type SwaggerConfig struct {
 	} `mapstructure:"domain"`
 }
 
+// ArchiveConfig 投票数据归档配置
+type ArchiveConfig struct {
+	Enable    bool          `mapstructure:"enable"`     // 是否启用归档任务
+	Interval  time.Duration `mapstructure:"interval"`   // 扫描间隔
+	BatchSize int64         `mapstructure:"batch_size"` // 每批处理的数量
+}
+
+// RankingConfig 帖子排序算法配置
+type RankingConfig struct {
+	VoteScore       float64       `mapstructure:"vote_score"`       // 简化版投票分数：每一票的分值
+	HotEpoch        int64         `mapstructure:"hot_epoch"`        // Reddit hot 算法的时间起点（Unix 时间戳）
+	HotDivisor      float64       `mapstructure:"hot_divisor"`      // Reddit hot 算法的时间权重（秒）
+	Gravity         float64       `mapstructure:"gravity"`          // Hacker News 算法的重力因子
+	WilsonZ         float64       `mapstructure:"wilson_z"`         // Wilson 置信区间的 z 值
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"` // 重力衰减分数的刷新间隔
+}
+
+// CommentTreeConfig 评论树配置
+type CommentTreeConfig struct {
+	DefaultDepth int     `mapstructure:"default_depth"`  // 默认返回的层数（一级评论为第1层）
+	MaxDepth     int     `mapstructure:"max_depth"`      // 允许请求的最大层数
+	ReplySizes   []int64 `mapstructure:"reply_sizes"`    // 默认每层回复的数量（从第2层开始，层数超出时沿用最后一个值）
+	MaxReplySize int64   `mapstructure:"max_reply_size"` // 每层回复数量的上限
+}
+
+// PasswordConfig 密码哈希配置
+type PasswordConfig struct {
+	Algorithm     string `mapstructure:"algorithm"`      // 哈希算法：bcrypt/argon2id
+	BcryptCost    int    `mapstructure:"bcrypt_cost"`    // bcrypt 的计算成本
+	Argon2Memory  int    `mapstructure:"argon2_memory"`  // argon2id 使用的内存（KiB）
+	Argon2Time    int    `mapstructure:"argon2_time"`    // argon2id 的迭代次数
+	Argon2Threads int    `mapstructure:"argon2_threads"` // argon2id 的并行度
+}
+
+// JWTConfig JWT 签名配置
+type JWTConfig struct {
+	Issuer             string         `mapstructure:"issuer"`               // 签发人
+	AccessTokenExpire  time.Duration  `mapstructure:"access_token_expire"`  // access_token 过期时间
+	RefreshTokenExpire time.Duration  `mapstructure:"refresh_token_expire"` // refresh_token 过期时间
+	ActiveKID          string         `mapstructure:"active_kid"`           // 当前用于签名的密钥 kid，为空时使用第一个密钥
+	Keys               []JWTKeyConfig `mapstructure:"keys"`                 // 密钥环，轮换后保留旧密钥用于验证未过期的 token
+}
+
+// JWTKeyConfig JWT 签名密钥
+type JWTKeyConfig struct {
+	KID            string `mapstructure:"kid"`              // 密钥 id，写入 token 头部的 kid
+	Algorithm      string `mapstructure:"algorithm"`        // 签名算法：HS256/RS256/EdDSA
+	Secret         string `mapstructure:"secret"`           // HS256 的密钥，不要提交到仓库
+	SecretEnv      string `mapstructure:"secret_env"`       // secret 为空时从该环境变量读取 HS256 的密钥
+	PrivateKeyFile string `mapstructure:"private_key_file"` // RS256/EdDSA 的私钥文件（PEM），只用于验证的旧密钥可以不设置
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // RS256/EdDSA 的公钥文件（PEM），为空时由私钥导出
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver string `mapstructure:"driver"` // 发送方式：smtp/file/log
+	From   string `mapstructure:"from"`   // 发件人
+	Dir    string `mapstructure:"dir"`    // file 方式保存邮件的目录
+	SMTP   struct {
+		Host     string `mapstructure:"host"`
+		Port     int    `mapstructure:"port"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"smtp"`
+}
+
+// AccountConfig 邮箱验证和找回密码配置
+type AccountConfig struct {
+	VerifyURL         string        `mapstructure:"verify_url"`          // 邮箱验证链接，token 拼接在末尾
+	ResetURL          string        `mapstructure:"reset_url"`           // 重置密码链接，token 拼接在末尾
+	VerifyTokenExpire time.Duration `mapstructure:"verify_token_expire"` // 邮箱验证 token 的有效期
+	ResetTokenExpire  time.Duration `mapstructure:"reset_token_expire"`  // 重置密码 token 的有效期
+}
+
+// LoginGuardConfig 登录防暴力破解配置
+type LoginGuardConfig struct {
+	Enable        bool          `mapstructure:"enable"`          // 是否启用
+	MaxAttempts   int64         `mapstructure:"max_attempts"`    // 同一用户名连续失败多少次后开始锁定
+	IPMaxAttempts int64         `mapstructure:"ip_max_attempts"` // 同一 IP 连续失败多少次后开始锁定
+	Window        time.Duration `mapstructure:"window"`          // 失败次数的统计窗口（最后一次失败后多久清零）
+	BaseLockout   time.Duration `mapstructure:"base_lockout"`    // 首次锁定的时长，之后每次失败翻倍
+	MaxLockout    time.Duration `mapstructure:"max_lockout"`     // 锁定时长的上限
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enable   bool                       `mapstructure:"enable"`   // 是否启用
+	Policies map[string]RateLimitPolicy `mapstructure:"policies"` // 限流策略，路由按名称引用
+}
+
+// RateLimitPolicy 限流策略：每个用户（未登录时按 IP）在 period 内最多 limit 次请求
+type RateLimitPolicy struct {
+	Limit  int64         `mapstructure:"limit"`
+	Period time.Duration `mapstructure:"period"`
+}
+
+// ContentGuardConfig 反垃圾内容配置
+type ContentGuardConfig struct {
+	Enable            bool              `mapstructure:"enable"`              // 是否启用
+	DuplicateWindow   time.Duration     `mapstructure:"duplicate_window"`    // 重复内容检测的时间窗口
+	DuplicateDistance int               `mapstructure:"duplicate_distance"`  // 指纹汉明距离不超过该值视为重复内容
+	NewAccountAge     time.Duration     `mapstructure:"new_account_age"`     // 注册时长低于该值且 karma 未达标的为新账号
+	NewAccountKarma   int64             `mapstructure:"new_account_karma"`   // 帖子净票数之和达到该值后不再按新账号限制
+	Post              ContentGuardLimit `mapstructure:"post"`                // 发帖限制
+	Comment           ContentGuardLimit `mapstructure:"comment"`             // 评论限制
+	NewAccountPost    ContentGuardLimit `mapstructure:"new_account_post"`    // 新账号发帖限制
+	NewAccountComment ContentGuardLimit `mapstructure:"new_account_comment"` // 新账号评论限制
+}
+
+// ContentGuardLimit 发布频率限制
+type ContentGuardLimit struct {
+	Interval   time.Duration `mapstructure:"interval"`    // 两次发布的最小间隔
+	DailyLimit int64         `mapstructure:"daily_limit"` // 24 小时内最多发布的次数，0 表示不限制
+}
+
+// SensitiveConfig 敏感词过滤配置
+type SensitiveConfig struct {
+	Enable bool                  `mapstructure:"enable"` // 是否启用
+	Lists  []SensitiveListConfig `mapstructure:"lists"`  // 词库
+}
+
+// SensitiveListConfig 敏感词库
+type SensitiveListConfig struct {
+	Name   string `mapstructure:"name"`
+	File   string `mapstructure:"file"`   // 词库文件，每行一个敏感词
+	Action string `mapstructure:"action"` // 命中后的处理方式：reject(拒绝)、mask(替换为 *)、review(加入审核队列)
+}
+
+// NotificationConfig 通知配置
+type NotificationConfig struct {
+	Milestones []int64 `mapstructure:"milestones"` // 帖子/评论的赞成票数达到这些值时通知作者
+}
+
+// PushConfig 实时推送配置
+type PushConfig struct {
+	Heartbeat   time.Duration `mapstructure:"heartbeat"`    // 心跳间隔，避免代理关闭空闲连接
+	MaxLifetime time.Duration `mapstructure:"max_lifetime"` // 连接的最长时间，到期后断开由客户端重新连接（重新认证）
+	BufferSize  int           `mapstructure:"buffer_size"`  // 每个连接缓冲的消息数量，客户端消费过慢时断开连接
+}
+
+// FeedConfig 关注动态配置
+type FeedConfig struct {
+	BigAccountFollowers int64 `mapstructure:"big_account_followers"` // 粉丝数量达到该值的用户发帖时不推送到粉丝的时间线，由粉丝读取时合并
+	TimelineSize        int64 `mapstructure:"timeline_size"`         // 每个时间线保留的帖子数量
+	FanoutBatchSize     int64 `mapstructure:"fanout_batch_size"`     // 推送时每批读取的粉丝数量
+}
+
 // IsDevMode 判断是否为开发环境
 func (c *AppConfig) IsDevMode() bool {
 	return c.Mode == ModeDev
@@ -133,6 +301,14 @@ func Init() (err error) {
 		if err := viper.Unmarshal(Conf); err != nil {
 			panic(fmt.Errorf("Unmarshal failed, err:%v\n", err))
 		}
+		for _, hook := range configChangeHooks {
+			hook()
+		}
 	})
 	return err
 }
+
+// OnConfigChange 注册配置文件修改后执行的函数（需要在 Init 之后、服务启动之前调用）
+func OnConfigChange(hook func()) {
+	configChangeHooks = append(configChangeHooks, hook)
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"errors"
+	"go_community/internal/models"
+	"go_community/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// VerifyEmailHandler 验证邮箱
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的token完成邮箱验证，token只能使用一次
+// @Tags 用户相关接口
+// @Produce application/json
+// @Param token query string true "验证token"
+// @Success 1000 {object} ResponseData
+// @Failure 1020 {object} ResponseData "链接无效或已过期"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /email/verify [get]
+func VerifyEmailHandler(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	if err := service.VerifyEmail(token); err != nil {
+		zap.L().Error("logic.VerifyEmail failed", zap.Error(err))
+		if errors.Is(err, service.ErrorInvalidAccountToken) {
+			ResponseError(c, CodeInvalidEmailToken)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// ResendVerifyEmailHandler 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Description 向当前登录用户的邮箱重新发送验证邮件，之前的验证链接在过期前仍然有效
+// @Tags 用户相关接口
+// @Produce application/json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 1000 {object} ResponseData
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1021 {object} ResponseData "邮箱已验证"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /email/verify/resend [post]
+func ResendVerifyEmailHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	if err := service.ResendVerifyEmail(userID); err != nil {
+		zap.L().Error("logic.ResendVerifyEmail failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrorEmailVerified):
+			ResponseError(c, CodeEmailVerified)
+		case errors.Is(err, service.ErrorEmailNotSet):
+			ResponseErrorWithMsg(c, CodeInvalidParams, service.ErrorEmailNotSet.Error())
+		default:
+			ResponseError(c, CodeServerBusy)
+		}
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// ForgotPasswordHandler 找回密码
+// @Summary 找回密码
+// @Description 向注册邮箱发送重置密码链接；邮箱未注册时同样返回成功
+// @Tags 用户相关接口
+// @Accept application/json
+// @Produce application/json
+// @Param object body models.ParamForgotPassword true "邮箱"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /password/forgot [post]
+func ForgotPasswordHandler(c *gin.Context) {
+	p := new(models.ParamForgotPassword)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("ForgotPasswordHandler with invalid param", zap.Error(err))
+		// 判断 err 是否为 validator.ValidationErrors 类型
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParams)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParams, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	if err := service.ForgotPassword(p); err != nil {
+		zap.L().Error("logic.ForgotPassword failed", zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// ResetPasswordHandler 重置密码
+// @Summary 重置密码
+// @Description 使用重置邮件中的token设置新密码，token只能使用一次；重置后之前签发的所有token失效
+// @Tags 用户相关接口
+// @Accept application/json
+// @Produce application/json
+// @Param object body models.ParamResetPassword true "重置密码参数"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1020 {object} ResponseData "链接无效或已过期"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /password/reset [post]
+func ResetPasswordHandler(c *gin.Context) {
+	p := new(models.ParamResetPassword)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("ResetPasswordHandler with invalid param", zap.Error(err))
+		// 判断 err 是否为 validator.ValidationErrors 类型
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParams)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParams, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	if err := service.ResetPassword(p); err != nil {
+		zap.L().Error("logic.ResetPassword failed", zap.Error(err))
+		if errors.Is(err, service.ErrorInvalidAccountToken) {
+			ResponseError(c, CodeInvalidEmailToken)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
@@ -26,6 +26,25 @@ const (
 	CodeCommunityExist    MyCode = 1015
 	CodeCommunityNotExist MyCode = 1016
 	CodeCommunityHasPost  MyCode = 1017
+
+	CodeVoteNotExist MyCode = 1018
+
+	CodeEmailExist        MyCode = 1019
+	CodeInvalidEmailToken MyCode = 1020
+	CodeEmailVerified     MyCode = 1021
+
+	CodeLoginLocked MyCode = 1022
+
+	CodeTooManyRequests MyCode = 1023
+
+	CodePublishTooFrequent MyCode = 1024
+	CodeDuplicateContent   MyCode = 1025
+
+	CodeSensitiveWord MyCode = 1026
+
+	CodeReportExist   MyCode = 1027
+	CodeReportClaimed MyCode = 1028
+	CodeUserBanned    MyCode = 1029
 )
 
 var msgFlags = map[MyCode]string{
@@ -49,6 +68,25 @@ var msgFlags = map[MyCode]string{
 	CodeCommunityExist:    "社区名称已存在",
 	CodeCommunityNotExist: "社区不存在",
 	CodeCommunityHasPost:  "该社区下还有帖子，无法删除",
+
+	CodeVoteNotExist: "尚未投票，无法撤销",
+
+	CodeEmailExist:        "邮箱已被注册",
+	CodeInvalidEmailToken: "链接无效或已过期",
+	CodeEmailVerified:     "邮箱已验证",
+
+	CodeLoginLocked: "登录失败次数过多，请稍后再试",
+
+	CodeTooManyRequests: "请求过于频繁，请稍后再试",
+
+	CodePublishTooFrequent: "发布过于频繁，请稍后再试",
+	CodeDuplicateContent:   "请勿重复发布相似的内容",
+
+	CodeSensitiveWord: "内容包含敏感词",
+
+	CodeReportExist:   "已举报过该内容，请等待处理",
+	CodeReportClaimed: "该举报已被其他人认领",
+	CodeUserBanned:    "账号已被封禁",
 }
 
 func (c MyCode) Msg() string {
@@ -22,6 +22,9 @@ import (
 // @Success 1000 {object} ResponseData
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1024 {object} ResponseData "发布过于频繁（data.retry_after 为需要等待的秒数）"
+// @Failure 1025 {object} ResponseData "重复内容"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /comment [post]
 func CreateCommentHandler(c *gin.Context) {
@@ -55,6 +58,13 @@ func CreateCommentHandler(c *gin.Context) {
 			ResponseError(c, CodeInvalidParams)
 			return
 		}
+		if err == service.ErrorSensitiveWord {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
+		if responsePublishGuardError(c, err) {
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -75,6 +85,7 @@ func CreateCommentHandler(c *gin.Context) {
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1008 {object} ResponseData "未登录"
 // @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /comment [put]
 func UpdateCommentHandler(c *gin.Context) {
@@ -107,6 +118,10 @@ func UpdateCommentHandler(c *gin.Context) {
 			ResponseError(c, CodeNoPermission)
 			return
 		}
+		if err == service.ErrorSensitiveWord {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -120,10 +135,12 @@ func UpdateCommentHandler(c *gin.Context) {
 // @Tags 评论相关接口
 // @Accept application/json
 // @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
 // @Param post_id query int false "帖子ID(获取帖子评论时必填)"
 // @Param comment_id query int false "评论ID(获取评论回复时必填)"
 // @Param page query int false "页码" minimum(1) default(1)
 // @Param size query int false "每页数量" minimum(1) maximum(100) default(10)
+// @Param order query string false "帖子评论的排序方式" Enums(new, old, top, best) default(new)
 // @Success 1000 {object} _ResponseCommentList
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1005 {object} ResponseData "服务繁忙"
@@ -145,12 +162,13 @@ func GetCommentListHandler(c *gin.Context) {
 	// 根据参数判断获取类型
 	var data interface{}
 	var err error
+	viewerID, _ := getCurrentUserId(c) // 未登录时为 0
 	if p.PostID != 0 {
 		// 获取帖子评论列表
-		data, err = service.GetCommentList(p.PostID, p.Page, p.Size)
+		data, err = service.GetCommentList(p, viewerID)
 	} else {
 		// 获取评论回复列表
-		data, err = service.GetCommentReplyList(p.CommentID)
+		data, err = service.GetCommentReplyList(p.CommentID, viewerID)
 	}
 
 	if err != nil {
@@ -161,12 +179,56 @@ func GetCommentListHandler(c *gin.Context) {
 	ResponseSuccess(c, data)
 }
 
+// GetCommentTreeHandler
+// @Summary 获取评论树
+// @Description 按帖子获取嵌套的评论树，或按父评论加载折叠的回复
+// @Tags 评论相关接口
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
+// @Param object query models.ParamCommentTree false "查询参数"
+// @Success 1000 {object} _ResponseCommentTree
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /comments/tree [get]
+func GetCommentTreeHandler(c *gin.Context) {
+	// 获取参数
+	p := &models.ParamCommentTree{}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("GetCommentTreeHandler with invalid params", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+
+	// 参数校验
+	if p.PostID == 0 && p.ParentID == 0 {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+
+	// 获取评论树
+	viewerID, _ := getCurrentUserId(c) // 未登录时为 0
+	data, err := service.GetCommentTree(p, viewerID)
+	if err != nil {
+		zap.L().Error("logic.GetCommentTree failed", zap.Error(err))
+		if err == mysql.ErrorInvalidID || err == service.ErrorInvalidCursor {
+			ResponseError(c, CodeInvalidParams)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+
+	ResponseSuccess(c, data)
+}
+
 // GetCommentDetailHandler
 // @Summary 获取评论详情
 // @Description 获取评论的详细信息
 // @Tags 评论相关接口
 // @Accept application/json
 // @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
 // @Param id path int true "评论ID"
 // @Success 1000 {object} _ResponseCommentDetail
 // @Failure 1001 {object} ResponseData "参数错误"
@@ -182,7 +244,8 @@ func GetCommentDetailHandler(c *gin.Context) {
 	}
 
 	// 获取评论详情
-	data, err := service.GetCommentById(commentID)
+	viewerID, _ := getCurrentUserId(c) // 未登录时为 0
+	data, err := service.GetCommentById(commentID, viewerID)
 	if err != nil {
 		zap.L().Error("logic.GetCommentById failed",
 			zap.Int64("comment_id", commentID),
@@ -222,15 +285,20 @@ func DeleteCommentHandler(c *gin.Context) {
 		return
 	}
 
-	// 2. 获取当前登录用户ID
+	// 2. 获取当前登录用户ID及角色
 	userID, err := getCurrentUserId(c)
 	if err != nil {
 		ResponseError(c, CodeNotLogin)
 		return
 	}
+	role, err := getCurrentUserRole(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
 
 	// 3. 删除评论
-	if err := service.DeleteComment(userID, commentID); err != nil {
+	if err := service.DeleteComment(userID, role, commentID); err != nil {
 		zap.L().Error("logic.DeleteComment failed",
 			zap.Int64("comment_id", commentID),
 			zap.Int64("user_id", userID),
@@ -276,15 +344,20 @@ func DeleteCommentWithRepliesHandler(c *gin.Context) {
 		return
 	}
 
-	// 2. 获取当前登录用户ID
+	// 2. 获取当前登录用户ID及角色
 	userID, err := getCurrentUserId(c)
 	if err != nil {
 		ResponseError(c, CodeNotLogin)
 		return
 	}
+	role, err := getCurrentUserRole(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
 
 	// 3. 删除评论及其回复
-	if err := service.DeleteCommentWithReplies(userID, commentID); err != nil {
+	if err := service.DeleteCommentWithReplies(userID, role, commentID); err != nil {
 		zap.L().Error("logic.DeleteCommentWithReplies failed",
 			zap.Int64("comment_id", commentID),
 			zap.Int64("user_id", userID),
@@ -106,7 +106,7 @@ func CommunityDetailHandler(c *gin.Context) {
 
 // CreateCommunityHandler 创建社区
 // @Summary 创建社区
-// @Description 创建新的社区
+// @Description 创建新的社区（仅管理员）
 // @Tags 社区相关接口
 // @Accept application/json
 // @Produce application/json
@@ -117,6 +117,8 @@ func CommunityDetailHandler(c *gin.Context) {
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1008 {object} ResponseData "未登录"
 // @Failure 1010 {object} ResponseData "社区已存在"
+// @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /community [post]
 func CreateCommunityHandler(c *gin.Context) {
@@ -145,6 +147,10 @@ func CreateCommunityHandler(c *gin.Context) {
 			ResponseError(c, CodeCommunityExist)
 			return
 		}
+		if err == service.ErrorSensitiveWord {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -153,7 +159,7 @@ func CreateCommunityHandler(c *gin.Context) {
 
 // UpdateCommunityHandler 更新社区信息
 // @Summary 更新社区
-// @Description 更新社区信息
+// @Description 更新社区信息（仅管理员）
 // @Tags 社区相关接口
 // @Accept application/json
 // @Produce application/json
@@ -166,6 +172,8 @@ func CreateCommunityHandler(c *gin.Context) {
 // @Failure 1008 {object} ResponseData "未登录"
 // @Failure 1009 {object} ResponseData "社区不存在"
 // @Failure 1010 {object} ResponseData "社区名称已存在"
+// @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /community/{id} [put]
 func UpdateCommunityHandler(c *gin.Context) {
@@ -207,6 +215,10 @@ func UpdateCommunityHandler(c *gin.Context) {
 			ResponseError(c, CodeCommunityNotExist)
 			return
 		}
+		if err == service.ErrorSensitiveWord {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -215,7 +227,7 @@ func UpdateCommunityHandler(c *gin.Context) {
 
 // DeleteCommunityHandler 删除社区
 // @Summary 删除社区
-// @Description 删除指定社区
+// @Description 删除指定社区（仅管理员）
 // @Tags 社区相关接口
 // @Accept application/json
 // @Produce application/json
@@ -227,6 +239,7 @@ func UpdateCommunityHandler(c *gin.Context) {
 // @Failure 1008 {object} ResponseData "未登录"
 // @Failure 1009 {object} ResponseData "社区不存在"
 // @Failure 1014 {object} ResponseData "社区下存在帖子"
+// @Failure 1011 {object} ResponseData "无操作权限"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /community/{id} [delete]
 func DeleteCommunityHandler(c *gin.Context) {
@@ -263,3 +276,347 @@ func DeleteCommunityHandler(c *gin.Context) {
 	}
 	ResponseSuccess(c, nil)
 }
+
+// GetCommunityModeratorsHandler 获取社区版主列表
+// @Summary 获取社区版主列表
+// @Description 获取指定社区的版主列表
+// @Tags 社区相关接口
+// @Accept application/json
+// @Produce application/json
+// @Param id path int true "社区ID"
+// @Success 1000 {object} ResponseData{data=[]models.ApiCommunityModerator}
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1016 {object} ResponseData "社区不存在"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /community/{id}/moderators [get]
+func GetCommunityModeratorsHandler(c *gin.Context) {
+	// 获取社区ID
+	communityIDStr := c.Param("id")
+	communityID, err := strconv.ParseInt(communityIDStr, 10, 64)
+	if err != nil {
+		ResponseErrorWithMsg(c, CodeInvalidParams, "无效的社区ID")
+		return
+	}
+
+	data, err := service.GetCommunityModerators(communityID)
+	if err != nil {
+		zap.L().Error("logic.GetCommunityModerators failed",
+			zap.Int64("communityID", communityID),
+			zap.Error(err))
+		if err == mysql.ErrorInvalidID {
+			ResponseError(c, CodeCommunityNotExist)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, data)
+}
+
+// AddCommunityModeratorHandler 添加社区版主
+// @Summary 添加社区版主
+// @Description 社区创建者或管理员为社区添加版主
+// @Tags 社区相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path int true "社区ID"
+// @Param moderator body models.ParamCommunityModerator true "版主信息"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1003 {object} ResponseData "用户不存在"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1016 {object} ResponseData "社区不存在"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /community/{id}/moderators [post]
+func AddCommunityModeratorHandler(c *gin.Context) {
+	// 获取当前登录用户ID及角色
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	role, err := getCurrentUserRole(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	// 获取社区ID
+	communityIDStr := c.Param("id")
+	communityID, err := strconv.ParseInt(communityIDStr, 10, 64)
+	if err != nil {
+		ResponseErrorWithMsg(c, CodeInvalidParams, "无效的社区ID")
+		return
+	}
+
+	// 获取参数
+	p := new(models.ParamCommunityModerator)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("AddCommunityModeratorHandler with invalid param", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+
+	// 添加版主
+	if err := service.AddCommunityModerator(userID, role, communityID, p.UserID); err != nil {
+		zap.L().Error("logic.AddCommunityModerator failed",
+			zap.Int64("communityID", communityID),
+			zap.Int64("moderatorID", p.UserID),
+			zap.Error(err))
+		switch err {
+		case mysql.ErrorInvalidID:
+			ResponseError(c, CodeCommunityNotExist)
+		case mysql.ErrorUserNotExist:
+			ResponseError(c, CodeUserNotExist)
+		case mysql.ErrorNoPermission:
+			ResponseError(c, CodeNoPermission)
+		default:
+			ResponseError(c, CodeServerBusy)
+		}
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// RemoveCommunityModeratorHandler 移除社区版主
+// @Summary 移除社区版主
+// @Description 社区创建者或管理员移除社区版主
+// @Tags 社区相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path int true "社区ID"
+// @Param user_id path int true "版主的用户ID"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /community/{id}/moderators/{user_id} [delete]
+func RemoveCommunityModeratorHandler(c *gin.Context) {
+	// 获取当前登录用户ID及角色
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	role, err := getCurrentUserRole(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	// 获取社区ID和版主ID
+	communityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseErrorWithMsg(c, CodeInvalidParams, "无效的社区ID")
+		return
+	}
+	moderatorID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		ResponseErrorWithMsg(c, CodeInvalidParams, "无效的用户ID")
+		return
+	}
+
+	// 移除版主
+	if err := service.RemoveCommunityModerator(userID, role, communityID, moderatorID); err != nil {
+		zap.L().Error("logic.RemoveCommunityModerator failed",
+			zap.Int64("communityID", communityID),
+			zap.Int64("moderatorID", moderatorID),
+			zap.Error(err))
+		switch err {
+		case mysql.ErrorInvalidID:
+			ResponseErrorWithMsg(c, CodeInvalidParams, "社区不存在或该用户不是社区版主")
+		case mysql.ErrorNoPermission:
+			ResponseError(c, CodeNoPermission)
+		default:
+			ResponseError(c, CodeServerBusy)
+		}
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+const maxCommunityPageSize = 50 // 加入的社区列表及帖子列表每页数量的上限
+
+// JoinCommunityHandler 加入社区
+// @Summary 加入社区
+// @Description 加入指定社区，重复加入不会报错
+// @Tags 社区相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path int true "社区ID"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1009 {object} ResponseData "社区不存在"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /community/{id}/join [post]
+func JoinCommunityHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	communityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseErrorWithMsg(c, CodeInvalidParams, "无效的社区ID")
+		return
+	}
+
+	if err := service.JoinCommunity(userID, communityID); err != nil {
+		zap.L().Error("logic.JoinCommunity failed",
+			zap.Int64("communityID", communityID),
+			zap.Int64("userID", userID),
+			zap.Error(err))
+		if err == mysql.ErrorInvalidID {
+			ResponseError(c, CodeCommunityNotExist)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// LeaveCommunityHandler 退出社区
+// @Summary 退出社区
+// @Description 退出指定社区，不是社区成员时不会报错
+// @Tags 社区相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path int true "社区ID"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /community/{id}/join [delete]
+func LeaveCommunityHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	communityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseErrorWithMsg(c, CodeInvalidParams, "无效的社区ID")
+		return
+	}
+
+	if err := service.LeaveCommunity(userID, communityID); err != nil {
+		zap.L().Error("logic.LeaveCommunity failed",
+			zap.Int64("communityID", communityID),
+			zap.Int64("userID", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// GetJoinedCommunityListHandler 用户加入的社区列表
+// @Summary 用户加入的社区列表
+// @Description 按加入时间倒序返回指定用户加入的社区
+// @Tags 社区相关接口
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "用户ID"
+// @Param page query int false "页码" minimum(1) default(1)
+// @Param size query int false "每页数量" minimum(1) maximum(50) default(10)
+// @Success 1000 {object} ResponseData{data=models.ApiCommunityDetailRes}
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1003 {object} ResponseData "用户不存在"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /user/{id}/communities [get]
+func GetJoinedCommunityListHandler(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseErrorWithMsg(c, CodeInvalidParams, "无效的用户ID")
+		return
+	}
+	p := &models.ParamPage{
+		Page: 1,  // 默认第1页
+		Size: 10, // 默认每页10条
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("GetJoinedCommunityListHandler with invalid params", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 || p.Size > maxCommunityPageSize {
+		p.Size = 10
+	}
+
+	data, err := service.GetJoinedCommunityList(userID, p)
+	if err != nil {
+		zap.L().Error("logic.GetJoinedCommunityList failed",
+			zap.Int64("userID", userID),
+			zap.Error(err))
+		if err == mysql.ErrorUserNotExist {
+			ResponseError(c, CodeUserNotExist)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, data)
+}
+
+// GetJoinedCommunityFeedHandler 加入的社区的帖子列表
+// @Summary 加入的社区的帖子列表
+// @Description 合并当前用户加入的所有社区中的帖子，排序方式与帖子列表相同（默认按时间）
+// @Tags 社区相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param object query models.ParamCommunityFeed false "分页及排序参数"
+// @Success 1000 {object} _ResponsePostList
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /feed/communities [get]
+func GetJoinedCommunityFeedHandler(c *gin.Context) {
+	p := &models.ParamCommunityFeed{
+		Page:  1,
+		Size:  10,
+		Order: models.OrderTime,
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("GetJoinedCommunityFeedHandler with invalid params", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 || p.Size > maxCommunityPageSize {
+		p.Size = 10
+	}
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	data, err := service.GetJoinedCommunityFeed(userID, p)
+	if err != nil {
+		zap.L().Error("logic.GetJoinedCommunityFeed failed",
+			zap.Int64("userID", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, data)
+}
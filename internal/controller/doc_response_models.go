@@ -32,3 +32,31 @@ type _ResponseCommentDetail struct {
 	Message string                   `json:"message" example:"success"` // 提示信息
 	Data    *models.ApiCommentDetail `json:"data"`                      // 评论详情数据
 }
+
+// _ResponseCommentTree 评论树响应
+type _ResponseCommentTree struct {
+	Code    MyCode                    `json:"code" example:"1000"`       // 业务响应状态码
+	Message string                    `json:"message" example:"success"` // 提示信息
+	Data    *models.ApiCommentTreeRes `json:"data"`                      // 评论树数据
+}
+
+// _ResponseReportList 举报列表响应
+type _ResponseReportList struct {
+	Code    MyCode                   `json:"code" example:"1000"`       // 业务响应状态码
+	Message string                   `json:"message" example:"success"` // 提示信息
+	Data    *models.ApiReportListRes `json:"data"`                      // 举报列表数据
+}
+
+// _ResponseNotificationList 通知列表响应
+type _ResponseNotificationList struct {
+	Code    MyCode                         `json:"code" example:"1000"`       // 业务响应状态码
+	Message string                         `json:"message" example:"success"` // 提示信息
+	Data    *models.ApiNotificationListRes `json:"data"`                      // 通知列表数据
+}
+
+// _ResponseFollowList 关注/粉丝列表响应
+type _ResponseFollowList struct {
+	Code    MyCode                   `json:"code" example:"1000"`       // 业务响应状态码
+	Message string                   `json:"message" example:"success"` // 提示信息
+	Data    *models.ApiFollowListRes `json:"data"`                      // 关注/粉丝列表数据
+}
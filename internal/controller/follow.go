@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"errors"
+	"go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"go_community/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxFollowPageSize = 50 // 关注/粉丝列表及关注动态每页数量的上限
+
+// FollowHandler 关注用户
+// @Summary 关注用户
+// @Description 关注指定用户，重复关注不会报错
+// @Tags 关注相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path string true "用户ID"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误或不能关注自己"
+// @Failure 1003 {object} ResponseData "用户不存在"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /user/{id}/follow [post]
+func FollowHandler(c *gin.Context) {
+	followeeID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	if err := service.Follow(userID, followeeID); err != nil {
+		zap.L().Error("logic.Follow failed",
+			zap.Int64("user_id", userID),
+			zap.Int64("followee_id", followeeID),
+			zap.Error(err))
+		if errors.Is(err, service.ErrorCannotFollowSelf) {
+			ResponseErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		if errors.Is(err, mysql.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExist)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// UnfollowHandler 取消关注
+// @Summary 取消关注
+// @Description 取消关注指定用户，没有关注过不会报错
+// @Tags 关注相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path string true "用户ID"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /user/{id}/follow [delete]
+func UnfollowHandler(c *gin.Context) {
+	followeeID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	if err := service.Unfollow(userID, followeeID); err != nil {
+		zap.L().Error("logic.Unfollow failed",
+			zap.Int64("user_id", userID),
+			zap.Int64("followee_id", followeeID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// GetFollowerListHandler 粉丝列表
+// @Summary 粉丝列表
+// @Description 按关注时间倒序返回指定用户的粉丝
+// @Tags 关注相关接口
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "用户ID"
+// @Param object query models.ParamFollowList false "分页参数"
+// @Success 1000 {object} _ResponseFollowList
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1003 {object} ResponseData "用户不存在"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /user/{id}/followers [get]
+func GetFollowerListHandler(c *gin.Context) {
+	getFollowList(c, "GetFollowerList", service.GetFollowerList)
+}
+
+// GetFollowingListHandler 关注列表
+// @Summary 关注列表
+// @Description 按关注时间倒序返回指定用户关注的人
+// @Tags 关注相关接口
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "用户ID"
+// @Param object query models.ParamFollowList false "分页参数"
+// @Success 1000 {object} _ResponseFollowList
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1003 {object} ResponseData "用户不存在"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /user/{id}/following [get]
+func GetFollowingListHandler(c *gin.Context) {
+	getFollowList(c, "GetFollowingList", service.GetFollowingList)
+}
+
+// getFollowList 关注/粉丝列表的公共处理逻辑
+func getFollowList(c *gin.Context, name string, list func(int64, *models.ParamFollowList) (*models.ApiFollowListRes, error)) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	// 初始化结构体时指定初始参数
+	p := &models.ParamFollowList{
+		Page: 1,
+		Size: 10,
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error(name+" with invalid params", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 || p.Size > maxFollowPageSize {
+		p.Size = 10
+	}
+
+	data, err := list(userID, p)
+	if err != nil {
+		zap.L().Error("logic."+name+" failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		if errors.Is(err, mysql.ErrorUserNotExist) {
+			ResponseError(c, CodeUserNotExist)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, data)
+}
+
+// GetFollowingFeedHandler 关注动态
+// @Summary 关注动态
+// @Description 按发帖时间倒序返回当前用户关注的人发布的帖子
+// @Tags 关注相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param object query models.ParamFeed false "分页参数"
+// @Success 1000 {object} _ResponsePostList
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /feed/following [get]
+func GetFollowingFeedHandler(c *gin.Context) {
+	// 初始化结构体时指定初始参数
+	p := &models.ParamFeed{
+		Page: 1,
+		Size: 10,
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("GetFollowingFeedHandler with invalid params", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 || p.Size > maxFollowPageSize {
+		p.Size = 10
+	}
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	data, err := service.GetFollowingFeed(userID, p)
+	if err != nil {
+		zap.L().Error("logic.GetFollowingFeed failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, data)
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"errors"
+	"go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"go_community/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxNotificationPageSize = 50 // 通知列表每页数量的上限
+
+// GetNotificationListHandler 通知列表
+// @Summary 通知列表
+// @Description 按时间倒序返回当前用户的通知（评论、回复、@提及、点赞里程碑），同时返回未读数量
+// @Tags 通知相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param object query models.ParamNotificationList false "查询参数"
+// @Success 1000 {object} _ResponseNotificationList
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /notifications [get]
+func GetNotificationListHandler(c *gin.Context) {
+	// 初始化结构体时指定初始参数
+	p := &models.ParamNotificationList{
+		Page: 1,
+		Size: 10,
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("GetNotificationListHandler with invalid params", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 || p.Size > maxNotificationPageSize {
+		p.Size = 10
+	}
+
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	data, err := service.GetNotificationList(userID, p)
+	if err != nil {
+		zap.L().Error("logic.GetNotificationList failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, data)
+}
+
+// GetUnreadNotificationCountHandler 未读通知数量
+// @Summary 未读通知数量
+// @Description 获取当前用户的未读通知数量
+// @Tags 通知相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 1000 {object} ResponseData
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /notifications/unread_count [get]
+func GetUnreadNotificationCountHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	count, err := service.GetUnreadNotificationCount(userID)
+	if err != nil {
+		zap.L().Error("logic.GetUnreadNotificationCount failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, &models.ApiUnreadCount{Unread: count})
+}
+
+// MarkNotificationReadHandler 标记通知为已读
+// @Summary 标记通知为已读
+// @Description 将当前用户的一条通知标记为已读
+// @Tags 通知相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path string true "通知ID"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误或通知不存在"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /notification/{id}/read [put]
+func MarkNotificationReadHandler(c *gin.Context) {
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	if err := service.MarkNotificationRead(userID, notificationID); err != nil {
+		zap.L().Error("logic.MarkNotificationRead failed",
+			zap.Int64("notification_id", notificationID),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			ResponseErrorWithMsg(c, CodeInvalidParams, "通知不存在")
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// MarkAllNotificationsReadHandler 标记所有通知为已读
+// @Summary 标记所有通知为已读
+// @Description 将当前用户的所有未读通知标记为已读
+// @Tags 通知相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 1000 {object} ResponseData
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /notifications/read [put]
+func MarkAllNotificationsReadHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	if err := service.MarkAllNotificationsRead(userID); err != nil {
+		zap.L().Error("logic.MarkAllNotificationsRead failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
@@ -5,6 +5,7 @@ import (
 	"go_community/internal/dao/mysql"
 	"go_community/internal/models"
 	"go_community/internal/service"
+	"math"
 	"strconv"
 
 	"go.uber.org/zap"
@@ -24,6 +25,9 @@ import (
 // @Success 1000 {object} ResponseData
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1024 {object} ResponseData "发布过于频繁（data.retry_after 为需要等待的秒数）"
+// @Failure 1025 {object} ResponseData "重复内容"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /post [post]
 func CreatePostHandler(c *gin.Context) {
@@ -46,6 +50,13 @@ func CreatePostHandler(c *gin.Context) {
 	// 2.创建帖子
 	if err := service.CreatePost(p); err != nil {
 		zap.L().Error("logic.CreatePost failed", zap.Error(err))
+		if errors.Is(err, service.ErrorSensitiveWord) {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
+		if responsePublishGuardError(c, err) {
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -59,6 +70,7 @@ func CreatePostHandler(c *gin.Context) {
 // @Tags 帖子相关接口
 // @Accept application/json
 // @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
 // @Param id path int true "帖子ID"
 // @Success 1000 {object} _ResponsePostDetail
 // @Failure 1001 {object} ResponseData "参数错误"
@@ -72,8 +84,9 @@ func PostDetailHandler(c *gin.Context) {
 		zap.L().Error("PostDetailHandler with invalid param", zap.Error(err))
 		ResponseError(c, CodeInvalidParams)
 	}
-	// 2.根据ID取出帖子数据（查数据库）
-	post, err := service.GetPostById(postID)
+	// 2.根据ID取出帖子数据（查数据库），携带有效 token 时同时返回当前用户的投票方向
+	viewerID, _ := getCurrentUserId(c)
+	post, err := service.GetPostById(postID, viewerID)
 	if err != nil {
 		zap.L().Error("logic.GetPostById failed", zap.Error(err))
 		ResponseError(c, CodeServerBusy)
@@ -88,6 +101,7 @@ func PostDetailHandler(c *gin.Context) {
 // @Tags 帖子相关接口
 // @Accept application/json
 // @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
 // @Param page query int false "页码" minimum(1) default(1)
 // @Param size query int false "每页数量" minimum(1) maximum(10) default(5)
 // @Success 1000 {object} _ResponsePostList
@@ -98,7 +112,8 @@ func GetPostListHandler(c *gin.Context) {
 	// 获取分页参数
 	page, size := getPageInfo(c)
 	// 获取数据
-	posts, err := service.GetPostList(page, size)
+	viewerID, _ := getCurrentUserId(c) // 未登录时为 0
+	posts, err := service.GetPostList(page, size, viewerID)
 	if err != nil {
 		zap.L().Error("logic.GetPostList failed", zap.Error(err))
 		ResponseError(c, CodeServerBusy)
@@ -113,6 +128,7 @@ func GetPostListHandler(c *gin.Context) {
 // @Tags 帖子相关接口
 // @Accept application/json
 // @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
 // @Param object query models.ParamPostListQueryNoSearch false "查询参数"
 // @Success 200 {object} _ResponsePostList
 // @Router /posts2 [get]
@@ -133,7 +149,8 @@ func GetPostListHandler2(c *gin.Context) {
 	}
 
 	// 获取数据
-	posts, err := service.GetPostListNew(p) // 更新：合二为一
+	viewerID, _ := getCurrentUserId(c)                // 未登录时为 0
+	posts, err := service.GetPostListNew(p, viewerID) // 更新：合二为一
 	if err != nil {
 		zap.L().Error("logic.GetPostListNew failed", zap.Error(err))
 		ResponseError(c, CodeServerBusy)
@@ -146,10 +163,11 @@ func GetPostListHandler2(c *gin.Context) {
 
 // PostSearchHandler
 // @Summary 搜索帖子
-// @Description 根据关键词搜索帖子
+// @Description 根据关键词搜索帖子，按相关度排序并返回高亮摘要，可按社区、作者和发布日期过滤；关键词为空（或只有标点符号）时返回空列表，不再返回所有帖子
 // @Tags 帖子相关接口
 // @Accept application/json
 // @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
 // @Param object query models.ParamPostListQueryWithSearch false "查询参数"
 // @Success 1000 {object} _ResponsePostList
 // @Failure 1001 {object} ResponseData "参数错误"
@@ -164,7 +182,8 @@ func PostSearchHandler(c *gin.Context) {
 	}
 
 	// 获取数据
-	data, err := service.PostSearch(p)
+	viewerID, _ := getCurrentUserId(c) // 未登录时为 0
+	data, err := service.PostSearch(p, viewerID)
 	if err != nil {
 		zap.L().Error("logic.PostSearch failed", zap.Error(err))
 		ResponseError(c, CodeServerBusy)
@@ -186,6 +205,7 @@ func PostSearchHandler(c *gin.Context) {
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1008 {object} ResponseData "未登录"
 // @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /post [put]
 func UpdatePostHandler(c *gin.Context) {
@@ -211,6 +231,10 @@ func UpdatePostHandler(c *gin.Context) {
 			ResponseError(c, CodeNoPermission)
 			return
 		}
+		if errors.Is(err, service.ErrorSensitiveWord) {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -224,6 +248,7 @@ func UpdatePostHandler(c *gin.Context) {
 // @Tags 帖子相关接口
 // @Accept application/json
 // @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌（可选，携带时返回当前用户的投票方向）"
 // @Param id path int true "用户ID"
 // @Param page query int false "页码" minimum(1) default(1)
 // @Param size query int false "每页数量" minimum(1) maximum(10) default(5)
@@ -245,7 +270,8 @@ func GetUserPostListHandler(c *gin.Context) {
 	page, size := getPageInfo(c)
 
 	// 获取数据
-	data, err := service.GetUserPostList(userID, page, size)
+	viewerID, _ := getCurrentUserId(c) // 未登录时为 0
+	data, err := service.GetUserPostList(userID, page, size, viewerID)
 	if err != nil {
 		zap.L().Error("logic.GetUserPostList failed",
 			zap.Int64("user_id", userID),
@@ -281,15 +307,20 @@ func DeletePostHandler(c *gin.Context) {
 		return
 	}
 
-	// 2. 获取当前登录用户ID
+	// 2. 获取当前登录用户ID及角色
 	userID, err := getCurrentUserId(c)
 	if err != nil {
 		ResponseError(c, CodeNotLogin)
 		return
 	}
+	role, err := getCurrentUserRole(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
 
 	// 3. 删除帖子
-	if err := service.DeletePost(userID, postID); err != nil {
+	if err := service.DeletePost(userID, role, postID); err != nil {
 		zap.L().Error("logic.DeletePost failed",
 			zap.Int64("post_id", postID),
 			zap.Int64("user_id", userID),
@@ -309,3 +340,24 @@ func DeletePostHandler(c *gin.Context) {
 
 	ResponseSuccess(c, nil)
 }
+
+// responsePublishGuardError 发帖/评论被反垃圾内容检查拒绝时返回对应的错误响应
+// 返回 false 表示不是反垃圾内容的错误，需要调用方继续处理
+func responsePublishGuardError(c *gin.Context, err error) bool {
+	var limitedErr *service.PublishLimitedError
+	if errors.As(err, &limitedErr) {
+		// 向上取整，避免客户端过早重试
+		retryAfter := int64(math.Ceil(limitedErr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+		ResponseErrorWithData(c, CodePublishTooFrequent, gin.H{
+			"retry_after": retryAfter,
+			"daily_limit": limitedErr.DailyLimit,
+		})
+		return true
+	}
+	if errors.Is(err, service.ErrorDuplicateContent) {
+		ResponseError(c, CodeDuplicateContent)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"go_community/internal/service"
+	"go_community/pkg/hub"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateStreamTicketHandler 获取推送连接的票据
+// @Summary 获取推送连接的票据
+// @Description 签发短期有效、只能使用一次的票据，用于浏览器的 EventSource 建立推送连接（ticket 参数），
+// @Description 避免 access token 出现在 URI 和访问日志中
+// @Tags 实时推送接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 1000 {object} ResponseData{data=models.ApiStreamTicket}
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /stream/ticket [post]
+func CreateStreamTicketHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	role, err := getCurrentUserRole(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	sessionID, err := getCurrentSessionID(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	ticket, expire, err := service.CreateStreamTicket(userID, role, sessionID)
+	if err != nil {
+		zap.L().Error("logic.CreateStreamTicket failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, &models.ApiStreamTicket{
+		Ticket:    ticket,
+		ExpiresIn: int64(expire / time.Second),
+	})
+}
+
+// NotificationStreamHandler 通知实时推送
+// @Summary 通知实时推送
+// @Description 使用 Server-Sent Events 推送当前用户的新通知（event: notification）和未读数量变化（event: unread），
+// @Description 定时发送心跳（event: ping），连接超过最长时间后断开，客户端重新连接。
+// @Description 浏览器的 EventSource 不能设置请求头，可以先通过 /stream/ticket 获取一次性票据，再使用 ticket 参数建立连接
+// @Tags 实时推送接口
+// @Produce text/event-stream
+// @Security Bearer
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param ticket query string false "一次性票据（不能设置请求头时使用）"
+// @Success 200 {string} string "事件流"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /stream/notifications [get]
+func NotificationStreamHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	sub, err := service.SubscribeNotifications(userID)
+	if err != nil {
+		zap.L().Error("logic.SubscribeNotifications failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	defer sub.Close()
+
+	// 连接建立后先推送当前的未读数量
+	unread, err := service.GetUnreadNotificationCount(userID)
+	if err != nil {
+		zap.L().Error("logic.GetUnreadNotificationCount failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+	streamEvents(c, sub, &models.PushMessage{
+		Event: models.PushEventUnread,
+		Data:  mustMarshal(&models.ApiUnreadCount{Unread: unread}),
+	})
+}
+
+// CommentStreamHandler 帖子新评论实时推送
+// @Summary 帖子新评论实时推送
+// @Description 使用 Server-Sent Events 推送帖子的新评论和回复（event: comment），数据格式与评论详情相同，
+// @Description 定时发送心跳（event: ping），连接超过最长时间后断开，客户端重新连接。
+// @Description 浏览器的 EventSource 不能设置请求头，可以先通过 /stream/ticket 获取一次性票据，再使用 ticket 参数建立连接
+// @Tags 实时推送接口
+// @Produce text/event-stream
+// @Security Bearer
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param ticket query string false "一次性票据（不能设置请求头时使用）"
+// @Param id path string true "帖子ID"
+// @Success 200 {string} string "事件流"
+// @Failure 1001 {object} ResponseData "参数错误或帖子不存在"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /stream/post/{id}/comments [get]
+func CommentStreamHandler(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	sub, err := service.SubscribePostComments(postID)
+	if err != nil {
+		zap.L().Error("logic.SubscribePostComments failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			ResponseErrorWithMsg(c, CodeInvalidParams, "帖子不存在")
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	defer sub.Close()
+
+	streamEvents(c, sub)
+}
+
+// streamEvents 将订阅收到的消息以 SSE 格式写给客户端，直到客户端断开、订阅结束或超过连接的最长时间
+// initial 为连接建立后立即发送的消息
+func streamEvents(c *gin.Context, sub *hub.Subscription, initial ...*models.PushMessage) {
+	heartbeat, maxLifetime := service.PushTimeouts()
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	deadline := time.NewTimer(maxLifetime)
+	defer deadline.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 nginx 的响应缓冲
+	for _, msg := range initial {
+		c.SSEvent(msg.Event, msg.Data)
+	}
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case payload, ok := <-sub.C:
+			if !ok {
+				// 客户端消费过慢，订阅已被关闭
+				return false
+			}
+			msg := new(models.PushMessage)
+			if err := json.Unmarshal(payload, msg); err != nil {
+				zap.L().Error("json.Unmarshal push message failed", zap.Error(err))
+				return true
+			}
+			c.SSEvent(msg.Event, msg.Data)
+			return true
+		}
+	})
+}
+
+// mustMarshal 序列化推送数据，数据都是项目中定义的结构体，不会失败
+func mustMarshal(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
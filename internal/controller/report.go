@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"errors"
+	"go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"go_community/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+const maxReportPageSize = 50 // 举报列表每页数量的上限
+
+// ReportHandler 举报
+// @Summary 举报
+// @Description 举报帖子、评论或用户，同一对象未处理完时不能重复举报
+// @Tags 举报相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param report body models.ParamReport true "举报信息"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误或举报对象不存在"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1027 {object} ResponseData "已举报过该内容"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /report [post]
+func ReportHandler(c *gin.Context) {
+	// 1. 参数校验
+	p := new(models.ParamReport)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("ReportHandler with invalid param", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParams)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParams, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+
+	// 2. 获取当前用户ID
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	// 3. 举报
+	if err := service.CreateReport(userID, p); err != nil {
+		zap.L().Error("logic.CreateReport failed",
+			zap.Int64("user_id", userID),
+			zap.Any("params", p),
+			zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			ResponseErrorWithMsg(c, CodeInvalidParams, "举报对象不存在或已删除")
+			return
+		}
+		if errors.Is(err, service.ErrorCannotReportSelf) {
+			ResponseErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		if errors.Is(err, mysql.ErrorReportExist) {
+			ResponseError(c, CodeReportExist)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+
+	ResponseSuccess(c, nil)
+}
+
+// GetReportListHandler 举报列表
+// @Summary 举报列表
+// @Description 审核队列：按举报时间升序返回举报（全站版主/管理员）
+// @Tags 举报相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param object query models.ParamReportList false "查询参数"
+// @Success 1000 {object} _ResponseReportList
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /reports [get]
+func GetReportListHandler(c *gin.Context) {
+	// 初始化结构体时指定初始参数
+	p := &models.ParamReportList{
+		Page: 1,
+		Size: 10,
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("GetReportListHandler with invalid params", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 || p.Size > maxReportPageSize {
+		p.Size = 10
+	}
+
+	data, err := service.GetReportList(p)
+	if err != nil {
+		zap.L().Error("logic.GetReportList failed", zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, data)
+}
+
+// ClaimReportHandler 认领举报
+// @Summary 认领举报
+// @Description 认领待处理的举报，认领超过 30 分钟未处理的举报可以被其他人重新认领（全站版主/管理员）
+// @Tags 举报相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path string true "举报ID"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误或举报已处理"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1028 {object} ResponseData "举报已被其他人认领"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /report/{id}/claim [post]
+func ClaimReportHandler(c *gin.Context) {
+	reportID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	if err := service.ClaimReport(userID, reportID); err != nil {
+		zap.L().Error("logic.ClaimReport failed",
+			zap.Int64("report_id", reportID),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			ResponseErrorWithMsg(c, CodeInvalidParams, "举报不存在")
+			return
+		}
+		if errors.Is(err, service.ErrorReportAlreadyClosed) {
+			ResponseErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		if errors.Is(err, mysql.ErrorReportClaimed) {
+			ResponseError(c, CodeReportClaimed)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+
+	ResponseSuccess(c, nil)
+}
+
+// ResolveReportHandler 处理举报
+// @Summary 处理举报
+// @Description 处理自己认领的举报：隐藏、删除、驳回或封禁作者，同一对象未处理完的举报一并标记为已处理（全站版主/管理员）
+// @Tags 举报相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path string true "举报ID"
+// @Param resolve body models.ParamResolveReport true "处理方式"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误、举报已处理或不支持的处理方式"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1011 {object} ResponseData "无操作权限（未认领该举报，或封禁版主/管理员）"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /report/{id}/resolve [post]
+func ResolveReportHandler(c *gin.Context) {
+	reportID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+	p := new(models.ParamResolveReport)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("ResolveReportHandler with invalid param", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResponseError(c, CodeInvalidParams)
+			return
+		}
+		ResponseErrorWithMsg(c, CodeInvalidParams, removeTopStruct(errs.Translate(trans)))
+		return
+	}
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	role, err := getCurrentUserRole(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	if err := service.ResolveReport(userID, role, reportID, p); err != nil {
+		zap.L().Error("logic.ResolveReport failed",
+			zap.Int64("report_id", reportID),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		switch {
+		case errors.Is(err, mysql.ErrorInvalidID):
+			ResponseErrorWithMsg(c, CodeInvalidParams, "举报不存在")
+		case errors.Is(err, service.ErrorReportAlreadyClosed), errors.Is(err, service.ErrorUnsupportedAction):
+			ResponseErrorWithMsg(c, CodeInvalidParams, err.Error())
+		case errors.Is(err, service.ErrorReportNotClaimed):
+			ResponseErrorWithMsg(c, CodeNoPermission, err.Error())
+		case errors.Is(err, mysql.ErrorNoPermission):
+			ResponseError(c, CodeNoPermission)
+		default:
+			ResponseError(c, CodeServerBusy)
+		}
+		return
+	}
+
+	ResponseSuccess(c, nil)
+}
@@ -7,7 +7,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-const CtxUserIDKey = "userID"
+const (
+	CtxUserIDKey    = "userID"
+	CtxUserRoleKey  = "userRole"
+	CtxSessionIDKey = "sessionID"
+)
 
 var ErrorUserNotLogin = errors.New("用户未登录")
 
@@ -26,6 +30,36 @@ func getCurrentUserId(c *gin.Context) (userId int64, err error) {
 	return
 }
 
+// getCurrentUserRole 获取当前登录用户的角色
+func getCurrentUserRole(c *gin.Context) (role int8, err error) {
+	_role, ok := c.Get(CtxUserRoleKey)
+	if !ok {
+		err = ErrorUserNotLogin
+		return
+	}
+	role, ok = _role.(int8)
+	if !ok {
+		err = ErrorUserNotLogin
+		return
+	}
+	return
+}
+
+// getCurrentSessionID 获取当前请求的登录会话id
+func getCurrentSessionID(c *gin.Context) (sessionID string, err error) {
+	_sessionID, ok := c.Get(CtxSessionIDKey)
+	if !ok {
+		err = ErrorUserNotLogin
+		return
+	}
+	sessionID, ok = _sessionID.(string)
+	if !ok || sessionID == "" {
+		err = ErrorUserNotLogin
+		return
+	}
+	return
+}
+
 // getPageInfo 分页参数
 func getPageInfo(c *gin.Context) (int64, int64) {
 	// 获取分页参数
@@ -32,6 +32,24 @@ func ResponseErrorWithMsg(ctx *gin.Context, code MyCode, errMsg interface{}) {
 	ctx.JSON(http.StatusOK, rd)
 }
 
+func ResponseErrorWithData(ctx *gin.Context, code MyCode, data interface{}) {
+	rd := &ResponseData{
+		Code:    code,
+		Message: code.Msg(),
+		Data:    data,
+	}
+	ctx.JSON(http.StatusOK, rd)
+}
+
+func ResponseErrorWithStatus(ctx *gin.Context, status int, c MyCode) {
+	rd := &ResponseData{
+		Code:    c,
+		Message: c.Msg(),
+		Data:    nil,
+	}
+	ctx.JSON(status, rd)
+}
+
 func ResponseSuccess(ctx *gin.Context, data interface{}) {
 	rd := &ResponseData{
 		Code:    CodeSuccess,
@@ -4,13 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"go_community/internal/dao/mysql"
+	"go_community/internal/dao/redis"
 	"go_community/internal/models"
 	"go_community/internal/service"
 	pkg_file "go_community/pkg/file"
 	"go_community/pkg/jwt"
+	"math"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/go-playground/validator/v10"
 
@@ -29,6 +30,8 @@ import (
 // @Success 1000 {object} ResponseData
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1002 {object} ResponseData "用户名已存在"
+// @Failure 1019 {object} ResponseData "邮箱已被注册"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /signup [post]
 func SignUpHandler(c *gin.Context) {
@@ -55,6 +58,14 @@ func SignUpHandler(c *gin.Context) {
 			ResponseError(c, CodeUserExist)
 			return
 		}
+		if errors.Is(err, mysql.ErrorEmailExist) {
+			ResponseError(c, CodeEmailExist)
+			return
+		}
+		if errors.Is(err, service.ErrorSensitiveWord) {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -72,6 +83,8 @@ func SignUpHandler(c *gin.Context) {
 // @Success 1000 {object} ResponseData{data=map[string]string{user_id=string,user_name=string,access_token=string,refresh_token=string}}
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1004 {object} ResponseData "用户名或密码错误"
+// @Failure 1022 {object} ResponseData{data=map[string]int64{retry_after=int64}} "登录失败次数过多，retry_after秒后再试（同时返回Retry-After响应头）"
+// @Failure 1029 {object} ResponseData "账号已被封禁"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /login [post]
 func LoginHandler(c *gin.Context) {
@@ -91,13 +104,27 @@ func LoginHandler(c *gin.Context) {
 	}
 
 	// 2.业务逻辑处理
-	user, err := service.Login(p)
+	user, err := service.Login(p, c.ClientIP())
 	if err != nil {
 		zap.L().Error("logic.Login failed", zap.String("username", p.UserName), zap.Error(err))
+		var lockedErr *service.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			// 向上取整，避免客户端过早重试
+			retryAfter := int64(math.Ceil(lockedErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			ResponseErrorWithData(c, CodeLoginLocked, gin.H{
+				"retry_after": retryAfter,
+			})
+			return
+		}
 		if errors.Is(err, mysql.ErrorUserNotExist) {
 			ResponseError(c, CodeUserNotExist)
 			return
 		}
+		if errors.Is(err, mysql.ErrorUserBanned) {
+			ResponseError(c, CodeUserBanned)
+			return
+		}
 		ResponseError(c, CodeInvalidPassword)
 		return
 	}
@@ -112,49 +139,116 @@ func LoginHandler(c *gin.Context) {
 
 // RefreshTokenHandler 刷新token
 // @Summary 刷新访问令牌
-// @Description 使用refresh_token刷新access_token
+// @Description 使用refresh_token签发新的access_token和refresh_token，旧的refresh_token随即失效；重复使用已失效的refresh_token会注销该登录会话
 // @Tags 用户相关接口
 // @Accept application/json
 // @Produce application/json
-// @Param Authorization header string true "Bearer 用户令牌"
 // @Param refresh_token query string true "刷新令牌"
-// @Success 200 {object} map[string]string{access_token=string,refresh_token=string}
+// @Success 1000 {object} ResponseData{data=map[string]string{access_token=string,refresh_token=string}}
 // @Failure 1006 {object} ResponseData "无效的Token"
+// @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /refresh_token [get]
 func RefreshTokenHandler(c *gin.Context) {
 	rt := c.Query("refresh_token")
-	// 客户端携带 Token 有三种方式 1.放在请求头 2.放在请求体 3.放在 URI
-	// 这里假设 Token 放在 Header 的 Authorization 中，并使用 Bearer 开头
-	// 这里的具体实现方式要依据你的实际业务情况决定
-	authHeader := c.Request.Header.Get("Authorization")
-	if authHeader == "" {
-		ResponseErrorWithMsg(c, CodeInvalidToken, "请求头缺少Auth Token")
-		c.Abort()
+	if rt == "" {
+		ResponseErrorWithMsg(c, CodeInvalidToken, "缺少refresh_token")
 		return
 	}
-	// 按空格分割
-	parts := strings.SplitN(authHeader, " ", 2)
-	if !(len(parts) == 2 && parts[0] == "Bearer") {
-		ResponseErrorWithMsg(c, CodeInvalidToken, "Token格式不对")
-		c.Abort()
+	aToken, rToken, err := service.RefreshToken(rt)
+	if err != nil {
+		zap.L().Error("logic.RefreshToken failed", zap.Error(err))
+		if errors.Is(err, service.ErrorInvalidRefreshToken) ||
+			errors.Is(err, redis.ErrorSessionNotExist) ||
+			errors.Is(err, redis.ErrorRefreshTokenReused) {
+			ResponseError(c, CodeInvalidToken)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
 		return
 	}
-	aToken, rToken, err := jwt.RefreshToken(parts[1], rt)
-	zap.L().Error("jwt.RefreshToken failed", zap.Error(err))
-	c.JSON(http.StatusOK, gin.H{
+	ResponseSuccess(c, gin.H{
 		"access_token":  aToken,
 		"refresh_token": rToken,
 	})
 }
 
+// LogoutHandler 退出登录
+// @Summary 退出登录
+// @Description 注销当前登录会话，该会话的access_token和refresh_token立即失效
+// @Tags 用户相关接口
+// @Produce application/json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 1000 {object} ResponseData
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /logout [post]
+func LogoutHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	sessionID, err := getCurrentSessionID(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	if err := service.Logout(userID, sessionID); err != nil {
+		zap.L().Error("logic.Logout failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, nil)
+}
+
+// LogoutAllHandler 退出所有设备
+// @Summary 退出所有设备
+// @Description 注销当前用户的所有登录会话（包括当前会话）
+// @Tags 用户相关接口
+// @Produce application/json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Success 1000 {object} ResponseData{data=map[string]int64{count=int64}}
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /logout/all [post]
+func LogoutAllHandler(c *gin.Context) {
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+	count, err := service.LogoutAll(userID)
+	if err != nil {
+		zap.L().Error("logic.LogoutAll failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+	ResponseSuccess(c, gin.H{
+		"count": count,
+	})
+}
+
+// JWKSHandler 公开 JWT 签名公钥 GET /.well-known/jwks.json（不在 /api/v1 下）
+// 返回 RS256/EdDSA 签名密钥的公钥（JWKS 格式，包括轮换后仍用于验证的旧密钥），HS256 密钥不公开
+func JWKSHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwt.PublicKeys())
+}
+
 // GetUserInfoHandler 获取用户信息
 // @Summary 获取用户信息
-// @Description 获取指定用户的详细信息
+// @Description 获取指定用户的详细信息，包括粉丝数量和关注数量
 // @Tags 用户相关接口
 // @Accept application/json
 // @Produce application/json
 // @Param id path int true "用户ID"
-// @Success 1000 {object} ResponseData{data=map[string]string{user_id=string,username=string,avatar=string}}
+// @Success 1000 {object} ResponseData{data=map[string]interface{}{user_id=string,username=string,avatar=string,follower_count=int,following_count=int}}
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1003 {object} ResponseData "用户不存在"
 // @Failure 1005 {object} ResponseData "服务繁忙"
@@ -182,10 +276,22 @@ func GetUserInfoHandler(c *gin.Context) {
 		return
 	}
 
+	// 获取粉丝数量和关注数量
+	followers, following, err := service.GetFollowCounts(userID)
+	if err != nil {
+		zap.L().Error("logic.GetFollowCounts failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+
 	ResponseSuccess(c, gin.H{
-		"user_id":  fmt.Sprintf("%d", user.UserID),
-		"username": user.UserName,
-		"avatar":   user.GetAvatarURL(),
+		"user_id":         fmt.Sprintf("%d", user.UserID),
+		"username":        user.UserName,
+		"avatar":          user.GetAvatarURL(),
+		"follower_count":  followers,
+		"following_count": following,
 	})
 }
 
@@ -202,6 +308,7 @@ func GetUserInfoHandler(c *gin.Context) {
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1002 {object} ResponseData "用户名已存在"
 // @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1026 {object} ResponseData "内容包含敏感词"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /user/name [put]
 func UpdateUserNameHandler(c *gin.Context) {
@@ -229,6 +336,60 @@ func UpdateUserNameHandler(c *gin.Context) {
 			ResponseError(c, CodeUserExist)
 			return
 		}
+		if err == service.ErrorSensitiveWord {
+			ResponseError(c, CodeSensitiveWord)
+			return
+		}
+		ResponseError(c, CodeServerBusy)
+		return
+	}
+
+	ResponseSuccess(c, nil)
+}
+
+// UpdateUserRoleHandler 修改用户角色
+// @Summary 修改用户角色
+// @Description 管理员设置指定用户的角色（0普通用户,1版主,2管理员），角色变化后该用户的所有会话失效，需要重新登录
+// @Tags 用户相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param id path int true "用户ID"
+// @Param role body models.ParamUpdateRole true "角色信息"
+// @Success 1000 {object} ResponseData
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1003 {object} ResponseData "用户不存在"
+// @Failure 1011 {object} ResponseData "无操作权限"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /user/{id}/role [put]
+func UpdateUserRoleHandler(c *gin.Context) {
+	// 获取用户ID参数
+	userIDStr := c.Param("id")
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+
+	// 获取参数
+	p := new(models.ParamUpdateRole)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("UpdateUserRoleHandler with invalid param", zap.Error(err))
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+
+	// 修改角色
+	if err := service.UpdateUserRole(userID, *p.Role); err != nil {
+		zap.L().Error("logic.UpdateUserRole failed",
+			zap.Int64("user_id", userID),
+			zap.Int8("role", *p.Role),
+			zap.Error(err))
+		if err == mysql.ErrorUserNotExist {
+			ResponseError(c, CodeUserNotExist)
+			return
+		}
 		ResponseError(c, CodeServerBusy)
 		return
 	}
@@ -238,14 +399,14 @@ func UpdateUserNameHandler(c *gin.Context) {
 
 // UpdatePasswordHandler 修改密码
 // @Summary 修改密码
-// @Description 修改当前登录用户的密码
+// @Description 修改当前登录用户的密码，之前签发的所有token失效，返回当前设备新的token
 // @Tags 用户相关接口
 // @Accept application/json
 // @Produce application/json
 // @Security Bearer
 // @Param Authorization header string true "Bearer 用户令牌"
 // @Param password body models.ParamUpdatePassword true "密码信息"
-// @Success 1000 {object} ResponseData
+// @Success 1000 {object} ResponseData{data=map[string]string{access_token=string,refresh_token=string}}
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1004 {object} ResponseData "原密码错误"
 // @Failure 1008 {object} ResponseData "未登录"
@@ -268,7 +429,8 @@ func UpdatePasswordHandler(c *gin.Context) {
 	}
 
 	// 修改密码
-	if err := service.UpdatePassword(userID, p); err != nil {
+	user, err := service.UpdatePassword(userID, p)
+	if err != nil {
 		zap.L().Error("logic.UpdatePassword failed",
 			zap.Int64("user_id", userID),
 			zap.Error(err))
@@ -280,7 +442,11 @@ func UpdatePasswordHandler(c *gin.Context) {
 		return
 	}
 
-	ResponseSuccess(c, nil)
+	// 之前签发的 token 全部失效，返回当前设备新的 token
+	ResponseSuccess(c, gin.H{
+		"access_token":  user.AccessToken,
+		"refresh_token": user.RefreshToken,
+	})
 }
 
 // UpdateAvatarHandler 更新用户头像
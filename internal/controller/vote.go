@@ -2,6 +2,7 @@ package controller
 
 import (
 	"github.com/go-playground/validator/v10"
+	"go_community/internal/dao/mysql"
 	"go_community/internal/dao/redis"
 	"go_community/internal/models"
 	"go_community/internal/service"
@@ -23,8 +24,9 @@ import (
 // @Success 1000 {object} ResponseData{data=map[string]int64{vote_num=int64}}
 // @Failure 1001 {object} ResponseData "参数错误"
 // @Failure 1008 {object} ResponseData "未登录"
-// @Failure 1012 {object} ResponseData "重复投票"
-// @Failure 1013 {object} ResponseData "投票时间已过"
+// @Failure 1009 {object} ResponseData "重复投票"
+// @Failure 1010 {object} ResponseData "投票时间已过"
+// @Failure 1018 {object} ResponseData "尚未投票，无法撤销"
 // @Failure 1005 {object} ResponseData "服务繁忙"
 // @Router /vote [post]
 func VoteHandler(c *gin.Context) {
@@ -56,14 +58,56 @@ func VoteHandler(c *gin.Context) {
 	voteNum, err := service.VoteForTarget(userID, vote)
 	if err != nil {
 		zap.L().Error("logic.VoteForTarget failed", zap.Error(err))
-		switch err {
-		case redis.ErrorVoteRepeted:
-			ResponseError(c, CodeVoteRepeated)
-		case redis.ErrorVoteTimeExpire:
-			ResponseError(c, CodeVoteTimeExpire)
-		default:
-			ResponseError(c, CodeServerBusy)
+		responseVoteError(c, err)
+		return
+	}
+
+	ResponseSuccess(c, gin.H{
+		"vote_num": voteNum,
+	})
+}
+
+// RetractVoteHandler 撤销投票
+// @Summary 撤销投票
+// @Description 撤销对帖子或评论的投票
+// @Tags 投票相关接口
+// @Accept application/json
+// @Produce application/json
+// @Security Bearer
+// @Param Authorization header string true "Bearer 用户令牌"
+// @Param object query models.ParamRetractVote true "撤销投票的目标"
+// @Success 1000 {object} ResponseData{data=map[string]int64{vote_num=int64}}
+// @Failure 1001 {object} ResponseData "参数错误"
+// @Failure 1008 {object} ResponseData "未登录"
+// @Failure 1010 {object} ResponseData "投票时间已过"
+// @Failure 1018 {object} ResponseData "尚未投票，无法撤销"
+// @Failure 1005 {object} ResponseData "服务繁忙"
+// @Router /vote [delete]
+func RetractVoteHandler(c *gin.Context) {
+	// 1.获取请求参数和参数校验
+	p := new(models.ParamRetractVote)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("RetractVoteHandler with invalid param", zap.Error(err))
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			ResponseErrorWithMsg(c, CodeInvalidParams, removeTopStruct(errs.Translate(trans)))
+			return
 		}
+		ResponseError(c, CodeInvalidParams)
+		return
+	}
+
+	// 获取当前用户
+	userID, err := getCurrentUserId(c)
+	if err != nil {
+		ResponseError(c, CodeNotLogin)
+		return
+	}
+
+	// 撤销投票并获取最新点赞数
+	voteNum, err := service.RetractVote(userID, p)
+	if err != nil {
+		zap.L().Error("logic.RetractVote failed", zap.Error(err))
+		responseVoteError(c, err)
 		return
 	}
 
@@ -71,3 +115,19 @@ func VoteHandler(c *gin.Context) {
 		"vote_num": voteNum,
 	})
 }
+
+// responseVoteError 将投票相关的错误转换为响应
+func responseVoteError(c *gin.Context, err error) {
+	switch err {
+	case redis.ErrorVoteRepeted:
+		ResponseError(c, CodeVoteRepeated)
+	case redis.ErrorVoteTimeExpire:
+		ResponseError(c, CodeVoteTimeExpire)
+	case redis.ErrorVoteNotExist:
+		ResponseError(c, CodeVoteNotExist)
+	case mysql.ErrorInvalidID:
+		ResponseError(c, CodeInvalidParams)
+	default:
+		ResponseError(c, CodeServerBusy)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"go_community/internal/models"
 	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
 
 	"go.uber.org/zap"
 )
@@ -63,12 +66,16 @@ func GetCommentCount(postId int64) (count int64, err error) {
 	return count, nil
 }
 
-// GetCommentList 获取帖子的评论列表(分页)
-func GetCommentList(postId int64, page, size int64) ([]*models.Comment, error) {
+// GetCommentList 获取帖子的评论列表(分页)，asc 为 true 时按发布时间升序
+func GetCommentList(postId int64, page, size int64, asc bool) ([]*models.Comment, error) {
+	order := "desc"
+	if asc {
+		order = "asc"
+	}
 	sqlStr := `select comment_id, parent_id, post_id, author_id, content, create_time
 	from comment 
 	where post_id = ? and parent_id = 0 and status = 1 
-	order by create_time desc
+	order by create_time ` + order + `, comment_id ` + order + `
 	limit ?, ?`
 
 	comments := make([]*models.Comment, 0)
@@ -76,6 +83,39 @@ func GetCommentList(postId int64, page, size int64) ([]*models.Comment, error) {
 	return comments, err
 }
 
+// GetCommentListByIds 根据给定的id列表查询评论数据（保持给定的顺序）
+func GetCommentListByIds(ids []string) ([]*models.Comment, error) {
+	comments := make([]*models.Comment, 0, len(ids))
+	if len(ids) == 0 {
+		return comments, nil
+	}
+	sqlStr := `select comment_id, parent_id, post_id, author_id, content, create_time
+	from comment
+	where comment_id in (?) and status = 1
+	order by FIND_IN_SET(comment_id, ?)`
+
+	query, args, err := sqlx.In(sqlStr, ids, strings.Join(ids, ","))
+	if err != nil {
+		return nil, err
+	}
+	query = db.Rebind(query)
+	err = db.Select(&comments, query, args...)
+	return comments, err
+}
+
+// GetCommentListAfterID 按评论ID升序分批查询评论（用于重建缓存）
+func GetCommentListAfterID(lastID, size int64) ([]*models.Comment, error) {
+	sqlStr := `select comment_id, parent_id, post_id, author_id, create_time
+	from comment
+	where comment_id > ? and status = 1
+	order by comment_id
+	limit ?`
+
+	comments := make([]*models.Comment, 0, size)
+	err := db.Select(&comments, sqlStr, lastID, size)
+	return comments, err
+}
+
 // GetCommentReplyCount 获取评论的回复数量
 func GetCommentReplyCount(commentId int64) (int64, error) {
 	sqlStr := `select count(*) from comment where parent_id = ? and status = 1`
@@ -96,6 +136,47 @@ func GetCommentReplyList(commentId int64) ([]*models.Comment, error) {
 	return comments, err
 }
 
+// GetCommentRepliesAfterID 按评论ID升序查询评论的直接回复（游标分页，不包含 afterID 本身）
+func GetCommentRepliesAfterID(parentID, afterID, size int64) ([]*models.Comment, error) {
+	sqlStr := `select comment_id, parent_id, post_id, author_id, reply_to_uid, content, create_time
+	from comment
+	where parent_id = ? and comment_id > ? and status = 1
+	order by comment_id
+	limit ?`
+
+	comments := make([]*models.Comment, 0, size)
+	err := db.Select(&comments, sqlStr, parentID, afterID, size)
+	return comments, err
+}
+
+// GetCommentReplyCounts 批量查询评论的直接回复数量
+func GetCommentReplyCounts(ids []string) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	sqlStr := `select parent_id, count(*) as reply_count
+	from comment
+	where parent_id in (?) and status = 1
+	group by parent_id`
+
+	query, args, err := sqlx.In(sqlStr, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]struct {
+		ParentID   int64 `db:"parent_id"`
+		ReplyCount int64 `db:"reply_count"`
+	}, 0, len(ids))
+	if err = db.Select(&rows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ParentID] = row.ReplyCount
+	}
+	return counts, nil
+}
+
 // GetCommentById 根据评论ID获取评论
 func GetCommentById(commentId int64) (comment *models.Comment, err error) {
 	comment = new(models.Comment)
@@ -109,6 +190,23 @@ func GetCommentById(commentId int64) (comment *models.Comment, err error) {
 	return
 }
 
+// HideComment 隐藏评论（版主处理举报）
+func HideComment(commentID int64) error {
+	sqlStr := `update comment set status = 2 where comment_id = ? and status = 1`
+	result, err := db.Exec(sqlStr, commentID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrorInvalidID
+	}
+	return nil
+}
+
 // DeleteCommentWithTx 删除评论(使用事务)
 func DeleteCommentWithTx(tx *sql.Tx, commentID int64) error {
 	sqlStr := `update comment set status = 0 where comment_id = ? and status = 1`
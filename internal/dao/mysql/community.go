@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"go.uber.org/zap"
 	"go_community/internal/models"
+
+	"github.com/jmoiron/sqlx"
 )
 
 // GetCommunityList 查询分类社区列表
@@ -45,19 +47,18 @@ func GetCommunityTotalCount() (count int64, err error) {
 // GetCommunityDetailById 根据ID查询社区详情
 func GetCommunityDetailById(communityID int64) (*models.CommunityDetail, error) {
 	community := new(models.CommunityDetail)
-	sqlStr := `select community_id, community_name, introduction, create_time 
+	sqlStr := `select community_id, community_name, introduction, owner_id, create_time 
 	from community 
 	where community_id = ? and status = 1`
 	err := db.Get(community, sqlStr, communityID)
+	if err == sql.ErrNoRows {
+		return nil, ErrorInvalidID
+	}
 	if err != nil {
 		zap.L().Error("query community failed",
 			zap.String("sql", sqlStr),
 			zap.Error(err))
-		err = ErrorQueryFailed
-		if err == sql.ErrNoRows {
-			return nil, ErrorInvalidID
-		}
-		return nil, err
+		return nil, ErrorQueryFailed
 	}
 	return community, nil
 }
@@ -65,7 +66,7 @@ func GetCommunityDetailById(communityID int64) (*models.CommunityDetail, error)
 // GetCommunityDetailByName 根据名称查询社区详情
 func GetCommunityDetailByName(communityName string) (community *models.CommunityDetail, err error) {
 	community = new(models.CommunityDetail)
-	sqlStr := `select community_id, community_name, introduction, create_time, status
+	sqlStr := `select community_id, community_name, introduction, owner_id, create_time, status
 	from community
 	where community_name = ? and status = 1`
 	err = db.Get(community, sqlStr, communityName)
@@ -87,10 +88,10 @@ func CreateCommunity(community *models.CommunityDetail) (err error) {
 	// 设置默认状态为1
 	community.Status = 1
 	sqlStr := `insert into community(
-	community_id, community_name, introduction, status)
-	values(?,?,?,?)`
+	community_id, community_name, introduction, owner_id, status)
+	values(?,?,?,?,?)`
 	_, err = db.Exec(sqlStr, community.CommunityID,
-		community.CommunityName, community.Introduction, community.Status)
+		community.CommunityName, community.Introduction, community.OwnerID, community.Status)
 	if err != nil {
 		zap.L().Error("CreateCommunity failed",
 			zap.String("sql", sqlStr),
@@ -154,3 +155,135 @@ func UpdateCommunityStatus(id int64, status int8) error {
 	}
 	return nil
 }
+
+// AddCommunityModerator 添加社区版主（重复添加时忽略）
+func AddCommunityModerator(communityID, userID int64) error {
+	sqlStr := `insert ignore into community_moderator(community_id, user_id) values(?,?)`
+	_, err := db.Exec(sqlStr, communityID, userID)
+	return err
+}
+
+// RemoveCommunityModerator 移除社区版主
+func RemoveCommunityModerator(communityID, userID int64) error {
+	sqlStr := `delete from community_moderator where community_id = ? and user_id = ?`
+	result, err := db.Exec(sqlStr, communityID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrorInvalidID
+	}
+	return nil
+}
+
+// IsCommunityModerator 判断用户是否是社区版主
+func IsCommunityModerator(communityID, userID int64) (bool, error) {
+	sqlStr := `select count(id) from community_moderator where community_id = ? and user_id = ?`
+	var count int64
+	if err := db.Get(&count, sqlStr, communityID, userID); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetCommunityModerators 获取社区的版主列表
+func GetCommunityModerators(communityID int64) (moderators []*models.CommunityModerator, err error) {
+	sqlStr := `select community_id, user_id, create_time
+	from community_moderator
+	where community_id = ?
+	order by create_time`
+	moderators = make([]*models.CommunityModerator, 0)
+	err = db.Select(&moderators, sqlStr, communityID)
+	return
+}
+
+// JoinCommunity 加入社区，返回 false 表示已经是社区成员
+func JoinCommunity(communityID, userID int64) (bool, error) {
+	sqlStr := `insert ignore into community_member(community_id, user_id) values(?,?)`
+	result, err := db.Exec(sqlStr, communityID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// LeaveCommunity 退出社区，返回 false 表示不是社区成员
+func LeaveCommunity(communityID, userID int64) (bool, error) {
+	sqlStr := `delete from community_member where community_id = ? and user_id = ?`
+	result, err := db.Exec(sqlStr, communityID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetCommunityMemberCounts 批量查询社区的成员数量，没有成员的社区不在结果中
+func GetCommunityMemberCounts(communityIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(communityIDs))
+	if len(communityIDs) == 0 {
+		return counts, nil
+	}
+	sqlStr := `select community_id, count(*) as member_count
+	from community_member
+	where community_id in (?)
+	group by community_id`
+
+	query, args, err := sqlx.In(sqlStr, communityIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]struct {
+		CommunityID int64 `db:"community_id"`
+		MemberCount int64 `db:"member_count"`
+	}, 0, len(communityIDs))
+	if err = db.Select(&rows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.CommunityID] = row.MemberCount
+	}
+	return counts, nil
+}
+
+// GetJoinedCommunityCount 查询用户加入的社区数量（不包括已删除的社区）
+func GetJoinedCommunityCount(userID int64) (count int64, err error) {
+	sqlStr := `select count(m.id)
+	from community_member m join community c on c.community_id = m.community_id
+	where m.user_id = ? and c.status = 1`
+	err = db.Get(&count, sqlStr, userID)
+	return
+}
+
+// GetJoinedCommunityList 分页查询用户加入的社区，按加入时间倒序
+func GetJoinedCommunityList(userID, page, size int64) (communities []*models.CommunityDetail, err error) {
+	sqlStr := `select c.community_id, c.community_name, c.introduction, c.owner_id, c.create_time
+	from community_member m join community c on c.community_id = m.community_id
+	where m.user_id = ? and c.status = 1
+	order by m.id desc
+	limit ?,?`
+	communities = make([]*models.CommunityDetail, 0, size)
+	err = db.Select(&communities, sqlStr, userID, (page-1)*size, size)
+	return
+}
+
+// GetJoinedCommunityIDs 查询用户加入的所有社区的id（不包括已删除的社区）
+func GetJoinedCommunityIDs(userID int64) (ids []int64, err error) {
+	sqlStr := `select m.community_id
+	from community_member m join community c on c.community_id = m.community_id
+	where m.user_id = ? and c.status = 1`
+	ids = make([]int64, 0)
+	err = db.Select(&ids, sqlStr, userID)
+	return
+}
@@ -4,6 +4,7 @@ import "errors"
 
 var (
 	ErrorUserExist     = errors.New("用户已存在")
+	ErrorEmailExist    = errors.New("邮箱已被注册")
 	ErrorUserNotExist  = errors.New("用户不存在")
 	ErrorPasswordWrong = errors.New("密码错误")
 	ErrorGenIDFailed   = errors.New("创建用户ID失败")
@@ -11,4 +12,7 @@ var (
 	ErrorQueryFailed   = errors.New("查询数据失败")
 	ErrorInsertFailed  = errors.New("插入数据失败")
 	ErrorNoPermission  = errors.New("无操作权限")
+	ErrorUserBanned    = errors.New("账号已被封禁")
+	ErrorReportExist   = errors.New("已举报过该内容")
+	ErrorReportClaimed = errors.New("举报已被其他人认领")
 )
@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"go_community/internal/models"
+)
+
+// Follow 关注用户，返回 false 表示已经关注过
+func Follow(followerID, followeeID int64) (bool, error) {
+	sqlStr := `insert ignore into follow(follower_id, followee_id) values(?,?)`
+	result, err := db.Exec(sqlStr, followerID, followeeID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// Unfollow 取消关注，返回 false 表示没有关注过
+func Unfollow(followerID, followeeID int64) (bool, error) {
+	sqlStr := `delete from follow where follower_id = ? and followee_id = ?`
+	result, err := db.Exec(sqlStr, followerID, followeeID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetFollowerCount 查询用户的粉丝数量
+func GetFollowerCount(userID int64) (count int64, err error) {
+	err = db.Get(&count, `select count(id) from follow where followee_id = ?`, userID)
+	return
+}
+
+// GetFollowingCount 查询用户关注的人数
+func GetFollowingCount(userID int64) (count int64, err error) {
+	err = db.Get(&count, `select count(id) from follow where follower_id = ?`, userID)
+	return
+}
+
+// GetFollowerList 分页查询用户的粉丝，按关注时间倒序
+func GetFollowerList(userID, page, size int64) (users []*models.ApiFollowUser, err error) {
+	sqlStr := `select u.user_id, u.username, u.avatar, f.create_time as follow_time
+	from follow f join user u on u.user_id = f.follower_id
+	where f.followee_id = ? and u.status in (1, 2)
+	order by f.id desc
+	limit ?,?`
+	users = make([]*models.ApiFollowUser, 0, size)
+	err = db.Select(&users, sqlStr, userID, (page-1)*size, size)
+	return
+}
+
+// GetFollowingList 分页查询用户关注的人，按关注时间倒序
+func GetFollowingList(userID, page, size int64) (users []*models.ApiFollowUser, err error) {
+	sqlStr := `select u.user_id, u.username, u.avatar, f.create_time as follow_time
+	from follow f join user u on u.user_id = f.followee_id
+	where f.follower_id = ? and u.status in (1, 2)
+	order by f.id desc
+	limit ?,?`
+	users = make([]*models.ApiFollowUser, 0, size)
+	err = db.Select(&users, sqlStr, userID, (page-1)*size, size)
+	return
+}
+
+// GetFollowersAfterID 按 id 分批查询用户的粉丝（用于推送动态）
+func GetFollowersAfterID(userID, lastID, size int64) (follows []*models.Follow, err error) {
+	sqlStr := `select id, follower_id, followee_id, create_time
+	from follow
+	where followee_id = ? and id > ?
+	order by id
+	limit ?`
+	follows = make([]*models.Follow, 0, size)
+	err = db.Select(&follows, sqlStr, userID, lastID, size)
+	return
+}
+
+// GetFollowsAfterID 按 id 分批查询所有关注关系（用于重建 redis）
+func GetFollowsAfterID(lastID, size int64) (follows []*models.Follow, err error) {
+	sqlStr := `select id, follower_id, followee_id, create_time
+	from follow
+	where id > ?
+	order by id
+	limit ?`
+	follows = make([]*models.Follow, 0, size)
+	err = db.Select(&follows, sqlStr, lastID, size)
+	return
+}
+
+// GetFolloweeIDs 查询用户关注的所有用户id
+func GetFolloweeIDs(userID int64) (ids []int64, err error) {
+	err = db.Select(&ids, `select followee_id from follow where follower_id = ?`, userID)
+	return
+}
+
+// GetUserIDsByFollowerCount 查询粉丝数量不少于 min 的用户id
+func GetUserIDsByFollowerCount(min int64) (ids []int64, err error) {
+	sqlStr := `select followee_id from follow group by followee_id having count(id) >= ?`
+	err = db.Select(&ids, sqlStr, min)
+	return
+}
+
+// GetUserRecentPosts 查询用户最近发布的帖子id及发布时间
+func GetUserRecentPosts(userID, limit int64) (posts []*models.Post, err error) {
+	sqlStr := `select post_id, create_time
+	from post
+	where author_id = ? and status = 1
+	order by create_time desc
+	limit ?`
+	posts = make([]*models.Post, 0, limit)
+	err = db.Select(&posts, sqlStr, userID, limit)
+	return
+}
@@ -0,0 +1,96 @@
+package mysql
+
+import (
+	"go_community/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// GetUsersByNames 根据用户名批量查询用户，不存在的用户名忽略
+func GetUsersByNames(names []string) (users []*models.User, err error) {
+	users = make([]*models.User, 0, len(names))
+	if len(names) == 0 {
+		return
+	}
+	sqlStr := `select user_id, username, avatar from user where username in (?) and status in (1, 2)`
+	query, args, err := sqlx.In(sqlStr, names)
+	if err != nil {
+		return
+	}
+	err = db.Select(&users, db.Rebind(query), args...)
+	return
+}
+
+// SaveMentions 保存帖子或评论中 @ 的用户（替换之前的记录），返回新增的用户id
+func SaveMentions(targetType int8, targetID int64, mentions []*models.Mention) (added []int64, err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var existing []int64
+	sqlStr := `select user_id from mention where target_type = ? and target_id = ? for update`
+	if err = tx.Select(&existing, sqlStr, targetType, targetID); err != nil {
+		return nil, err
+	}
+	if _, err = tx.Exec(`delete from mention where target_type = ? and target_id = ?`, targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	mentioned := make(map[int64]bool, len(existing))
+	for _, userID := range existing {
+		mentioned[userID] = true
+	}
+	sqlStr = `insert into mention(target_type, target_id, user_id, username) values(?,?,?,?)`
+	for _, m := range mentions {
+		if _, err = tx.Exec(sqlStr, targetType, targetID, m.UserID, m.UserName); err != nil {
+			zap.L().Error("SaveMentions failed",
+				zap.String("sql", sqlStr),
+				zap.Any("mention", m),
+				zap.Error(err))
+			return nil, err
+		}
+		if !mentioned[m.UserID] {
+			added = append(added, m.UserID)
+		}
+	}
+	return added, nil
+}
+
+// GetMentions 批量查询帖子或评论中 @ 的用户，avatar 为用户当前头像的文件名
+func GetMentions(targetType int8, targetIDs []int64) (map[int64][]*models.ApiMention, error) {
+	data := make(map[int64][]*models.ApiMention, len(targetIDs))
+	if len(targetIDs) == 0 {
+		return data, nil
+	}
+	sqlStr := `select m.target_id, m.user_id, m.username, u.avatar
+	from mention m join user u on u.user_id = m.user_id
+	where m.target_type = ? and m.target_id in (?)
+	order by m.id`
+	query, args, err := sqlx.In(sqlStr, targetType, targetIDs)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		TargetID int64 `db:"target_id"`
+		models.ApiMention
+	}
+	if err := db.Select(&rows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		data[rows[i].TargetID] = append(data[rows[i].TargetID], &rows[i].ApiMention)
+	}
+	return data, nil
+}
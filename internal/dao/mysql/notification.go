@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"go_community/internal/models"
+)
+
+// CreateNotifications 批量保存通知
+func CreateNotifications(notifications []*models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	sqlStr := `insert into notification(notification_id, user_id, actor_id, type, target_type, target_id, post_id, content, create_time)
+	values(:notification_id, :user_id, :actor_id, :type, :target_type, :target_id, :post_id, :content, :create_time)`
+	_, err := db.NamedExec(sqlStr, notifications)
+	return err
+}
+
+// notificationWhere 通知列表的查询条件
+func notificationWhere(userID int64, unreadOnly bool) (string, []interface{}) {
+	where := ` where user_id = ?`
+	if unreadOnly {
+		where += ` and is_read = 0`
+	}
+	return where, []interface{}{userID}
+}
+
+// GetNotificationCount 查询用户的通知数量，unreadOnly 为 true 时只统计未读通知
+func GetNotificationCount(userID int64, unreadOnly bool) (count int64, err error) {
+	where, args := notificationWhere(userID, unreadOnly)
+	err = db.Get(&count, `select count(id) from notification`+where, args...)
+	return
+}
+
+// GetNotificationList 分页查询用户的通知，按时间倒序
+func GetNotificationList(userID int64, unreadOnly bool, page, size int64) (notifications []*models.Notification, err error) {
+	where, args := notificationWhere(userID, unreadOnly)
+	sqlStr := `select notification_id, user_id, actor_id, type, target_type, target_id, post_id, content, is_read, create_time
+	from notification` + where + ` order by id desc limit ?,?`
+	notifications = make([]*models.Notification, 0, size)
+	err = db.Select(&notifications, sqlStr, append(args, (page-1)*size, size)...)
+	return
+}
+
+// MarkNotificationRead 将用户的一条通知标记为已读，返回是否由未读变为已读
+func MarkNotificationRead(userID, notificationID int64) (bool, error) {
+	sqlStr := `update notification set is_read = 1 where notification_id = ? and user_id = ? and is_read = 0`
+	result, err := db.Exec(sqlStr, notificationID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		return true, nil
+	}
+	// 区分通知不存在和已经是已读
+	var count int
+	if err := db.Get(&count, `select count(id) from notification where notification_id = ? and user_id = ?`,
+		notificationID, userID); err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, ErrorInvalidID
+	}
+	return false, nil
+}
+
+// MarkAllNotificationsRead 将用户的所有通知标记为已读，返回标记的数量
+func MarkAllNotificationsRead(userID int64) (int64, error) {
+	sqlStr := `update notification set is_read = 1 where user_id = ? and is_read = 0`
+	result, err := db.Exec(sqlStr, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
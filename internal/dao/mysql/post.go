@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"go_community/internal/models"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -88,6 +89,18 @@ func GetPostList(page, size int64) (posts []*models.Post, err error) {
 	return
 }
 
+// GetPostListAfterID 按帖子ID升序分批查询帖子（用于重建缓存）
+func GetPostListAfterID(lastID, size int64) (posts []*models.Post, err error) {
+	sqlStr := `select post_id, title, content, author_id, community_id, create_time
+	from post
+	where post_id > ? and status = 1
+	order by post_id
+	limit ?`
+	posts = make([]*models.Post, 0, size)
+	err = db.Select(&posts, sqlStr, lastID, size)
+	return
+}
+
 // GetPostListByIds 根据给定的id列表查询帖子数据
 func GetPostListByIds(ids []string) (posts []*models.Post, err error) {
 	// 初始化切片，设置合适的容量
@@ -96,10 +109,10 @@ func GetPostListByIds(ids []string) (posts []*models.Post, err error) {
 	sqlStr := `select post_id, title, content, author_id, community_id, create_time
 	from post
 	where post_id in (?) and status = 1
-	ORDER BY create_time DESC` // 使用 FIELD 保持顺序
+	order by FIND_IN_SET(post_id, ?)` // 使用 FIND_IN_SET 保持 redis 中的排序
 
 	// 使用 sqlx.In 来动态生成 IN 查询语句
-	query, args, err := sqlx.In(sqlStr, ids)
+	query, args, err := sqlx.In(sqlStr, ids, strings.Join(ids, ","))
 	if err != nil {
 		return
 	}
@@ -111,56 +124,6 @@ func GetPostListByIds(ids []string) (posts []*models.Post, err error) {
 	return
 }
 
-// GetPostListTotalCount 根据关键词查询帖子列表总数
-func GetPostListTotalCount(p *models.ParamPostList) (count int64, err error) {
-	// 根据帖子标题或者帖子内容模糊查询帖子列表总数
-	sqlStr := `select count(post_id)
-	from post
-	where status = 1
-	and (
-		title like ?
-		or content like ?
-	)`
-	keyword := "%" + p.Search + "%"
-	err = db.Get(&count, sqlStr, keyword, keyword)
-	if err != nil {
-		return
-	}
-	return
-}
-
-// GetPostListByKeywords 根据关键词查询帖子列表
-func GetPostListByKeywords(p *models.ParamPostList) (posts []*models.Post, err error) {
-	// 根据帖子标题或者帖子内容模糊查询帖子列表
-	sqlStr := `select post_id, title, content, author_id, community_id, create_time
-	from post
-	where status = 1
-	and (
-		title like ?
-		or content like ?
-	)
-	ORDER BY create_time
-	DESC
-	limit ?,?
-	`
-	// %keyword%
-	p.Search = "%" + p.Search + "%"
-	// 初始化 posts 切片
-	posts = make([]*models.Post, 0, 2)
-	// 执行查询
-	err = db.Select(&posts, sqlStr, p.Search, p.Search, (p.Page-1)*p.Size, p.Size)
-	if err != nil {
-		// 添加日志记录实际执行的 SQL
-		zap.L().Error("GetPostListByKeywords failed",
-			zap.String("sql", sqlStr),
-			zap.Any("param", p),
-			zap.Error(err))
-		err = ErrorQueryFailed
-		return
-	}
-	return
-}
-
 // UpdatePost 更新帖子
 func UpdatePost(postId int64, title string, content string) error {
 	sqlStr := `update post 
@@ -199,6 +162,23 @@ func GetUserPostList(userId, page, size int64) (posts []*models.Post, err error)
 	return
 }
 
+// HidePost 隐藏帖子（版主处理举报）
+func HidePost(postID int64) error {
+	sqlStr := `update post set status = 2 where post_id = ? and status = 1`
+	result, err := db.Exec(sqlStr, postID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrorInvalidID
+	}
+	return nil
+}
+
 // DeletePostWithTx 删除帖子(使用事务)
 func DeletePostWithTx(tx *sql.Tx, postID int64) error {
 	sqlStr := `update post set status = 0 where post_id = ? and status = 1`
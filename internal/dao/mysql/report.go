@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"database/sql"
+	"go_community/internal/models"
+	"time"
+)
+
+// CreateReport 创建举报
+func CreateReport(report *models.Report) error {
+	sqlStr := `insert into report(report_id, target_type, target_id, target_user_id, reporter_id, reason, detail)
+	values(?,?,?,?,?,?,?)`
+	_, err := db.Exec(sqlStr, report.ReportID, report.TargetType, report.TargetID, report.TargetUserID,
+		report.ReporterID, report.Reason, report.Detail)
+	return err
+}
+
+// CheckReportExist 检查用户是否已举报过该对象且尚未处理完
+func CheckReportExist(reporterID int64, targetType int8, targetID int64) error {
+	sqlStr := `select count(id) from report
+	where reporter_id = ? and target_type = ? and target_id = ? and status in (0, 1)`
+	var count int
+	if err := db.Get(&count, sqlStr, reporterID, targetType, targetID); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrorReportExist
+	}
+	return nil
+}
+
+// GetReportById 根据举报id查询举报
+func GetReportById(reportID int64) (report *models.Report, err error) {
+	report = new(models.Report)
+	sqlStr := `select report_id, target_type, target_id, target_user_id, reporter_id, reason, detail,
+	status, handler_id, action, note, claim_time, resolve_time, create_time
+	from report where report_id = ?`
+	if err = db.Get(report, sqlStr, reportID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrorInvalidID
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// reportListWhere 举报列表的查询条件，status 为 nil 时查询未处理完的举报
+func reportListWhere(status *int8, targetType int8) (string, []interface{}) {
+	where := ` where status in (0, 1)`
+	args := make([]interface{}, 0, 2)
+	if status != nil {
+		where = ` where status = ?`
+		args = append(args, *status)
+	}
+	if targetType != 0 {
+		where += ` and target_type = ?`
+		args = append(args, targetType)
+	}
+	return where, args
+}
+
+// GetReportCount 查询举报总数
+func GetReportCount(status *int8, targetType int8) (count int64, err error) {
+	where, args := reportListWhere(status, targetType)
+	err = db.Get(&count, `select count(id) from report`+where, args...)
+	return
+}
+
+// GetReportList 分页查询举报列表，按举报时间升序（先举报的先处理）
+func GetReportList(status *int8, targetType int8, page, size int64) (reports []*models.Report, err error) {
+	where, args := reportListWhere(status, targetType)
+	sqlStr := `select report_id, target_type, target_id, target_user_id, reporter_id, reason, detail,
+	status, handler_id, action, note, claim_time, resolve_time, create_time
+	from report` + where + ` order by create_time asc, id asc limit ?,?`
+	reports = make([]*models.Report, 0, size)
+	err = db.Select(&reports, sqlStr, append(args, (page-1)*size, size)...)
+	return
+}
+
+// ClaimReport 认领举报：待处理的举报，或认领时间早于 expireBefore（超时未处理）的举报可以被认领
+func ClaimReport(reportID, handlerID int64, expireBefore time.Time) error {
+	sqlStr := `update report set status = 1, handler_id = ?, claim_time = now()
+	where report_id = ? and (status = 0 or (status = 1 and (handler_id = ? or claim_time < ?)))`
+	result, err := db.Exec(sqlStr, handlerID, reportID, handlerID, expireBefore)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrorReportClaimed
+	}
+	return nil
+}
+
+// ResolveReports 处理举报，同一对象未处理完的举报一并标记为已处理，记录处理人、处理方式和时间
+func ResolveReports(targetType int8, targetID, handlerID int64, action int8, note string) (int64, error) {
+	sqlStr := `update report set status = 2, handler_id = ?, action = ?, note = ?, resolve_time = now()
+	where target_type = ? and target_id = ? and status in (0, 1)`
+	result, err := db.Exec(sqlStr, handlerID, action, note, targetType, targetID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
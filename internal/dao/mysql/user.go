@@ -1,31 +1,55 @@
 package mysql
 
 import (
-	"crypto/md5"
 	"database/sql"
-	"encoding/hex"
 	"fmt"
 	"go_community/internal/models"
 	"go_community/pkg/file"
+	"go_community/pkg/password"
 	"strings"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 // 把每一步数据库操作封装成函数
 // 等待 logic 层根据业务需求调用
 
-const secret = "liwenzhou.com"
+// verifyPassword 验证密码，哈希使用旧算法或旧参数时重新哈希并保存
+func verifyPassword(userID int64, originPassword, hashedPassword string) error {
+	ok, needRehash, err := password.Verify(originPassword, hashedPassword)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorPasswordWrong
+	}
+	if needRehash {
+		// 升级失败不影响本次验证，下次验证时重试
+		if err := rehashPassword(userID, originPassword, hashedPassword); err != nil {
+			zap.L().Error("rehash password failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
 
-// encryptPassword 对密码进行加密
-func encryptPassword(data []byte) (result string) {
-	h := md5.New()
-	h.Write([]byte(secret))
-	h.Write(data)
-	return hex.EncodeToString(h.Sum(nil))
+// rehashPassword 使用当前配置的算法重新哈希密码
+// 仅当数据库中的哈希未被修改时更新，避免覆盖并发修改的新密码
+func rehashPassword(userID int64, originPassword, oldHash string) error {
+	newHash, err := password.Hash(originPassword)
+	if err != nil {
+		return err
+	}
+	sqlStr := `update user set password = ? where user_id = ? and password = ? and status = 1`
+	_, err = db.Exec(sqlStr, newHash, userID, oldHash)
+	return err
 }
 
 // CheckUserExist 检查指定用户名的用户是否存在
 func CheckUserExist(username string) (err error) {
-	sqlStr := `select count(user_id) from user where username = ? and status = 1`
+	sqlStr := `select count(user_id) from user where username = ? and status in (1, 2)`
 	var count int
 	if err := db.Get(&count, sqlStr, username); err != nil {
 		return err
@@ -36,24 +60,40 @@ func CheckUserExist(username string) (err error) {
 	return
 }
 
+// CheckEmailExist 检查邮箱是否已被注册
+func CheckEmailExist(email string) (err error) {
+	sqlStr := `select count(user_id) from user where email = ?`
+	var count int
+	if err := db.Get(&count, sqlStr, email); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrorEmailExist
+	}
+	return
+}
+
 // InsertUser 向数据库中插入一条新的用户记录
 func InsertUser(user *models.User) (err error) {
 	// 生成加密密码
-	user.Password = encryptPassword([]byte(user.Password))
+	user.Password, err = password.Hash(user.Password)
+	if err != nil {
+		return err
+	}
 	// 设置随机默认头像 - 只存储文件名
 	user.Avatar = file.GetRandomDefaultAvatar()
 	// 设置默认状态为1
 	user.Status = 1
 	// 执行 SQL 语句入库
-	sqlStr := `insert into user(user_id, username, password, avatar, status) values (?,?,?,?,?)`
-	_, err = db.Exec(sqlStr, user.UserID, user.UserName, user.Password, user.Avatar, user.Status)
+	sqlStr := `insert into user(user_id, username, password, email, avatar, status) values (?,?,?,?,?,?)`
+	_, err = db.Exec(sqlStr, user.UserID, user.UserName, user.Password, user.Email, user.Avatar, user.Status)
 	return
 }
 
 // Login 用户登录
 func Login(user *models.User) (err error) {
 	originPassword := user.Password // 用户登录的原始密码
-	sqlStr := `select user_id, username, password from user where username = ? and status = 1`
+	sqlStr := `select user_id, username, password, role, status, token_version from user where username = ? and status in (1, 2)`
 	err = db.Get(user, sqlStr, user.UserName)
 	// 用户不存在
 	if err == sql.ErrNoRows {
@@ -64,17 +104,21 @@ func Login(user *models.User) (err error) {
 		return err
 	}
 	// 判断密码是否正确
-	password := encryptPassword([]byte(originPassword))
-	if user.Password != password {
-		return ErrorPasswordWrong
+	if err := verifyPassword(user.UserID, originPassword, user.Password); err != nil {
+		return err
 	}
-	return
+	// 密码正确后再提示封禁，避免泄露账号状态
+	if user.Status == models.UserStatusBanned {
+		return ErrorUserBanned
+	}
+	return nil
 }
 
-// GetUserById 根据ID查询作者信息
+// GetUserById 根据ID查询作者信息（包括被封禁的用户，保证其发布的内容正常显示）
 func GetUserById(id int64) (user *models.User, err error) {
 	user = new(models.User)
-	sqlStr := `select user_id, username, avatar from user where user_id = ? and status = 1`
+	sqlStr := `select user_id, username, avatar, role, status, ifnull(email, '') as email, email_verified, token_version
+	from user where user_id = ? and status in (1, 2)`
 	err = db.Get(user, sqlStr, id)
 	if err == sql.ErrNoRows {
 		return nil, ErrorUserNotExist
@@ -82,6 +126,52 @@ func GetUserById(id int64) (user *models.User, err error) {
 	return
 }
 
+// GetUserCreateTime 查询用户的注册时间
+func GetUserCreateTime(UserID int64) (createTime time.Time, err error) {
+	sqlStr := `select create_time from user where user_id = ? and status = 1`
+	err = db.Get(&createTime, sqlStr, UserID)
+	if err == sql.ErrNoRows {
+		return createTime, ErrorUserNotExist
+	}
+	return
+}
+
+// GetUserByEmail 根据邮箱查询用户
+func GetUserByEmail(email string) (user *models.User, err error) {
+	user = new(models.User)
+	sqlStr := `select user_id, username, role, email, email_verified, token_version
+	from user where email = ? and status = 1`
+	err = db.Get(user, sqlStr, email)
+	if err == sql.ErrNoRows {
+		return nil, ErrorUserNotExist
+	}
+	return
+}
+
+// SetEmailVerified 将用户邮箱标记为已验证，邮箱已变更时返回 ErrorInvalidID
+func SetEmailVerified(UserID int64, email string) error {
+	sqlStr := `update user set email_verified = 1 where user_id = ? and email = ? and status = 1`
+	result, err := db.Exec(sqlStr, UserID, email)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		// 已验证时 RowsAffected 同样为 0
+		var count int
+		if err := db.Get(&count, `select count(user_id) from user where user_id = ? and email = ? and status = 1`, UserID, email); err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrorInvalidID
+		}
+	}
+	return nil
+}
+
 // UpdateUserAvatar 更新用户头像
 func UpdateUserAvatar(UserID int64, avatarPath string) error {
 	sqlStr := `update user set avatar = ? where user_id = ? and status = 1`
@@ -99,6 +189,26 @@ func UpdateUserAvatar(UserID int64, avatarPath string) error {
 	return nil
 }
 
+// UpdateUserRole 更新用户角色，同时递增 token 版本使之前签发的 token（携带旧角色）失效
+// 返回新的 token 版本，角色未变化时不更新并返回 changed = false，用户是否存在由调用方提前校验
+func UpdateUserRole(UserID int64, role int8) (tokenVersion int64, changed bool, err error) {
+	sqlStr := `update user set role = ?, token_version = token_version + 1
+	where user_id = ? and status = 1 and role <> ?`
+	result, err := db.Exec(sqlStr, role, UserID, role)
+	if err != nil {
+		return 0, false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, false, err
+	}
+	if rows == 0 {
+		return 0, false, nil
+	}
+	tokenVersion, err = GetUserTokenVersion(UserID)
+	return tokenVersion, true, err
+}
+
 // UpdateUserName 更新用户名
 func UpdateUserName(UserID int64, p *models.ParamUpdateUser) error {
 	// 构建更新语句
@@ -136,7 +246,7 @@ func UpdateUserName(UserID int64, p *models.ParamUpdateUser) error {
 }
 
 // CheckPassword 检查密码是否正确
-func CheckPassword(UserID int64, password string) error {
+func CheckPassword(UserID int64, originPassword string) error {
 	sqlStr := `select password from user where user_id = ? and status = 1`
 	var hashedPassword string
 	if err := db.Get(&hashedPassword, sqlStr, UserID); err != nil {
@@ -147,25 +257,55 @@ func CheckPassword(UserID int64, password string) error {
 	}
 
 	// 验证密码
-	if hashedPassword != encryptPassword([]byte(password)) {
-		return ErrorPasswordWrong
+	return verifyPassword(UserID, originPassword, hashedPassword)
+}
+
+// UpdatePassword 更新密码，同时递增 token 版本使之前签发的 token 失效，返回新的 token 版本
+func UpdatePassword(UserID int64, newPassword string) (tokenVersion int64, err error) {
+	hashedPassword, err := password.Hash(newPassword)
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	sqlStr := `update user set password = ?, token_version = token_version + 1 where user_id = ? and status = 1`
+	result, err := db.Exec(sqlStr, hashedPassword, UserID)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rows == 0 {
+		return 0, ErrorInvalidID
+	}
+	return GetUserTokenVersion(UserID)
 }
 
-// UpdatePassword 更新密码
-func UpdatePassword(UserID int64, newPassword string) error {
-	sqlStr := `update user set password = ? where user_id = ? and status = 1`
-	result, err := db.Exec(sqlStr, encryptPassword([]byte(newPassword)), UserID)
+// BanUser 封禁用户，同时递增 token 版本使之前签发的 token 失效，返回新的 token 版本
+func BanUser(UserID int64) (tokenVersion int64, err error) {
+	sqlStr := `update user set status = 2, token_version = token_version + 1 where user_id = ? and status = 1`
+	result, err := db.Exec(sqlStr, UserID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	rows, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if rows == 0 {
-		return ErrorInvalidID
+		return 0, ErrorInvalidID
 	}
-	return nil
+	sqlStr = `select token_version from user where user_id = ?`
+	err = db.Get(&tokenVersion, sqlStr, UserID)
+	return
+}
+
+// GetUserTokenVersion 查询用户的 token 版本
+func GetUserTokenVersion(UserID int64) (tokenVersion int64, err error) {
+	sqlStr := `select token_version from user where user_id = ? and status = 1`
+	err = db.Get(&tokenVersion, sqlStr, UserID)
+	if err == sql.ErrNoRows {
+		return 0, ErrorUserNotExist
+	}
+	return
 }
@@ -0,0 +1,59 @@
+package mysql
+
+import (
+	"go_community/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// ArchiveVotes 批量保存归档的投票数据
+// 投票窗口关闭后数据不再变化，已归档的记录不会被覆盖
+func ArchiveVotes(archives []*models.VoteArchive) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	sqlStr := `insert ignore into vote_archive(
+	target_id, target_type, up_votes, down_votes, score)
+	values(?,?,?,?,?)`
+	for _, a := range archives {
+		if _, err = tx.Exec(sqlStr, a.TargetID, a.TargetType, a.UpVotes, a.DownVotes, a.Score); err != nil {
+			zap.L().Error("ArchiveVotes failed",
+				zap.String("sql", sqlStr),
+				zap.Any("archive", a),
+				zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// GetVoteArchives 根据给定的id列表批量查询归档的投票数据
+func GetVoteArchives(targetType int8, ids []string) (archives []*models.VoteArchive, err error) {
+	archives = make([]*models.VoteArchive, 0, len(ids))
+	if len(ids) == 0 {
+		return
+	}
+	sqlStr := `select target_id, target_type, up_votes, down_votes, score
+	from vote_archive
+	where target_type = ? and target_id in (?)`
+	query, args, err := sqlx.In(sqlStr, targetType, ids)
+	if err != nil {
+		return
+	}
+	query = db.Rebind(query)
+	err = db.Select(&archives, query, args...)
+	return
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+)
+
+/*
+	邮箱验证和重置密码 token
+	1.key 中只保存 token 的 sha256，redis 数据泄露时无法直接使用
+	2.token 只能使用一次：读取和删除在同一个事务中完成
+*/
+
+// SaveEmailVerifyToken 保存邮箱验证 token
+func SaveEmailVerifyToken(tokenHash string, userID int64, email string, expiration time.Duration) error {
+	return saveAccountToken(KeyEmailVerifyPrefix+tokenHash, map[string]interface{}{
+		"user_id": userID,
+		"email":   email,
+	}, expiration)
+}
+
+// ConsumeEmailVerifyToken 使用邮箱验证 token，返回用户id及验证的邮箱
+func ConsumeEmailVerifyToken(tokenHash string) (userID int64, email string, err error) {
+	fields, err := consumeAccountToken(KeyEmailVerifyPrefix + tokenHash)
+	if err != nil {
+		return 0, "", err
+	}
+	userID, err = strconv.ParseInt(fields["user_id"], 10, 64)
+	if err != nil {
+		return 0, "", ErrorTokenNotExist
+	}
+	return userID, fields["email"], nil
+}
+
+// SavePasswordResetToken 保存重置密码 token
+// tokenVersion 为签发时用户的 token 版本，密码修改后之前签发的重置 token 一并失效
+func SavePasswordResetToken(tokenHash string, userID, tokenVersion int64, expiration time.Duration) error {
+	return saveAccountToken(KeyPasswordResetPrefix+tokenHash, map[string]interface{}{
+		"user_id":       userID,
+		"token_version": tokenVersion,
+	}, expiration)
+}
+
+// ConsumePasswordResetToken 使用重置密码 token，返回用户id及签发时的 token 版本
+func ConsumePasswordResetToken(tokenHash string) (userID, tokenVersion int64, err error) {
+	fields, err := consumeAccountToken(KeyPasswordResetPrefix + tokenHash)
+	if err != nil {
+		return 0, 0, err
+	}
+	userID, err = strconv.ParseInt(fields["user_id"], 10, 64)
+	if err != nil {
+		return 0, 0, ErrorTokenNotExist
+	}
+	tokenVersion, err = strconv.ParseInt(fields["token_version"], 10, 64)
+	if err != nil {
+		return 0, 0, ErrorTokenNotExist
+	}
+	return userID, tokenVersion, nil
+}
+
+// saveAccountToken 保存 token 数据并设置过期时间
+func saveAccountToken(key string, fields map[string]interface{}, expiration time.Duration) error {
+	key = getRedisKey(key)
+	pipeline := client.TxPipeline()
+	pipeline.HMSet(key, fields)
+	pipeline.Expire(key, expiration)
+	_, err := pipeline.Exec()
+	return err
+}
+
+// consumeAccountToken 读取并删除 token 数据，不存在时返回 ErrorTokenNotExist
+func consumeAccountToken(key string) (map[string]string, error) {
+	key = getRedisKey(key)
+	pipeline := client.TxPipeline()
+	get := pipeline.HGetAll(key)
+	pipeline.Del(key)
+	if _, err := pipeline.Exec(); err != nil {
+		return nil, err
+	}
+	fields := get.Val()
+	if len(fields) == 0 {
+		return nil, ErrorTokenNotExist
+	}
+	return fields, nil
+}
@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"go_community/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+/*
+	投票数据归档
+	帖子/评论发布一周后投票窗口关闭，此后投票数据不再变化：
+		1.将 redis 中的赞成票数、反对票数及分数保存到 mysql
+		2.删除对应的 KeyPostVotedZSetPrefix / KeyCommentVotedZSetPrefix
+		3.帖子的重力衰减分数置 0
+	归档进度（已归档的最大发布时间）保存在游标 key 中，下次从游标之后继续扫描
+*/
+
+// GetExpiredPostIds 获取投票窗口已关闭、尚未归档的帖子（按发帖时间升序）
+func GetExpiredPostIds(batchSize int64) ([]redis.Z, error) {
+	return getExpiredIds(KeyPostTimeZSet, KeyPostArchiveCursor, batchSize)
+}
+
+// GetExpiredCommentIds 获取投票窗口已关闭、尚未归档的评论（按发布时间升序）
+func GetExpiredCommentIds(batchSize int64) ([]redis.Z, error) {
+	return getExpiredIds(KeyCommentTimeZSet, KeyCommentArchiveCursor, batchSize)
+}
+
+// getExpiredIds 从时间 ZSet 中查询游标之后、投票窗口已关闭的元素
+func getExpiredIds(timeKey, cursorKey string, batchSize int64) ([]redis.Z, error) {
+	min := "-inf"
+	cursor, err := client.Get(getRedisKey(cursorKey)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	if cursor != "" {
+		min = "(" + cursor // 不包含游标本身
+	}
+	max := strconv.FormatInt(time.Now().Unix()-OneWeekInSeconds, 10)
+
+	items, err := client.ZRangeByScoreWithScores(getRedisKey(timeKey), redis.ZRangeBy{
+		Min:   min,
+		Max:   max,
+		Count: batchSize,
+	}).Result()
+	if err != nil || int64(len(items)) < batchSize {
+		return items, err
+	}
+
+	// 批次已满时，去掉末尾与最后一个元素时间相同的元素，
+	// 避免游标推进后漏掉同一秒内发布、但不在本批次中的数据
+	last := items[len(items)-1].Score
+	end := len(items)
+	for end > 0 && items[end-1].Score == last {
+		end--
+	}
+	if end > 0 {
+		return items[:end], nil
+	}
+	// 整个批次都是同一秒发布的，一次性取出该秒内的全部元素
+	score := strconv.FormatFloat(last, 'f', -1, 64)
+	return client.ZRangeByScoreWithScores(getRedisKey(timeKey), redis.ZRangeBy{
+		Min: score,
+		Max: score,
+	}).Result()
+}
+
+// getVoteCounts 批量查询赞成票和反对票数量
+func getVoteCounts(votedPrefix string, ids []string) (up, down []int64, err error) {
+	pipeline := client.Pipeline()
+	for _, id := range ids {
+		key := getRedisKey(votedPrefix + id)
+		pipeline.ZCount(key, "1", "1")
+		pipeline.ZCount(key, "-1", "-1")
+	}
+	cmders, err := pipeline.Exec()
+	if err != nil {
+		return nil, nil, err
+	}
+	up = make([]int64, 0, len(ids))
+	down = make([]int64, 0, len(ids))
+	for i := 0; i < len(cmders); i += 2 {
+		up = append(up, cmders[i].(*redis.IntCmd).Val())
+		down = append(down, cmders[i+1].(*redis.IntCmd).Val())
+	}
+	return
+}
+
+// GetPostArchiveData 查询帖子待归档的投票数据
+func GetPostArchiveData(ids []string) ([]*models.VoteArchive, error) {
+	up, down, err := getVoteCounts(KeyPostVotedZSetPrefix, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询帖子分数
+	pipeline := client.Pipeline()
+	for _, id := range ids {
+		pipeline.ZScore(getRedisKey(KeyPostScoreZSet), id)
+	}
+	cmders, err := pipeline.Exec()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	data := make([]*models.VoteArchive, 0, len(ids))
+	for idx, id := range ids {
+		targetID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		data = append(data, &models.VoteArchive{
+			TargetID:   targetID,
+			TargetType: models.VoteTargetPost,
+			UpVotes:    up[idx],
+			DownVotes:  down[idx],
+			Score:      cmders[idx].(*redis.FloatCmd).Val(),
+		})
+	}
+	return data, nil
+}
+
+// GetCommentArchiveData 查询评论待归档的投票数据
+func GetCommentArchiveData(ids []string) ([]*models.VoteArchive, error) {
+	up, down, err := getVoteCounts(KeyCommentVotedZSetPrefix, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]*models.VoteArchive, 0, len(ids))
+	for idx, id := range ids {
+		targetID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		data = append(data, &models.VoteArchive{
+			TargetID:   targetID,
+			TargetType: models.VoteTargetComment,
+			UpVotes:    up[idx],
+			DownVotes:  down[idx],
+			Score:      float64(up[idx] - down[idx]), // 评论分数即净票数
+		})
+	}
+	return data, nil
+}
+
+// FinishPostArchive 删除已归档帖子的投票记录，并推进归档游标
+// 投票窗口关闭后帖子不再参与重力衰减排序，分数置 0
+func FinishPostArchive(ids []string, cursor float64) error {
+	pipeline := client.Pipeline()
+	for _, id := range ids {
+		pipeline.ZAdd(getRedisKey(KeyPostGravityZSet), redis.Z{Score: 0, Member: id})
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		return err
+	}
+	return finishArchive(KeyPostVotedZSetPrefix, KeyPostArchiveCursor, ids, cursor)
+}
+
+// FinishCommentArchive 删除已归档评论的投票记录，并推进归档游标
+func FinishCommentArchive(ids []string, cursor float64) error {
+	return finishArchive(KeyCommentVotedZSetPrefix, KeyCommentArchiveCursor, ids, cursor)
+}
+
+// finishArchive 删除投票记录并保存归档游标
+func finishArchive(votedPrefix, cursorKey string, ids []string, cursor float64) error {
+	pipeline := client.TxPipeline()
+	for _, id := range ids {
+		pipeline.Del(getRedisKey(votedPrefix + id))
+	}
+	pipeline.Set(getRedisKey(cursorKey), strconv.FormatFloat(cursor, 'f', -1, 64), 0)
+	_, err := pipeline.Exec()
+	return err
+}
@@ -1,6 +1,9 @@
 package redis
 
 import (
+	"go_community/internal/models"
+	"go_community/pkg/ranking"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -12,8 +15,40 @@ func GetCommentVoteNum(commentId string) (voteNum int64, err error) {
 	return client.ZCount(key, "1", "1").Result()
 }
 
+// GetCommentVoteCounts 根据ids批量查询评论的赞成票数、反对票数及当前用户的投票方向
+func GetCommentVoteCounts(ids []string, userId string) ([]*models.VoteCount, error) {
+	return getVoteData(KeyCommentVotedZSetPrefix, ids, userId)
+}
+
+// getCommentOrderKey 根据 order 参数确定帖子评论排序使用的 redis key
+func getCommentOrderKey(postId, order string) string {
+	if order == models.CommentOrderBest {
+		return getRedisKey(KeyCommentBestZSetPrefix + postId)
+	}
+	return getRedisKey(KeyCommentTopZSetPrefix + postId)
+}
+
+// GetCommentIdsInOrder 按净票数/Wilson score 从大到小分页查询帖子的一级评论id
+func GetCommentIdsInOrder(postId int64, order string, page, size int64) ([]string, error) {
+	key := getCommentOrderKey(strconv.FormatInt(postId, 10), order)
+	return getIdsFormKey(key, page, size)
+}
+
+// updateCommentRanks 根据 redis 中的投票记录重新计算一级评论的 Wilson score 分数
+func updateCommentRanks(postId, commentId string) error {
+	up, down, err := getVoteCounts(KeyCommentVotedZSetPrefix, []string{commentId})
+	if err != nil {
+		return err
+	}
+	return client.ZAdd(getRedisKey(KeyCommentBestZSetPrefix+postId), redis.Z{
+		Score:  ranking.Wilson(up[0], down[0], rankingConfig().WilsonZ),
+		Member: commentId,
+	}).Err()
+}
+
 // CreateComment 创建评论时记录到Redis
-func CreateComment(commentId int64) error {
+// 一级评论（parentId 为 0）同时加入所属帖子的评论排序 ZSet
+func CreateComment(commentId, postId, parentId int64) error {
 	now := float64(time.Now().Unix())
 	pipeline := client.TxPipeline()
 
@@ -22,21 +57,37 @@ func CreateComment(commentId int64) error {
 		Score:  now,
 		Member: commentId,
 	})
+	// 记录评论净票数
+	pipeline.ZAdd(getRedisKey(KeyCommentScoreZSet), redis.Z{
+		Score:  0,
+		Member: commentId,
+	})
+	// 记录帖子一级评论的排序分数
+	if parentId == 0 {
+		pid := strconv.FormatInt(postId, 10)
+		pipeline.ZAdd(getRedisKey(KeyCommentTopZSetPrefix+pid), redis.Z{Score: 0, Member: commentId})
+		pipeline.ZAdd(getRedisKey(KeyCommentBestZSetPrefix+pid), redis.Z{Score: 0, Member: commentId})
+	}
 
 	_, err := pipeline.Exec()
 	return err
 }
 
 // DeleteCommentsVoteData 批量删除评论的点赞数据
-func DeleteCommentsVoteData(commentIDs []string) error {
+func DeleteCommentsVoteData(postID string, commentIDs []string) error {
 	pipeline := client.TxPipeline()
 	
 	// 批量删除评论的点赞数据和时间记录
 	for _, commentID := range commentIDs {
+		// 删除帖子评论排序记录
+		pipeline.ZRem(getRedisKey(KeyCommentTopZSetPrefix+postID), commentID)
+		pipeline.ZRem(getRedisKey(KeyCommentBestZSetPrefix+postID), commentID)
 		// 删除评论点赞记录
 		pipeline.Del(getRedisKey(KeyCommentVotedZSetPrefix + commentID))
 		// 删除评论时间记录
 		pipeline.ZRem(getRedisKey(KeyCommentTimeZSet), commentID)
+		// 删除评论净票数记录
+		pipeline.ZRem(getRedisKey(KeyCommentScoreZSet), commentID)
 	}
 	
 	_, err := pipeline.Exec()
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// getCommunityFeedKey 用户加入的社区按指定排序合并后的缓存 key
+func getCommunityFeedKey(userID int64, orderKey string) string {
+	return getRedisKey(KeyCommunityFeedPrefix + strconv.FormatInt(userID, 10) + ":" + orderKey)
+}
+
+// GetJoinedCommunityPostIds 查询用户加入的社区中的帖子 ids（根据 order 从大到小排序）及帖子总数
+// 与 GetCommunityPostIdsInOrder 相同，合并结果保存在短期的缓存 key 中，减少 zunionstore 执行的次数
+func GetJoinedCommunityPostIds(userID int64, communityIDs []int64, order string, page, size int64) ([]string, int64, error) {
+	if len(communityIDs) == 0 {
+		return []string{}, 0, nil
+	}
+	orderName, ok := orderKeys[order]
+	if !ok {
+		orderName = KeyPostTimeZSet
+	}
+	orderKey := getRedisKey(orderName)
+	key := getCommunityFeedKey(userID, orderName)
+	if client.Exists(key).Val() < 1 {
+		communityKeys := make([]string, 0, len(communityIDs))
+		for _, id := range communityIDs {
+			communityKeys = append(communityKeys, getRedisKey(KeyCommunityPostSetPrefix+strconv.FormatInt(id, 10)))
+		}
+		// 1.zunionstore: 合并各社区的帖子 set
+		// 2.zinterstore: 与排序 ZSet 求交集，权重为 0 的帖子 set 不影响分数，同时去掉已删除的帖子
+		pipeline := client.TxPipeline()
+		pipeline.ZUnionStore(key, redis.ZStore{}, communityKeys...)
+		pipeline.ZInterStore(key, redis.ZStore{
+			Weights: []float64{0, 1},
+		}, key, orderKey)
+		pipeline.Expire(key, 60*time.Second) // 设置超时时间
+		if _, err := pipeline.Exec(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	pipeline := client.Pipeline()
+	total := pipeline.ZCard(key)
+	start := (page - 1) * size
+	ids := pipeline.ZRevRange(key, start, start+size-1)
+	if _, err := pipeline.Exec(); err != nil {
+		return nil, 0, err
+	}
+	return ids.Val(), total.Val(), nil
+}
+
+// DeleteJoinedCommunityFeed 删除用户加入的社区合并后的缓存（加入或退出社区后调用）
+func DeleteJoinedCommunityFeed(userID int64) error {
+	keys := make([]string, 0, len(orderKeys))
+	for _, orderName := range orderKeys {
+		keys = append(keys, getCommunityFeedKey(userID, orderName))
+	}
+	return client.Del(keys...).Err()
+}
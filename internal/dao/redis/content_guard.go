@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const maxGuardSimHashes = 100 // 每个用户保存的最近发布内容指纹的数量上限
+
+// acquirePublishScript 检查发布冷却和发布数量，通过时开始新的冷却并计数
+// KEYS[1] 冷却，KEYS[2] 发布数量
+// ARGV[1] 冷却时长（毫秒），ARGV[2] 数量上限（0 表示不限制），ARGV[3] 统计窗口（毫秒）
+// 返回 {0 通过/1 冷却中/2 达到数量上限, 需要等待的时长（毫秒）}
+var acquirePublishScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > 0 then
+	return {1, ttl}
+end
+local limit = tonumber(ARGV[2])
+if limit > 0 then
+	local count = tonumber(redis.call('GET', KEYS[2]) or '0')
+	if count >= limit then
+		return {2, redis.call('PTTL', KEYS[2])}
+	end
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call('SET', KEYS[1], 1, 'PX', ARGV[1])
+end
+if limit > 0 and redis.call('INCR', KEYS[2]) == 1 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+end
+return {0, 0}
+`)
+
+// 发布被拒绝的原因
+const (
+	PublishAllowed  = 0 // 通过
+	PublishCooldown = 1 // 冷却中
+	PublishDailyCap = 2 // 达到数量上限
+)
+
+// AcquirePublish 检查用户是否可以发布（kind 为 post 或 comment），通过时开始新的冷却并计数
+// 返回拒绝的原因及需要等待的时长
+func AcquirePublish(kind string, userID int64, interval time.Duration, dailyLimit int64, window time.Duration) (reason int64, wait time.Duration, err error) {
+	id := kind + ":" + strconv.FormatInt(userID, 10)
+	keys := []string{getRedisKey(KeyGuardCooldownPrefix + id), getRedisKey(KeyGuardDailyPrefix + id)}
+	values, err := acquirePublishScript.Run(client, keys,
+		int64(interval/time.Millisecond), dailyLimit, int64(window/time.Millisecond)).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	fields, ok := values.([]interface{})
+	if !ok || len(fields) != 2 {
+		return 0, 0, redis.Nil
+	}
+	reason, _ = fields[0].(int64)
+	ms, _ := fields[1].(int64)
+	return reason, time.Duration(ms) * time.Millisecond, nil
+}
+
+// GetRecentSimHashes 查询用户在 window 内发布内容的指纹
+func GetRecentSimHashes(kind string, userID int64, window time.Duration) ([]uint64, error) {
+	key := getRedisKey(KeyGuardSimHashZSetPrefix + kind + ":" + strconv.FormatInt(userID, 10))
+	min := strconv.FormatInt(time.Now().Add(-window).Unix(), 10)
+	members, err := client.ZRangeByScore(key, redis.ZRangeBy{Min: min, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]uint64, 0, len(members))
+	for _, member := range members {
+		h, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, h)
+	}
+	return hashes, nil
+}
+
+// AddSimHash 记录用户发布内容的指纹，并清理 window 之前及超出数量上限的指纹
+func AddSimHash(kind string, userID int64, hash uint64, window time.Duration) error {
+	key := getRedisKey(KeyGuardSimHashZSetPrefix + kind + ":" + strconv.FormatInt(userID, 10))
+	now := time.Now()
+	pipeline := client.TxPipeline()
+	pipeline.ZAdd(key, redis.Z{
+		Score:  float64(now.Unix()),
+		Member: strconv.FormatUint(hash, 10),
+	})
+	pipeline.ZRemRangeByScore(key, "-inf", "("+strconv.FormatInt(now.Add(-window).Unix(), 10))
+	pipeline.ZRemRangeByRank(key, 0, -maxGuardSimHashes-1)
+	pipeline.Expire(key, window)
+	_, err := pipeline.Exec()
+	return err
+}
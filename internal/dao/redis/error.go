@@ -5,4 +5,9 @@ import "errors"
 var (
 	ErrorVoteTimeExpire = errors.New("投票时间已过")
 	ErrorVoteRepeted    = errors.New("不允许重复投票")
+	ErrorVoteNotExist   = errors.New("尚未投票，无法撤销")
+
+	ErrorSessionNotExist    = errors.New("登录会话不存在或已注销")
+	ErrorRefreshTokenReused = errors.New("refresh token 被重复使用")
+	ErrorTokenNotExist      = errors.New("token 不存在或已过期")
 )
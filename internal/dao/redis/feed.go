@@ -0,0 +1,242 @@
+package redis
+
+import (
+	"go_community/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+/*
+	关注动态
+	1.推模式：普通用户发帖时写入每个粉丝的时间线 ZSet，读取时直接分页
+	2.拉模式：粉丝数量超过阈值的用户（大V）发帖时不推送，读取时将时间线与关注的大V最近的帖子
+	  使用 ZUNIONSTORE 合并到一个短期缓存的 ZSet 中再分页
+	3.时间线和用户帖子 ZSet 只保留最近的帖子，分数为发帖时间
+*/
+
+const feedMergedExpire = 60 * time.Second // 合并结果的缓存时间
+
+func userPostsKey(userID int64) string {
+	return getRedisKey(KeyUserPostZSetPrefix + strconv.FormatInt(userID, 10))
+}
+
+func timelineKey(userID int64) string {
+	return getRedisKey(KeyFeedTimelinePrefix + strconv.FormatInt(userID, 10))
+}
+
+func feedMergedKey(userID int64) string {
+	return getRedisKey(KeyFeedMergedPrefix + strconv.FormatInt(userID, 10))
+}
+
+// GetMissingUserPosts 返回没有缓存最近帖子的用户id
+func GetMissingUserPosts(userIDs []int64) ([]int64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	pipeline := client.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(userIDs))
+	for _, userID := range userIDs {
+		cmds = append(cmds, pipeline.Exists(userPostsKey(userID)))
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		return nil, err
+	}
+	missing := make([]int64, 0)
+	for idx, cmd := range cmds {
+		if cmd.Val() == 0 {
+			missing = append(missing, userIDs[idx])
+		}
+	}
+	return missing, nil
+}
+
+// SetUserPosts 缓存用户最近发布的帖子（从 mysql 加载）
+func SetUserPosts(userID int64, posts []*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	members := make([]redis.Z, 0, len(posts))
+	for _, post := range posts {
+		members = append(members, redis.Z{
+			Score:  float64(post.CreateTime.Unix()),
+			Member: post.PostID,
+		})
+	}
+	return client.ZAdd(userPostsKey(userID), members...).Err()
+}
+
+// AddUserPost 记录用户发布的帖子，只保留最近的 size 条
+func AddUserPost(userID, postID, createTime, size int64) error {
+	key := userPostsKey(userID)
+	pipeline := client.TxPipeline()
+	pipeline.ZAdd(key, redis.Z{
+		Score:  float64(createTime),
+		Member: postID,
+	})
+	pipeline.ZRemRangeByRank(key, 0, -size-1)
+	_, err := pipeline.Exec()
+	return err
+}
+
+// RemoveUserPost 从用户的帖子 ZSet 中删除帖子（帖子删除或隐藏后调用）
+// 大V的帖子在读取时从这里合并，新的粉丝的时间线也从这里加载，不能只依赖读取时的清理
+func RemoveUserPost(userID, postID int64) error {
+	return client.ZRem(userPostsKey(userID), postID).Err()
+}
+
+// PushToTimelines 将帖子写入粉丝的时间线，只保留最近的 size 条
+func PushToTimelines(userIDs []int64, postID, createTime, size int64) error {
+	pipeline := client.Pipeline()
+	for _, userID := range userIDs {
+		key := timelineKey(userID)
+		pipeline.ZAdd(key, redis.Z{
+			Score:  float64(createTime),
+			Member: postID,
+		})
+		pipeline.ZRemRangeByRank(key, 0, -size-1)
+	}
+	_, err := pipeline.Exec()
+	return err
+}
+
+// MergeIntoTimeline 将用户最近的帖子合并到粉丝的时间线（关注后补齐之前的帖子）
+func MergeIntoTimeline(userID int64, authorIDs []int64, size int64) error {
+	if len(authorIDs) == 0 {
+		return nil
+	}
+	key := timelineKey(userID)
+	keys := make([]string, 0, len(authorIDs)+1)
+	keys = append(keys, key)
+	for _, authorID := range authorIDs {
+		keys = append(keys, userPostsKey(authorID))
+	}
+	pipeline := client.TxPipeline()
+	pipeline.ZUnionStore(key, redis.ZStore{Aggregate: "MAX"}, keys...)
+	pipeline.ZRemRangeByRank(key, 0, -size-1)
+	pipeline.Del(feedMergedKey(userID))
+	_, err := pipeline.Exec()
+	return err
+}
+
+// RemoveFromTimeline 从粉丝的时间线中移除用户的帖子（取消关注）
+func RemoveFromTimeline(userID, authorID int64) error {
+	ids, err := client.ZRange(userPostsKey(authorID), 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	pipeline := client.TxPipeline()
+	if len(ids) > 0 {
+		members := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			members = append(members, id)
+		}
+		pipeline.ZRem(timelineKey(userID), members...)
+	}
+	pipeline.Del(feedMergedKey(userID))
+	_, err = pipeline.Exec()
+	return err
+}
+
+// RemoveFeedPosts 从时间线及合并缓存中移除已删除的帖子
+func RemoveFeedPosts(userID int64, ids []string) error {
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, id)
+	}
+	pipeline := client.Pipeline()
+	pipeline.ZRem(timelineKey(userID), members...)
+	pipeline.ZRem(feedMergedKey(userID), members...)
+	_, err := pipeline.Exec()
+	return err
+}
+
+// TimelineExists 判断用户的时间线是否存在
+func TimelineExists(userID int64) (bool, error) {
+	n, err := client.Exists(timelineKey(userID)).Result()
+	return n > 0, err
+}
+
+// AddBigAccount 将用户标记为大V，之后发帖不再推送到粉丝的时间线
+func AddBigAccount(userID int64) error {
+	return client.SAdd(getRedisKey(KeyFeedBigAccountSet), userID).Err()
+}
+
+// SetBigAccounts 重建大V集合
+func SetBigAccounts(userIDs []int64) error {
+	key := getRedisKey(KeyFeedBigAccountSet)
+	pipeline := client.TxPipeline()
+	pipeline.Del(key)
+	if len(userIDs) > 0 {
+		members := make([]interface{}, 0, len(userIDs))
+		for _, userID := range userIDs {
+			members = append(members, userID)
+		}
+		pipeline.SAdd(key, members...)
+	}
+	_, err := pipeline.Exec()
+	return err
+}
+
+// IsBigAccount 判断用户是否为大V
+func IsBigAccount(userID int64) (bool, error) {
+	return client.SIsMember(getRedisKey(KeyFeedBigAccountSet), userID).Result()
+}
+
+// FilterBigAccounts 返回 userIDs 中的大V
+func FilterBigAccounts(userIDs []int64) ([]int64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	key := getRedisKey(KeyFeedBigAccountSet)
+	pipeline := client.Pipeline()
+	cmds := make([]*redis.BoolCmd, 0, len(userIDs))
+	for _, userID := range userIDs {
+		cmds = append(cmds, pipeline.SIsMember(key, userID))
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		return nil, err
+	}
+	big := make([]int64, 0)
+	for idx, cmd := range cmds {
+		if cmd.Val() {
+			big = append(big, userIDs[idx])
+		}
+	}
+	return big, nil
+}
+
+// GetFeedPostIds 分页查询关注动态的帖子id（按发帖时间从新到旧），返回帖子总数
+// bigAccountIDs 为关注的大V，需要与时间线合并
+func GetFeedPostIds(userID int64, bigAccountIDs []int64, page, size int64) (ids []string, total int64, err error) {
+	key := timelineKey(userID)
+	if len(bigAccountIDs) > 0 {
+		// 利用缓存 key 减少 zunionstore 执行的次数
+		key = feedMergedKey(userID)
+		if client.Exists(key).Val() < 1 {
+			keys := make([]string, 0, len(bigAccountIDs)+1)
+			keys = append(keys, timelineKey(userID))
+			for _, authorID := range bigAccountIDs {
+				keys = append(keys, userPostsKey(authorID))
+			}
+			pipeline := client.Pipeline()
+			pipeline.ZUnionStore(key, redis.ZStore{Aggregate: "MAX"}, keys...)
+			pipeline.Expire(key, feedMergedExpire)
+			if _, err := pipeline.Exec(); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	total, err = client.ZCard(key).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	ids, err = getIdsFormKey(key, page, size)
+	return ids, total, err
+}
+
+// DeleteFeedCache 删除用户关注动态的合并缓存（关注大V后立即生效）
+func DeleteFeedCache(userID int64) error {
+	return client.Del(feedMergedKey(userID)).Err()
+}
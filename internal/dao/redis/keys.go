@@ -5,12 +5,44 @@ package redis
 // redis key 注意使用命名空间的方式，方便查询和拆分
 const (
 	KeyPrefix                 = "go_community:"
-	KeyPostTimeZSet           = "post:time"      // 帖子及发帖时间
-	KeyPostScoreZSet          = "post:score"     // 帖子及投票分数
-	KeyPostVotedZSetPrefix    = "post:voted:"    // 记录用户及投票类型
-	KeyCommunityPostSetPrefix = "community:"     // 保存每个分区下帖子的id
-	KeyCommentTimeZSet        = "comment:time"   // 评论及发布时间
-	KeyCommentVotedZSetPrefix = "comment:voted:" // 记录用户为评论投票的数据
+	KeyPostTimeZSet           = "post:time"              // 帖子及发帖时间
+	KeyPostScoreZSet          = "post:score"             // 帖子及投票分数
+	KeyPostHotZSet            = "post:hot"               // 帖子及 Reddit hot 分数
+	KeyPostGravityZSet        = "post:gravity"           // 帖子及 Hacker News 重力衰减分数
+	KeyPostBestZSet           = "post:best"              // 帖子及 Wilson score 分数
+	KeyPostControversialZSet  = "post:controversial"     // 帖子及争议度分数
+	KeyPostVotedZSetPrefix    = "post:voted:"            // 记录用户及投票类型
+	KeyCommunityPostSetPrefix = "community:"             // 保存每个分区下帖子的id
+	KeyCommentTimeZSet        = "comment:time"           // 评论及发布时间
+	KeyCommentScoreZSet       = "comment:score"          // 评论及净票数
+	KeyCommentTopZSetPrefix   = "comment:top:post:"      // 每个帖子下一级评论的净票数
+	KeyCommentBestZSetPrefix  = "comment:best:post:"     // 每个帖子下一级评论的 Wilson score 分数
+	KeyCommentVotedZSetPrefix = "comment:voted:"         // 记录用户为评论投票的数据
+	KeyPostArchiveCursor      = "post:archive:cursor"    // 帖子投票数据归档进度（已归档的最大发帖时间）
+	KeyCommentArchiveCursor   = "comment:archive:cursor" // 评论投票数据归档进度（已归档的最大发布时间）
+	KeyTaskLockPrefix         = "lock:task:"             // 后台定时任务的锁，多个实例中同一时间只有一个执行
+	KeySessionPrefix          = "session:"               // 登录会话：用户id及当前有效的 refresh token id
+	KeyUserSessionSetPrefix   = "user:sessions:"         // 每个用户的登录会话id
+	KeyUserTokenVersionPrefix = "user:token_version:"    // 用户 token 版本的缓存（以 mysql 为准）
+	KeyEmailVerifyPrefix      = "email:verify:"          // 邮箱验证 token（sha256）：用户id及邮箱
+	KeyPasswordResetPrefix    = "password:reset:"        // 重置密码 token（sha256）：用户id及 token 版本
+	KeyLoginFailPrefix        = "login:fail:"            // 登录失败次数（user:<用户名> 或 ip:<IP>）
+	KeyLoginLockPrefix        = "login:lock:"            // 登录锁定，过期时间即剩余的锁定时长
+	KeyRateLimitPrefix        = "ratelimit:"             // 限流：<策略>:user:<用户id> 或 <策略>:ip:<IP>，保存理论到达时间
+	KeyGuardCooldownPrefix    = "guard:cooldown:"        // 发布冷却：<post|comment>:<用户id>，过期时间即剩余的冷却时长
+	KeyGuardDailyPrefix       = "guard:daily:"           // 发布数量：<post|comment>:<用户id>，统计窗口内的发布次数
+	KeyGuardSimHashZSetPrefix = "guard:simhash:"         // 最近发布内容的指纹：<post|comment>:<用户id>，分数为发布时间
+	KeyNotifyUnreadPrefix     = "notify:unread:"         // 用户未读通知数量的缓存（以 mysql 为准）
+	KeyVoteMilestonePrefix    = "notify:milestone:"      // 已通知过的点赞里程碑：<post|comment>:<id>
+	KeyPushUserPrefix         = "push:user:"             // 推送频道（pub/sub）：用户的个人通知
+	KeyPushPostPrefix         = "push:post:"             // 推送频道（pub/sub）：帖子的新评论
+	KeyPushTicketPrefix       = "push:ticket:"           // 推送连接的一次性票据（sha256）：用户id、角色、会话id及 token 版本
+	KeySearchIndexChannel     = "search:index"           // 频道（pub/sub）：搜索索引的更新，各实例收到后同步本地索引
+	KeyUserPostZSetPrefix     = "user:posts:"            // 用户最近发布的帖子及发帖时间
+	KeyFeedTimelinePrefix     = "feed:timeline:"         // 用户的关注动态时间线（推模式写入）：帖子及发帖时间
+	KeyFeedMergedPrefix       = "feed:merged:"           // 时间线与大V帖子合并后的缓存（拉模式）
+	KeyFeedBigAccountSet      = "feed:big_accounts"      // 粉丝数量超过阈值的用户，发帖时不推送到粉丝的时间线
+	KeyCommunityFeedPrefix    = "feed:community:"        // 加入的社区合并后的帖子缓存：<用户id>:<排序 ZSet>
 )
 
 // getRedisKey redis key 拼接前缀
@@ -0,0 +1,25 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// releaseTaskLockScript 锁的值仍为 owner 时才删除，避免删除过期后被其他实例获取的锁
+var releaseTaskLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// AcquireTaskLock 尝试获取后台任务的锁（SET NX PX），已被其他实例持有时返回 false
+func AcquireTaskLock(task, owner string, expire time.Duration) (bool, error) {
+	return client.SetNX(getRedisKey(KeyTaskLockPrefix+task), owner, expire).Result()
+}
+
+// ReleaseTaskLock 释放后台任务的锁
+func ReleaseTaskLock(task, owner string) error {
+	return releaseTaskLockScript.Run(client, []string{getRedisKey(KeyTaskLockPrefix + task)}, owner).Err()
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// recordLoginFailureScript 记录一次登录失败，失败次数达到阈值后按 ARGV[3:] 中的时长依次锁定
+// KEYS[1] 失败次数，KEYS[2] 锁定
+// ARGV[1] 统计窗口（毫秒），ARGV[2] 阈值，ARGV[3:] 每次锁定的时长（毫秒），超出后使用最后一个
+// 锁定期间失败次数不会过期，解锁后再次失败时继续翻倍，而不是从首次锁定时长重新开始
+// 返回本次锁定的时长（毫秒），未锁定时返回 0
+var recordLoginFailureScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+local window = tonumber(ARGV[1])
+local threshold = tonumber(ARGV[2])
+if count < threshold then
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+local lockout = tonumber(ARGV[math.min(count - threshold + 3, #ARGV)])
+redis.call('PEXPIRE', KEYS[1], lockout + window)
+redis.call('SET', KEYS[2], count, 'PX', lockout)
+return lockout
+`)
+
+// loginLockouts 达到阈值后每次失败的锁定时长：从 baseLockout 开始翻倍，最后一个为 maxLockout
+func loginLockouts(baseLockout, maxLockout time.Duration) []time.Duration {
+	lockouts := make([]time.Duration, 0, 8)
+	for lockout := baseLockout; lockout > 0 && lockout < maxLockout; lockout *= 2 {
+		lockouts = append(lockouts, lockout)
+	}
+	return append(lockouts, maxLockout)
+}
+
+// GetLoginLockout 查询剩余的锁定时长，多个 id 取最大值，未锁定时返回 0
+// id 格式为 user:<用户名> 或 ip:<IP>
+func GetLoginLockout(ids ...string) (time.Duration, error) {
+	pipeline := client.Pipeline()
+	cmds := make([]*redis.DurationCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipeline.PTTL(getRedisKey(KeyLoginLockPrefix+id)))
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		return 0, err
+	}
+	var lockout time.Duration
+	for _, cmd := range cmds {
+		// key 不存在时 PTTL 返回负数
+		if ttl := cmd.Val(); ttl > lockout {
+			lockout = ttl
+		}
+	}
+	return lockout, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，返回触发的锁定时长（未锁定时为 0）
+func RecordLoginFailure(id string, threshold int64, window, baseLockout, maxLockout time.Duration) (time.Duration, error) {
+	keys := []string{getRedisKey(KeyLoginFailPrefix + id), getRedisKey(KeyLoginLockPrefix + id)}
+	args := []interface{}{int64(window / time.Millisecond), threshold}
+	for _, lockout := range loginLockouts(baseLockout, maxLockout) {
+		args = append(args, int64(lockout/time.Millisecond))
+	}
+	ms, err := recordLoginFailureScript.Run(client, keys, args...).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// ResetLoginFailures 清除失败次数和锁定
+func ResetLoginFailures(ids ...string) error {
+	keys := make([]string, 0, len(ids)*2)
+	for _, id := range ids {
+		keys = append(keys, getRedisKey(KeyLoginFailPrefix+id), getRedisKey(KeyLoginLockPrefix+id))
+	}
+	return client.Del(keys...).Err()
+}
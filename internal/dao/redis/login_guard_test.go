@@ -0,0 +1,21 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLockouts(t *testing.T) {
+	// 配置文件中的默认值：每次失败翻倍，最终达到上限
+	assert.Equal(t, []time.Duration{
+		30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute,
+		8 * time.Minute, 16 * time.Minute, 32 * time.Minute, time.Hour,
+	}, loginLockouts(30*time.Second, time.Hour))
+
+	// 首次锁定时长不小于上限时只使用上限
+	assert.Equal(t, []time.Duration{time.Minute}, loginLockouts(time.Minute, time.Minute))
+	assert.Equal(t, []time.Duration{time.Minute}, loginLockouts(time.Hour, time.Minute))
+	assert.Equal(t, []time.Duration{time.Minute}, loginLockouts(0, time.Minute))
+}
@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const unreadCountExpire = 7 * 24 * time.Hour // 未读通知数量缓存的过期时间
+
+// incrUnreadScript 缓存存在时调整未读数量，不小于 0；缓存不存在时不处理，下次查询时从 mysql 加载
+// KEYS[1] 未读数量，ARGV[1] 增量
+var incrUnreadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local count = redis.call('INCRBY', KEYS[1], ARGV[1])
+if count < 0 then
+	local ttl = redis.call('PTTL', KEYS[1])
+	redis.call('SET', KEYS[1], 0)
+	if ttl > 0 then
+		redis.call('PEXPIRE', KEYS[1], ttl)
+	end
+	return 0
+end
+return count
+`)
+
+func unreadCountKey(userID int64) string {
+	return getRedisKey(KeyNotifyUnreadPrefix + strconv.FormatInt(userID, 10))
+}
+
+// GetUnreadCount 查询缓存的未读通知数量，ok 为 false 表示没有缓存
+func GetUnreadCount(userID int64) (count int64, ok bool, err error) {
+	count, err = client.Get(unreadCountKey(userID)).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+// SetUnreadCount 缓存未读通知数量
+func SetUnreadCount(userID, count int64) error {
+	return client.Set(unreadCountKey(userID), count, unreadCountExpire).Err()
+}
+
+// IncrUnreadCount 调整缓存的未读通知数量（delta 可以为负数），没有缓存时不处理
+func IncrUnreadCount(userID, delta int64) error {
+	return incrUnreadScript.Run(client, []string{unreadCountKey(userID)}, delta).Err()
+}
+
+// AddVoteMilestone 记录帖子/评论达到的点赞里程碑，返回 false 表示之前已经记录过
+// kind 为 post 或 comment
+func AddVoteMilestone(kind string, targetID, milestone int64) (bool, error) {
+	key := getRedisKey(KeyVoteMilestonePrefix + kind + ":" + strconv.FormatInt(targetID, 10))
+	pipeline := client.TxPipeline()
+	added := pipeline.SAdd(key, milestone)
+	// 投票窗口关闭后不再变化
+	pipeline.Expire(key, 2*OneWeekInSeconds*time.Second)
+	if _, err := pipeline.Exec(); err != nil {
+		return false, err
+	}
+	return added.Val() > 0, nil
+}
@@ -16,14 +16,11 @@ func getIdsFormKey(key string, page, size int64) ([]string, error) {
 	return client.ZRevRange(key, start, end).Result()
 }
 
-// GetPostIdsInOrder 获取帖子列表：按创建时间/分数排序（查询出 ids，根据 order 从大到小排序）
+// GetPostIdsInOrder 获取帖子列表：按创建时间/分数/排序算法排序（查询出 ids，根据 order 从大到小排序）
 func GetPostIdsInOrder(p *models.ParamPostList) ([]string, error) {
 	// 从 redis 获取 id
-	// 1.根据请求中携带的 order 参数，确定要查询的 redis key
-	key := getRedisKey(KeyPostTimeZSet) // 默认是时间
-	if p.Order == models.OrderScore {   // 按照分数请求
-		key = getRedisKey(KeyPostScoreZSet)
-	}
+	// 1.根据请求中携带的 order 参数，确定要查询的 redis key（默认是时间）
+	key := getPostOrderKey(p.Order)
 	// 2.确定查询的索引起始点
 	return getIdsFormKey(key, p.Page, p.Size)
 }
@@ -65,14 +62,16 @@ func GetPostVoteData(ids []string) (data []int64, err error) {
 	return
 }
 
+// GetPostVoteCounts 根据ids批量查询帖子的赞成票数、反对票数及当前用户的投票方向
+func GetPostVoteCounts(ids []string, userId string) ([]*models.VoteCount, error) {
+	return getVoteData(KeyPostVotedZSetPrefix, ids, userId)
+}
+
 // GetCommunityPostIdsInOrder 按社区查询ids(查询出的ids根据order从大到小排序)
 func GetCommunityPostIdsInOrder(p *models.ParamPostList) ([]string, error) {
 	// 从 redis 获取 id
-	// 1.根据请求中携带的 order 参数，确定要查询的 redis key
-	orderKey := getRedisKey(KeyPostTimeZSet) // 默认是时间
-	if p.Order == models.OrderScore {        // 按照分数请求
-		orderKey = getRedisKey(KeyPostScoreZSet)
-	}
+	// 1.根据请求中携带的 order 参数，确定要查询的 redis key（默认是时间）
+	orderKey := getPostOrderKey(p.Order)
 	// 使用 zinterstore: 把分区的帖子 set 与帖子分数 zset 生成一个新的 zset
 	// 针对新的 zset，按之前的逻辑取数据
 	// 利用缓存 key 减少 zinterstore 执行的次数
@@ -103,11 +102,18 @@ func DeletePostData(postID string, commentIDs []string) error {
 	// 1. 删除帖子相关数据
 	pipeline.ZRem(getRedisKey(KeyPostTimeZSet), postID)
 	pipeline.ZRem(getRedisKey(KeyPostScoreZSet), postID)
+	for _, key := range rankKeys {
+		pipeline.ZRem(getRedisKey(key), postID)
+	}
 	pipeline.Del(getRedisKey(KeyPostVotedZSetPrefix + postID))
 
-	// 2. 删除该帖子下所有评论的点赞数据
+	// 2. 删除该帖子的评论排序数据
+	pipeline.Del(getRedisKey(KeyCommentTopZSetPrefix+postID), getRedisKey(KeyCommentBestZSetPrefix+postID))
+
+	// 3. 删除该帖子下所有评论的点赞数据
 	for _, commentID := range commentIDs {
 		pipeline.Del(getRedisKey(KeyCommentVotedZSetPrefix + commentID))
+		pipeline.ZRem(getRedisKey(KeyCommentScoreZSet), commentID)
 	}
 
 	_, err := pipeline.Exec()
@@ -126,6 +132,11 @@ func RemoveInvalidPostIds(ids []string) error {
 	scoreKey := getRedisKey(KeyPostScoreZSet)
 	pipeline.ZRem(scoreKey, ids)
 
+	// 从其他排序集合中删除
+	for _, key := range rankKeys {
+		pipeline.ZRem(getRedisKey(key), ids)
+	}
+
 	_, err := pipeline.Exec()
 	return err
 }
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// UserPushChannel 用户个人通知的推送频道
+func UserPushChannel(userID int64) string {
+	return getRedisKey(KeyPushUserPrefix + strconv.FormatInt(userID, 10))
+}
+
+// PostPushChannel 帖子新评论的推送频道
+func PostPushChannel(postID int64) string {
+	return getRedisKey(KeyPushPostPrefix + strconv.FormatInt(postID, 10))
+}
+
+// SearchIndexChannel 搜索索引更新的频道
+func SearchIndexChannel() string {
+	return getRedisKey(KeySearchIndexChannel)
+}
+
+// Publish 向推送频道发布消息，所有实例上订阅该频道的连接都会收到
+func Publish(channel string, message []byte) error {
+	return client.Publish(channel, message).Err()
+}
+
+// PushSubscriber 使用一个 redis 连接按需订阅多个推送频道
+type PushSubscriber struct {
+	pubsub *redis.PubSub
+}
+
+// NewPushSubscriber 创建推送频道的订阅者
+func NewPushSubscriber() *PushSubscriber {
+	return &PushSubscriber{pubsub: client.Subscribe()}
+}
+
+// Subscribe 订阅频道
+func (s *PushSubscriber) Subscribe(channel string) error {
+	return s.pubsub.Subscribe(channel)
+}
+
+// Unsubscribe 取消订阅频道
+func (s *PushSubscriber) Unsubscribe(channel string) error {
+	return s.pubsub.Unsubscribe(channel)
+}
+
+// Receive 接收已订阅频道的消息，直到订阅者关闭（连接断开时自动重连并重新订阅）
+func (s *PushSubscriber) Receive(handler func(channel string, payload []byte)) {
+	for msg := range s.pubsub.Channel() {
+		handler(msg.Channel, []byte(msg.Payload))
+	}
+}
+
+// Close 关闭订阅者
+func (s *PushSubscriber) Close() error {
+	return s.pubsub.Close()
+}
+
+// SaveStreamTicket 保存推送连接的票据，与邮箱验证 token 相同只保存 sha256
+func SaveStreamTicket(ticketHash string, userID int64, role int8, sessionID string, tokenVersion int64, expiration time.Duration) error {
+	return saveAccountToken(KeyPushTicketPrefix+ticketHash, map[string]interface{}{
+		"user_id":       userID,
+		"role":          role,
+		"session_id":    sessionID,
+		"token_version": tokenVersion,
+	}, expiration)
+}
+
+// ConsumeStreamTicket 使用推送连接的票据，票据只能使用一次
+func ConsumeStreamTicket(ticketHash string) (userID int64, role int8, sessionID string, tokenVersion int64, err error) {
+	fields, err := consumeAccountToken(KeyPushTicketPrefix + ticketHash)
+	if err != nil {
+		return 0, 0, "", 0, err
+	}
+	userID, err = strconv.ParseInt(fields["user_id"], 10, 64)
+	if err != nil {
+		return 0, 0, "", 0, ErrorTokenNotExist
+	}
+	r, err := strconv.ParseInt(fields["role"], 10, 8)
+	if err != nil {
+		return 0, 0, "", 0, ErrorTokenNotExist
+	}
+	tokenVersion, err = strconv.ParseInt(fields["token_version"], 10, 64)
+	if err != nil {
+		return 0, 0, "", 0, ErrorTokenNotExist
+	}
+	return userID, int8(r), fields["session_id"], tokenVersion, nil
+}
@@ -0,0 +1,161 @@
+package redis
+
+import (
+	"go_community/global"
+	"go_community/internal/models"
+	"go_community/pkg/ranking"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+/*
+	帖子排序
+	除了 KeyPostTimeZSet 和 KeyPostScoreZSet，每种排序算法的分数保存在各自的 ZSet 中：
+		1.hot、best、controversial 只与票数（及发帖时间）有关，在发帖和投票时更新
+		2.gravity 分数随时间衰减，由 service.StartRankingRefresher 定时重新计算
+	算法的参数见配置文件中的 ranking 部分
+*/
+
+// 排序算法参数的默认值，配置文件中未设置时使用
+const (
+	defaultVoteScore  float64 = 432        // 每一票的值432分
+	defaultHotEpoch   int64   = 1134028003 // Reddit hot 算法的时间起点
+	defaultHotDivisor float64 = 45000      // Reddit hot 算法的时间权重
+	defaultGravity    float64 = 1.8        // Hacker News 算法的重力因子
+	defaultWilsonZ    float64 = 1.96       // 95% 置信度
+)
+
+// orderKeys 排序方式对应的 ZSet
+var orderKeys = map[string]string{
+	models.OrderTime:          KeyPostTimeZSet,
+	models.OrderScore:         KeyPostScoreZSet,
+	models.OrderHot:           KeyPostHotZSet,
+	models.OrderGravity:       KeyPostGravityZSet,
+	models.OrderBest:          KeyPostBestZSet,
+	models.OrderControversial: KeyPostControversialZSet,
+}
+
+// rankKeys 由票数计算分数的排序 ZSet（不包括时间和简化版投票分数）
+var rankKeys = []string{
+	KeyPostHotZSet,
+	KeyPostGravityZSet,
+	KeyPostBestZSet,
+	KeyPostControversialZSet,
+}
+
+// getPostOrderKey 根据 order 参数确定要查询的 redis key，默认按时间排序
+func getPostOrderKey(order string) string {
+	key, ok := orderKeys[order]
+	if !ok {
+		key = KeyPostTimeZSet
+	}
+	return getRedisKey(key)
+}
+
+// rankingConfig 读取排序算法配置，未设置的参数使用默认值
+func rankingConfig() global.RankingConfig {
+	cfg := global.Conf.Ranking
+	if cfg.VoteScore <= 0 {
+		cfg.VoteScore = defaultVoteScore
+	}
+	if cfg.HotEpoch <= 0 {
+		cfg.HotEpoch = defaultHotEpoch
+	}
+	if cfg.HotDivisor <= 0 {
+		cfg.HotDivisor = defaultHotDivisor
+	}
+	if cfg.Gravity <= 0 {
+		cfg.Gravity = defaultGravity
+	}
+	if cfg.WilsonZ <= 0 {
+		cfg.WilsonZ = defaultWilsonZ
+	}
+	return cfg
+}
+
+// voteScore 简化版投票分数中每一票的分值
+func voteScore() float64 {
+	return rankingConfig().VoteScore
+}
+
+// postRankScores 根据赞成票数、反对票数和发帖时间计算各排序 ZSet 中的分数
+func postRankScores(up, down, createTime, now int64) map[string]float64 {
+	cfg := rankingConfig()
+	ageHours := float64(now-createTime) / 3600
+	return map[string]float64{
+		KeyPostHotZSet:           ranking.Hot(up, down, createTime, cfg.HotEpoch, cfg.HotDivisor),
+		KeyPostGravityZSet:       ranking.Gravity(up, down, ageHours, cfg.Gravity),
+		KeyPostBestZSet:          ranking.Wilson(up, down, cfg.WilsonZ),
+		KeyPostControversialZSet: ranking.Controversy(up, down),
+	}
+}
+
+// addPostRankScores 将帖子的各排序分数写入 pipeline
+func addPostRankScores(pipeline redis.Pipeliner, postId string, up, down, createTime, now int64) {
+	for key, score := range postRankScores(up, down, createTime, now) {
+		pipeline.ZAdd(getRedisKey(key), redis.Z{
+			Score:  score,
+			Member: postId,
+		})
+	}
+}
+
+// updatePostRanks 根据 redis 中的投票记录重新计算单个帖子的各排序分数
+func updatePostRanks(postId string) error {
+	createTime := int64(client.ZScore(getRedisKey(KeyPostTimeZSet), postId).Val())
+	up, down, err := getVoteCounts(KeyPostVotedZSetPrefix, []string{postId})
+	if err != nil {
+		return err
+	}
+	pipeline := client.Pipeline()
+	addPostRankScores(pipeline, postId, up[0], down[0], createTime, time.Now().Unix())
+	_, err = pipeline.Exec()
+	return err
+}
+
+// RefreshGravityScores 重新计算投票窗口内帖子的重力衰减分数，返回更新的帖子数量
+// 投票窗口关闭后帖子的分数在归档时置 0，不再参与刷新
+func RefreshGravityScores(batchSize int64) (count int, err error) {
+	now := time.Now().Unix()
+	min := strconv.FormatInt(now-OneWeekInSeconds, 10)
+	var offset int64
+	cfg := rankingConfig()
+	for {
+		items, err := client.ZRangeByScoreWithScores(getRedisKey(KeyPostTimeZSet), redis.ZRangeBy{
+			Min:    min,
+			Max:    "+inf",
+			Offset: offset,
+			Count:  batchSize,
+		}).Result()
+		if err != nil {
+			return count, err
+		}
+		if len(items) == 0 {
+			return count, nil
+		}
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.Member.(string))
+		}
+		up, down, err := getVoteCounts(KeyPostVotedZSetPrefix, ids)
+		if err != nil {
+			return count, err
+		}
+
+		pipeline := client.Pipeline()
+		for idx, item := range items {
+			ageHours := float64(now-int64(item.Score)) / 3600
+			pipeline.ZAdd(getRedisKey(KeyPostGravityZSet), redis.Z{
+				Score:  ranking.Gravity(up[idx], down[idx], ageHours, cfg.Gravity),
+				Member: ids[idx],
+			})
+		}
+		if _, err := pipeline.Exec(); err != nil {
+			return count, err
+		}
+		count += len(items)
+		offset += int64(len(items))
+	}
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+/*
+	限流：GCRA（通用信元速率算法）
+	1.每个 key 只保存一个理论到达时间（TAT），每次请求 TAT 增加 period/limit
+	2.TAT 超前当前时间不超过 period 时放行，因此允许最多 limit 次的突发请求
+	3.使用 redis 服务器的时间，多个实例之间不受本地时钟影响
+*/
+
+// rateLimitScript GCRA 限流
+// KEYS[1] 理论到达时间（毫秒）
+// ARGV[1] 每次请求的间隔（毫秒，period/limit），ARGV[2] limit
+// 返回 {是否放行, 剩余次数, 需要等待的时长（毫秒）, 完全恢复的时长（毫秒）}
+var rateLimitScript = redis.NewScript(`
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local interval = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local newTat = tat + interval
+local allowAt = newTat - limit * interval
+if now < allowAt then
+	return {0, 0, math.ceil(allowAt - now), math.ceil(tat - now)}
+end
+redis.call('SET', KEYS[1], newTat, 'PX', math.ceil(newTat - now))
+return {1, math.floor((now - allowAt) / interval), 0, math.ceil(newTat - now)}
+`)
+
+// RateLimitResult 限流结果
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64         // 剩余的请求次数
+	RetryAfter time.Duration // 被限流时需要等待的时长
+	ResetAfter time.Duration // 请求次数完全恢复的时长
+}
+
+// AllowRequest 判断 key 在 period 内的请求是否超过 limit 次
+func AllowRequest(key string, limit int64, period time.Duration) (*RateLimitResult, error) {
+	interval := float64(period/time.Microsecond) / 1000 / float64(limit)
+	values, err := rateLimitScript.Run(client, []string{getRedisKey(KeyRateLimitPrefix + key)},
+		interval, limit).Result()
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := values.([]interface{})
+	if !ok || len(fields) != 4 {
+		return nil, redis.Nil
+	}
+	ints := make([]int64, len(fields))
+	for i, field := range fields {
+		ints[i], _ = field.(int64)
+	}
+	return &RateLimitResult{
+		Allowed:    ints[0] == 1,
+		Limit:      limit,
+		Remaining:  ints[1],
+		RetryAfter: time.Duration(ints[2]) * time.Millisecond,
+		ResetAfter: time.Duration(ints[3]) * time.Millisecond,
+	}, nil
+}
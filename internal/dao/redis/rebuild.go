@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"go_community/internal/models"
+	"go_community/pkg/ranking"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RestorePosts 根据 mysql 中的帖子数据重建帖子的时间/分数/排序 ZSet 及社区 Set
+// archives 为已归档帖子的投票数据；未归档的帖子按 redis 中现存的投票记录重新计算分数
+func RestorePosts(posts []*models.Post, archives map[int64]*models.VoteArchive) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, strconv.FormatInt(post.PostID, 10))
+	}
+	up, down, err := getVoteCounts(KeyPostVotedZSetPrefix, ids)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	pipeline := client.TxPipeline()
+	for idx, post := range posts {
+		createTime := float64(post.CreateTime.Unix())
+		var score float64
+		if archive, ok := archives[post.PostID]; ok {
+			score = archive.Score
+			up[idx], down[idx] = archive.UpVotes, archive.DownVotes
+		} else {
+			// 与 CreatePost、VoteForPost 的计分方式保持一致
+			score = createTime + voteScore()*float64(1+up[idx]-down[idx])
+		}
+		pipeline.ZAdd(getRedisKey(KeyPostTimeZSet), redis.Z{
+			Score:  createTime,
+			Member: ids[idx],
+		})
+		pipeline.ZAdd(getRedisKey(KeyPostScoreZSet), redis.Z{
+			Score:  score,
+			Member: ids[idx],
+		})
+		addPostRankScores(pipeline, ids[idx], up[idx], down[idx], int64(createTime), now)
+		if now-int64(createTime) > OneWeekInSeconds {
+			// 投票窗口已关闭的帖子不再参与重力衰减排序，与归档时的处理保持一致
+			pipeline.ZAdd(getRedisKey(KeyPostGravityZSet), redis.Z{Score: 0, Member: ids[idx]})
+		}
+		communityKey := getRedisKey(KeyCommunityPostSetPrefix) + strconv.Itoa(int(post.CommunityID))
+		pipeline.SAdd(communityKey, ids[idx])
+	}
+	_, err = pipeline.Exec()
+	return err
+}
+
+// RestoreComments 根据 mysql 中的评论数据重建评论的时间/净票数 ZSet 及帖子评论排序 ZSet
+// archives 为已归档评论的投票数据；未归档的评论按 redis 中现存的投票记录计算净票数
+func RestoreComments(comments []*models.Comment, archives map[int64]*models.VoteArchive) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, strconv.FormatInt(comment.CommentID, 10))
+	}
+	up, down, err := getVoteCounts(KeyCommentVotedZSetPrefix, ids)
+	if err != nil {
+		return err
+	}
+
+	pipeline := client.TxPipeline()
+	for idx, comment := range comments {
+		if archive, ok := archives[comment.CommentID]; ok {
+			up[idx], down[idx] = archive.UpVotes, archive.DownVotes
+		}
+		score := float64(up[idx] - down[idx])
+		pipeline.ZAdd(getRedisKey(KeyCommentTimeZSet), redis.Z{
+			Score:  float64(comment.CreateTime.Unix()),
+			Member: ids[idx],
+		})
+		pipeline.ZAdd(getRedisKey(KeyCommentScoreZSet), redis.Z{
+			Score:  score,
+			Member: ids[idx],
+		})
+		// 一级评论加入所属帖子的评论排序 ZSet
+		if comment.ParentID == 0 {
+			postId := strconv.FormatInt(comment.PostID, 10)
+			pipeline.ZAdd(getRedisKey(KeyCommentTopZSetPrefix+postId), redis.Z{
+				Score:  score,
+				Member: ids[idx],
+			})
+			pipeline.ZAdd(getRedisKey(KeyCommentBestZSetPrefix+postId), redis.Z{
+				Score:  ranking.Wilson(up[idx], down[idx], rankingConfig().WilsonZ),
+				Member: ids[idx],
+			})
+		}
+	}
+	_, err = pipeline.Exec()
+	return err
+}
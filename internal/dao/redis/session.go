@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+/*
+	登录会话
+	1.每次登录创建一个会话，保存用户id和当前有效的 refresh token id（jti），过期时间与 refresh token 相同
+	2.刷新 token 时轮换 jti，旧的 refresh token 立即失效
+	3.已轮换的 refresh token 被再次使用时，认为 token 已泄露，注销整个会话
+	4.注销（或会话过期）后，该会话签发的 access token 也会被认证中间件拒绝
+	5.用户的 token 版本保存在 mysql 中，修改密码后递增；redis 缓存当前版本供认证中间件比较
+*/
+
+const (
+	sessionFieldUserID    = "user_id"
+	sessionFieldRefreshID = "refresh_id"
+
+	tokenVersionExpiration = 24 * time.Hour // token 版本缓存的过期时间
+)
+
+// rotateSessionScript 比较并轮换会话的 refresh token id
+// KEYS[1] 会话，KEYS[2] 用户的会话集合
+// ARGV[1] 旧的 refresh token id，ARGV[2] 新的 refresh token id，ARGV[3] 会话 id，ARGV[4] 过期时间（毫秒）
+// 返回 1 轮换成功，0 会话不存在，-1 旧 token 被重复使用（会话已注销）
+var rotateSessionScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'refresh_id')
+if not current then
+	return 0
+end
+if current ~= ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	redis.call('SREM', KEYS[2], ARGV[3])
+	return -1
+end
+redis.call('HSET', KEYS[1], 'refresh_id', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+return 1
+`)
+
+// deleteUserSessionsScript 删除用户的所有会话（与登录创建会话互斥，避免遗漏）
+// KEYS[1] 用户的会话集合，ARGV[1] 会话 key 的前缀
+var deleteUserSessionsScript = redis.NewScript(`
+local ids = redis.call('SMEMBERS', KEYS[1])
+for _, id in ipairs(ids) do
+	redis.call('DEL', ARGV[1] .. id)
+end
+redis.call('DEL', KEYS[1])
+return #ids
+`)
+
+// setTokenVersionScript 缓存的 token 版本只增不减
+// 避免并发时从 mysql 读到的旧版本覆盖修改密码后写入的新版本
+// KEYS[1] token 版本，ARGV[1] 版本，ARGV[2] 过期时间（毫秒）
+var setTokenVersionScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '-1')
+if tonumber(ARGV[1]) > current then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+end
+return 1
+`)
+
+// getSessionKey 会话的 redis key
+func getSessionKey(sessionID string) string {
+	return getRedisKey(KeySessionPrefix + sessionID)
+}
+
+// getUserSessionSetKey 用户会话集合的 redis key
+func getUserSessionSetKey(userID int64) string {
+	return getRedisKey(KeyUserSessionSetPrefix + strconv.FormatInt(userID, 10))
+}
+
+// CreateSession 创建登录会话
+func CreateSession(userID int64, sessionID, refreshID string, expiration time.Duration) error {
+	sessionKey := getSessionKey(sessionID)
+	setKey := getUserSessionSetKey(userID)
+	pipeline := client.TxPipeline()
+	pipeline.HMSet(sessionKey, map[string]interface{}{
+		sessionFieldUserID:    userID,
+		sessionFieldRefreshID: refreshID,
+	})
+	pipeline.Expire(sessionKey, expiration)
+	pipeline.SAdd(setKey, sessionID)
+	pipeline.Expire(setKey, expiration)
+	_, err := pipeline.Exec()
+	return err
+}
+
+// RotateSession 轮换会话的 refresh token id
+// 会话不存在返回 ErrorSessionNotExist，oldRefreshID 已被轮换过返回 ErrorRefreshTokenReused 并注销会话
+func RotateSession(userID int64, sessionID, oldRefreshID, newRefreshID string, expiration time.Duration) error {
+	keys := []string{getSessionKey(sessionID), getUserSessionSetKey(userID)}
+	result, err := rotateSessionScript.Run(client, keys,
+		oldRefreshID, newRefreshID, sessionID, int64(expiration/time.Millisecond)).Int64()
+	if err != nil {
+		return err
+	}
+	switch result {
+	case 0:
+		return ErrorSessionNotExist
+	case -1:
+		return ErrorRefreshTokenReused
+	}
+	return nil
+}
+
+// CheckSession 检查会话是否有效（未注销且属于该用户）
+func CheckSession(userID int64, sessionID string) error {
+	uid, err := client.HGet(getSessionKey(sessionID), sessionFieldUserID).Result()
+	if err == redis.Nil {
+		return ErrorSessionNotExist
+	}
+	if err != nil {
+		return err
+	}
+	if uid != strconv.FormatInt(userID, 10) {
+		return ErrorSessionNotExist
+	}
+	return nil
+}
+
+// DeleteSession 注销单个会话
+func DeleteSession(userID int64, sessionID string) error {
+	pipeline := client.TxPipeline()
+	pipeline.Del(getSessionKey(sessionID))
+	pipeline.SRem(getUserSessionSetKey(userID), sessionID)
+	_, err := pipeline.Exec()
+	return err
+}
+
+// DeleteUserSessions 注销用户的所有会话，返回注销的会话数量
+func DeleteUserSessions(userID int64) (int64, error) {
+	keys := []string{getUserSessionSetKey(userID)}
+	return deleteUserSessionsScript.Run(client, keys, getSessionKey("")).Int64()
+}
+
+// getTokenVersionKey 用户 token 版本缓存的 redis key
+func getTokenVersionKey(userID int64) string {
+	return getRedisKey(KeyUserTokenVersionPrefix + strconv.FormatInt(userID, 10))
+}
+
+// GetTokenVersion 获取缓存的用户 token 版本，未缓存时 ok 为 false
+func GetTokenVersion(userID int64) (tokenVersion int64, ok bool, err error) {
+	tokenVersion, err = client.Get(getTokenVersionKey(userID)).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return tokenVersion, true, nil
+}
+
+// SetTokenVersion 缓存用户的 token 版本（只会更新为更大的版本）
+func SetTokenVersion(userID int64, tokenVersion int64) error {
+	keys := []string{getTokenVersionKey(userID)}
+	return setTokenVersionScript.Run(client, keys,
+		tokenVersion, int64(tokenVersionExpiration/time.Millisecond)).Err()
+}
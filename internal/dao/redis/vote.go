@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"go_community/internal/models"
 	"math"
 	"strconv"
 	"time"
@@ -9,10 +10,9 @@ import (
 )
 
 const (
-	OneWeekInSeconds          = 7 * 24 * 3600        // 一周的秒数
-	OneMonthInSeconds         = 4 * OneWeekInSeconds // 一个月的秒数
-	VoteScore         float64 = 432                  // 每一票的值432分
-	PostPerAge                = 20                   // 每页显示20条帖子
+	OneWeekInSeconds  = 7 * 24 * 3600        // 一周的秒数
+	OneMonthInSeconds = 4 * OneWeekInSeconds // 一个月的秒数
+	PostPerAge        = 20                   // 每页显示20条帖子
 )
 
 /*
@@ -21,6 +21,7 @@ const (
 
 	本项目使用简化版的投票分数
 	投一票加432分（86400/200=432，200张赞成票就可以给帖子在首页续天）->《redis实战》
+	每一票的分值可通过配置文件中的 ranking.vote_score 调整，其他排序算法见 ranking.go
 */
 
 /*
@@ -33,9 +34,10 @@ const (
 	direction=1 时，有两种情况
 		1.之前没有投过票，现在投赞成票  -> 更新分数和投票记录，差值的绝对值：1, +432
 		2.之前投反对票，现在改为赞成票  -> 更新分数和投票记录，差值的绝对值：2, +432*2
-	direction=0 时，有两种情况
+	direction=0 时，有两种情况（撤销投票接口 DELETE /api/v1/vote 同样走这里）
 		2.之前投过反对票，现在取消投票  -> 更新分数和投票记录，差值的绝对值：1, +432
 		1.之前投过赞成票，现在取消投票  -> 更新分数和投票记录，差值的绝对值：1, -432
+		之前没有投过票时返回 ErrorVoteNotExist
 	direction=-1 时，有两种情况
 		1.之前没有投过票，现在投反对票  -> 更新分数和投票记录，差值的绝对值：1, -432
 		2.之前投赞成票，现在改为反对票  -> 更新分数和投票记录，差值的绝对值：2, -432*2
@@ -44,6 +46,7 @@ const (
 		每个帖子子发表之日起一个星期之内允许用户投票，超过一个星期就不允许投票了
 		1.到期之后将 redis 中保存的赞成票数及反对票数存储到 mysql 表中
 		2.到期之后删除那个 KeyPostVotedZSetPrefix
+	以上归档逻辑见 archive.go，由 service.StartVoteArchiver 定时执行
 */
 
 // VoteForPost	为帖子投票
@@ -57,6 +60,9 @@ func VoteForPost(userId, postId string, v float64) (voteNum int64, err error) {
 	// 2.判断是否已投票
 	key := getRedisKey(KeyPostVotedZSetPrefix + postId)
 	ov := client.ZScore(key, userId).Val()
+	if v == 0 && ov == 0 {
+		return 0, ErrorVoteNotExist
+	}
 	if v == ov {
 		return 0, ErrorVoteRepeted
 	}
@@ -72,7 +78,7 @@ func VoteForPost(userId, postId string, v float64) (voteNum int64, err error) {
 	pipeline := client.TxPipeline()
 
 	// 更新分数
-	pipeline.ZIncrBy(getRedisKey(KeyPostScoreZSet), VoteScore*diffAbs*op, postId)
+	pipeline.ZIncrBy(getRedisKey(KeyPostScoreZSet), voteScore()*diffAbs*op, postId)
 
 	// 记录投票数据
 	if v == 0 {
@@ -89,12 +95,18 @@ func VoteForPost(userId, postId string, v float64) (voteNum int64, err error) {
 		return 0, err
 	}
 
+	// 4.更新其他排序算法的分数
+	if err = updatePostRanks(postId); err != nil {
+		return 0, err
+	}
+
 	// 返回最新点赞数
 	return GetPostVoteNum(postId)
 }
 
 // VoteForComment 为评论投票
-func VoteForComment(userId, commentId string, v float64) (voteNum int64, err error) {
+// postId 为一级评论所属的帖子，用于更新帖子评论排序；回复的 postId 为空
+func VoteForComment(userId, commentId, postId string, v float64) (voteNum int64, err error) {
 	// 1.判断投票限制
 	commentTime := client.ZScore(getRedisKey(KeyCommentTimeZSet), commentId).Val()
 	if float64(time.Now().Unix())-commentTime > OneWeekInSeconds {
@@ -104,12 +116,23 @@ func VoteForComment(userId, commentId string, v float64) (voteNum int64, err err
 	// 2.判断是否已投票
 	key := getRedisKey(KeyCommentVotedZSetPrefix + commentId)
 	ov := client.ZScore(key, userId).Val()
+	if v == 0 && ov == 0 {
+		return 0, ErrorVoteNotExist
+	}
 	if v == ov {
 		return 0, ErrorVoteRepeted
 	}
 
 	// 3.更新投票数据
 	pipeline := client.TxPipeline()
+
+	// 更新净票数
+	pipeline.ZIncrBy(getRedisKey(KeyCommentScoreZSet), v-ov, commentId)
+	if postId != "" {
+		pipeline.ZIncrBy(getRedisKey(KeyCommentTopZSetPrefix+postId), v-ov, commentId)
+	}
+
+	// 记录投票数据
 	if v == 0 {
 		pipeline.ZRem(key, userId)
 	} else {
@@ -124,10 +147,55 @@ func VoteForComment(userId, commentId string, v float64) (voteNum int64, err err
 		return 0, err
 	}
 
+	// 4.更新帖子评论的 Wilson score 分数
+	if postId != "" {
+		if err = updateCommentRanks(postId, commentId); err != nil {
+			return 0, err
+		}
+	}
+
 	// 返回最新点赞数
 	return GetCommentVoteNum(commentId)
 }
 
+// getVoteData 批量查询赞成票数、反对票数，以及指定用户的投票方向（userId 为空时不查询）
+// 投票记录归档后，用户的投票方向不再保留
+func getVoteData(votedPrefix string, ids []string, userId string) ([]*models.VoteCount, error) {
+	// 使用 pipeline 一次发送多条命令，减少 RTT
+	pipeline := client.Pipeline()
+	for _, id := range ids {
+		key := getRedisKey(votedPrefix + id)
+		pipeline.ZCount(key, "1", "1")
+		pipeline.ZCount(key, "-1", "-1")
+		if userId != "" {
+			pipeline.ZScore(key, userId)
+		}
+	}
+	cmders, err := pipeline.Exec()
+	// 用户未投票时 ZScore 返回 redis.Nil
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	step := 2
+	if userId != "" {
+		step = 3
+	}
+	data := make([]*models.VoteCount, 0, len(ids))
+	for i := 0; i < len(cmders); i += step {
+		v := &models.VoteCount{
+			UpVotes:   cmders[i].(*redis.IntCmd).Val(),
+			DownVotes: cmders[i+1].(*redis.IntCmd).Val(),
+		}
+		v.NetVotes = v.UpVotes - v.DownVotes
+		if userId != "" {
+			v.MyVote = int8(cmders[i+2].(*redis.FloatCmd).Val())
+		}
+		data = append(data, v)
+	}
+	return data, nil
+}
+
 // CreatePost redis 存储帖子信息
 func CreatePost(postId, communityId int64) (err error) {
 	now := float64(time.Now().Unix())
@@ -141,9 +209,11 @@ func CreatePost(postId, communityId int64) (err error) {
 	})
 	// 帖子分数 ZSet
 	pipeline.ZAdd(getRedisKey(KeyPostScoreZSet), redis.Z{
-		Score:  now + VoteScore,
+		Score:  now + voteScore(),
 		Member: postId,
 	})
+	// 其他排序算法的分数 ZSet
+	addPostRankScores(pipeline, strconv.FormatInt(postId, 10), 0, 0, int64(now), int64(now))
 	// 把帖子id添加到社区 set
 	communityKey := getRedisKey(KeyCommunityPostSetPrefix) + strconv.Itoa(int(communityId))
 	pipeline.SAdd(communityKey, postId)
@@ -152,14 +222,19 @@ func CreatePost(postId, communityId int64) (err error) {
 }
 
 // DeleteCommentVote 删除评论的点赞数据
-func DeleteCommentVote(commentID string) error {
+func DeleteCommentVote(commentID, postID string) error {
 	pipeline := client.TxPipeline() // 使用事务pipeline
+
+	// 删除帖子评论排序记录
+	pipeline.ZRem(getRedisKey(KeyCommentTopZSetPrefix+postID), commentID)
+	pipeline.ZRem(getRedisKey(KeyCommentBestZSetPrefix+postID), commentID)
 	
 	// 删除评论点赞记录
 	pipeline.Del(getRedisKey(KeyCommentVotedZSetPrefix + commentID))
 	
 	// 删除评论时间记录
 	pipeline.ZRem(getRedisKey(KeyCommentTimeZSet), commentID)
+	pipeline.ZRem(getRedisKey(KeyCommentScoreZSet), commentID)
 	
 	// 执行事务
 	_, err := pipeline.Exec()
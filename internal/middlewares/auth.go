@@ -2,10 +2,12 @@ package middlewares
 
 import (
 	controller "go_community/internal/controller"
+	"go_community/internal/service"
 	"go_community/pkg/jwt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // JWTAuthMiddleware 基于JWT的认证中间件
@@ -34,8 +36,88 @@ func JWTAuthMiddleware() func(c *gin.Context) {
 			c.Abort()
 			return
 		}
-		// 将当前请求的 UserID 信息保存到请求的上下文 c
+		// 拒绝已注销会话或修改密码前签发的 token
+		if err := service.CheckSession(mc.UserID, mc.SessionID, mc.TokenVersion); err != nil {
+			if err == service.ErrorTokenRevoked {
+				controller.ResponseError(c, controller.CodeInvalidToken)
+			} else {
+				zap.L().Error("service.CheckSession failed", zap.Error(err))
+				controller.ResponseError(c, controller.CodeServerBusy)
+			}
+			c.Abort()
+			return
+		}
+		// 将当前请求的 UserID、角色和会话信息保存到请求的上下文 c
 		c.Set(controller.CtxUserIDKey, mc.UserID)
+		c.Set(controller.CtxUserRoleKey, mc.Role)
+		c.Set(controller.CtxSessionIDKey, mc.SessionID)
 		c.Next() // 后续的处理请求的函数中通过 c.Get(CtxUserIDKey) 来获取当前请求的用户信息
 	}
 }
+
+// StreamAuthMiddleware 实时推送接口的认证中间件
+// 浏览器的 EventSource 不能设置请求头，允许通过 URI 参数 ticket 携带一次性票据（见 service.CreateStreamTicket），
+// 请求头中有 token 时与 JWTAuthMiddleware 相同
+func StreamAuthMiddleware() func(c *gin.Context) {
+	auth := JWTAuthMiddleware()
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if c.Request.Header.Get("Authorization") != "" || ticket == "" {
+			auth(c)
+			return
+		}
+		userID, role, sessionID, err := service.RedeemStreamTicket(ticket)
+		if err != nil {
+			if err == service.ErrorInvalidStreamTicket {
+				controller.ResponseError(c, controller.CodeInvalidToken)
+			} else {
+				zap.L().Error("service.RedeemStreamTicket failed", zap.Error(err))
+				controller.ResponseError(c, controller.CodeServerBusy)
+			}
+			c.Abort()
+			return
+		}
+		c.Set(controller.CtxUserIDKey, userID)
+		c.Set(controller.CtxUserRoleKey, role)
+		c.Set(controller.CtxSessionIDKey, sessionID)
+		c.Next()
+	}
+}
+
+// OptionalJWTAuthMiddleware 可选的JWT认证中间件，用于无需登录的接口
+// 请求携带有效的 token 时将用户信息保存到上下文，否则按未登录处理，不会中断请求
+func OptionalJWTAuthMiddleware() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.Request.Header.Get("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if mc, err := jwt.ParseToken(parts[1]); err == nil && service.CheckSession(mc.UserID, mc.SessionID, mc.TokenVersion) == nil {
+				c.Set(controller.CtxUserIDKey, mc.UserID)
+				c.Set(controller.CtxUserRoleKey, mc.Role)
+				c.Set(controller.CtxSessionIDKey, mc.SessionID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RoleAuthMiddleware 基于角色的鉴权中间件，只放行拥有指定角色的用户
+// 需要注册在 JWTAuthMiddleware 之后，依赖其写入上下文的角色信息
+func RoleAuthMiddleware(roles ...int8) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		_role, ok := c.Get(controller.CtxUserRoleKey)
+		if !ok {
+			controller.ResponseError(c, controller.CodeNotLogin)
+			c.Abort()
+			return
+		}
+		role, _ := _role.(int8)
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+		controller.ResponseError(c, controller.CodeNoPermission)
+		c.Abort()
+	}
+}
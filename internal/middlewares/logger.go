@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"os"
 	"runtime/debug"
 	"strings"
@@ -64,12 +65,38 @@ func getLogWriter(filename string, maxSize, maxBackup, maxAge int) zapcore.Write
 	return zapcore.AddSync(lumberJackLogger)
 }
 
+// sensitiveQueryKeys 不能写入日志的 URI 参数
+var sensitiveQueryKeys = []string{"access_token", "refresh_token", "ticket", "token"}
+
+// redactQuery 隐藏 URI 参数中的 token 等凭据，避免日志泄露后被重放
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// 无法解析时不记录原始参数
+		return "[unparsable]"
+	}
+	redacted := false
+	for _, key := range sensitiveQueryKeys {
+		if _, ok := values[key]; ok {
+			values.Set(key, "***")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
 // GinLogger 接收gin框架默认的日志
 func GinLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := redactQuery(c.Request.URL.RawQuery)
 		c.Next()
 
 		cost := time.Since(start)
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactQuery(t *testing.T) {
+	assert.Equal(t, "", redactQuery(""))
+	assert.Equal(t, "page=1&size=10", redactQuery("page=1&size=10"))
+
+	for _, raw := range []string{
+		"ticket=abc",
+		"access_token=eyJhbGciOi.payload.sig&page=2",
+		"refresh_token=eyJhbGciOi.payload.sig",
+		"token=abc&token=def",
+	} {
+		redacted := redactQuery(raw)
+		values, err := url.ParseQuery(redacted)
+		assert.NoError(t, err)
+		for _, key := range sensitiveQueryKeys {
+			if v, ok := values[key]; ok {
+				assert.Equal(t, []string{"***"}, v, raw)
+			}
+		}
+		assert.NotContains(t, redacted, "abc")
+		assert.NotContains(t, redacted, "payload")
+	}
+	assert.Equal(t, "access_token=%2A%2A%2A&page=2", redactQuery("access_token=xyz&page=2"))
+}
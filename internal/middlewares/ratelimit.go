@@ -1,39 +1,60 @@
 package middlewares
 
 import (
+	controller "go_community/internal/controller"
+	"go_community/internal/service"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/juju/ratelimit"
+	"go.uber.org/zap"
 )
 
 /*
-	令牌桶其实和漏桶的原理类似，
-	令牌桶按固定的速率往桶里放入令牌，并且只要能从桶里取出令牌就能通过，令牌桶支持突发流量的快速处理。
-	对于从桶里取不到令牌的场景，可以选择等待也可以直接拒绝并返回。
-	对于令牌桶的 Go 语言实现，可以参照 github.com/juju/ratelimit 库。
-	这个库支持多种令牌桶模式，并且使用起来也比较简单。
-
-	对于该限流中间件的注册位置，可以按照不同的限流策略将其注册到不同的位置，例如：
-	如果对全站限流，就可以注册成全局的中间件。
-	如果是某一组路由需要限流，就只需将该限流中间件注册到对应的路由组。
+	分布式限流：基于 redis 的 GCRA 算法，多个实例共享同一份限流状态
+	1.注册在 JWTAuthMiddleware 之后时按用户id限流，否则按客户端 IP 限流
+	2.限流策略（limit/period）在配置文件 rate_limit.policies 中按名称定义
+	3.响应头返回 X-RateLimit-Limit、X-RateLimit-Remaining、X-RateLimit-Reset（秒），
+	  被限流时返回 429 及 Retry-After（秒）
+	4.redis 出错时放行请求，避免限流故障导致服务不可用
 */
 
-// RateLimitMiddleware 创建指定填充速率和容量大小的令牌桶
-func RateLimitMiddleware(fillInterval time.Duration, cap int64) func(c *gin.Context) {
-	bucket := ratelimit.NewBucket(fillInterval, cap)
+// RateLimitMiddleware 按指定的限流策略限制请求频率
+func RateLimitMiddleware(policy string) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		// 如果取不到令牌，就中断本次请求返回 rate limit...
-		//if bucket.Take(1) > 0 {
-		//
-		//}
-		if bucket.TakeAvailable(1) == 0 {
-			c.String(http.StatusOK, "rate limit...")
+		var userID int64
+		if uid, ok := c.Get(controller.CtxUserIDKey); ok {
+			userID, _ = uid.(int64)
+		}
+		result, err := service.RateLimit(policy, userID, c.ClientIP())
+		if err != nil {
+			zap.L().Error("service.RateLimit failed",
+				zap.String("policy", policy),
+				zap.Error(err))
+			c.Next()
+			return
+		}
+		if result == nil {
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.ResetAfter), 10))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.FormatInt(ceilSeconds(result.RetryAfter), 10))
+			controller.ResponseErrorWithStatus(c, http.StatusTooManyRequests, controller.CodeTooManyRequests)
 			c.Abort()
 			return
 		}
-		// 取到令牌就放行
 		c.Next()
 	}
 }
+
+// ceilSeconds 将时长向上取整为秒
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
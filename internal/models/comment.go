@@ -27,9 +27,12 @@ type ApiCommentDetail struct {
 	ReplyToUserName   string `json:"reply_to_name"`       // 被回复人用户名
 	ReplyToUserAvatar string `json:"reply_to_avatar"`     // 被回复人头像
 	ReplyCount        int64  `json:"reply_count"`
-	VoteNum           int64  `json:"vote_num"`
+	VoteNum           int64  `json:"vote_num"` // 赞成票数
 	Content           string `json:"content"`
 	CreateTime        string `json:"create_time"`
+	VoteCount                // 嵌入投票统计
+
+	Mentions []*ApiMention `json:"mentions,omitempty"` // 内容中 @ 的用户
 }
 
 // ApiCommentListRes 评论列表接口响应数据
@@ -37,3 +40,20 @@ type ApiCommentListRes struct {
 	Page *Page               `json:"page"` // 分页信息
 	List []*ApiCommentDetail `json:"list"` // 评论列表
 }
+
+// ApiCommentTreeNode 评论树节点
+type ApiCommentTreeNode struct {
+	*ApiCommentDetail
+	Depth      int                   `json:"depth"`       // 在返回的评论树中所在的层数，从1开始
+	Replies    []*ApiCommentTreeNode `json:"replies"`     // 已加载的回复
+	HasMore    bool                  `json:"has_more"`    // 是否还有未加载的回复
+	NextCursor string                `json:"next_cursor"` // 加载更多回复的游标，配合 parent_id 使用
+}
+
+// ApiCommentTreeRes 评论树接口响应数据
+type ApiCommentTreeRes struct {
+	Page       *Page                 `json:"page,omitempty"` // 一级评论分页信息（按 post_id 查询时返回）
+	List       []*ApiCommentTreeNode `json:"list"`           // 评论树
+	HasMore    bool                  `json:"has_more"`       // 按 parent_id 加载时，是否还有未加载的回复
+	NextCursor string                `json:"next_cursor"`    // 按 parent_id 加载时，下一页的游标
+}
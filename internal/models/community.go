@@ -16,8 +16,11 @@ type CommunityDetail struct {
 	CommunityID   int64     `json:"community_id" db:"community_id"`
 	CommunityName string    `json:"community_name" db:"community_name"`
 	Introduction  string    `json:"introduction,omitempty" db:"introduction"`
+	OwnerID       int64     `json:"owner_id,string" db:"owner_id"` // 创建者的用户id
 	Status        int8      `json:"status" db:"status"`
 	CreateTime    time.Time `json:"create_time" db:"create_time"`
+
+	MemberCount int64 `json:"member_count,omitempty" db:"-"` // 成员数量，只在社区详情和社区列表接口中返回
 }
 
 // ApiCommunityDetailRes 社区列表接口响应数据
@@ -25,3 +28,25 @@ type ApiCommunityDetailRes struct {
 	Page *Page              `json:"page"` // 分页信息
 	List []*CommunityDetail `json:"list"` // 社区列表
 }
+
+// CommunityModerator 社区版主模型
+type CommunityModerator struct {
+	CommunityID int64     `json:"community_id" db:"community_id"`
+	UserID      int64     `json:"user_id,string" db:"user_id"`
+	CreateTime  time.Time `json:"create_time" db:"create_time"`
+}
+
+// ApiCommunityModerator 社区版主列表接口响应数据
+type ApiCommunityModerator struct {
+	UserID     int64  `json:"user_id,string"`
+	UserName   string `json:"username"`
+	Avatar     string `json:"avatar"`
+	CreateTime string `json:"create_time"`
+}
+
+// ParamCommunityFeed 加入的社区的帖子列表请求参数
+type ParamCommunityFeed struct {
+	Page  int64  `json:"page" form:"page"`                   // 页码
+	Size  int64  `json:"size" form:"size"`                   // 每页数量
+	Order string `json:"order" form:"order" example:"score"` // 排序依据，与帖子列表相同
+}
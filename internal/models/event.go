@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// 事件总线的主题，事件数据为对应的 XxxEvent 指针
+const (
+	TopicPostCreated    = "post.created"    // *PostCreatedEvent
+	TopicCommentCreated = "comment.created" // *CommentCreatedEvent
+	TopicUserMentioned  = "user.mentioned"  // *UserMentionedEvent
+	TopicVoted          = "vote.voted"      // *VotedEvent
+	TopicNotification   = "notification"    // *Notification，通知已保存
+)
+
+// PostCreatedEvent 发布帖子
+type PostCreatedEvent struct {
+	PostID      int64
+	AuthorID    int64
+	CommunityID int64
+	CreateTime  time.Time
+}
+
+// CommentCreatedEvent 发布评论/回复
+type CommentCreatedEvent struct {
+	CommentID    int64
+	PostID       int64
+	ParentID     int64
+	AuthorID     int64 // 评论作者
+	PostAuthorID int64 // 帖子作者
+	ReplyToUID   int64 // 被回复人
+	Content      string
+}
+
+// UserMentionedEvent 在帖子或评论中 @ 用户
+type UserMentionedEvent struct {
+	TargetType int8 // 帖子或评论，取值同 VoteTargetPost/VoteTargetComment
+	TargetID   int64
+	PostID     int64
+	AuthorID   int64   // 发布内容的用户
+	UserIDs    []int64 // 被 @ 的用户
+	Content    string
+}
+
+// VotedEvent 投票
+type VotedEvent struct {
+	TargetType int8 // 帖子或评论，取值同 VoteTargetPost/VoteTargetComment
+	TargetID   int64
+	UserID     int64 // 投票的用户
+	Direction  int8
+	UpVotes    int64 // 投票后的赞成票数
+}
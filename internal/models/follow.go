@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Follow 关注关系
+type Follow struct {
+	ID         int64     `db:"id"`
+	FollowerID int64     `db:"follower_id"` // 粉丝
+	FolloweeID int64     `db:"followee_id"` // 被关注的用户
+	CreateTime time.Time `db:"create_time"`
+}
+
+// ApiFollowUser 关注/粉丝列表中的用户
+type ApiFollowUser struct {
+	UserID     int64     `json:"user_id,string" db:"user_id"`
+	UserName   string    `json:"username" db:"username"`
+	Avatar     string    `json:"avatar" db:"avatar"`
+	FollowTime time.Time `json:"follow_time" db:"follow_time"` // 关注时间
+}
+
+// ApiFollowListRes 关注/粉丝列表返回模型
+type ApiFollowListRes struct {
+	Page Page             `json:"page"`
+	List []*ApiFollowUser `json:"list"`
+}
+
+// ParamFollowList 关注/粉丝列表请求参数
+type ParamFollowList struct {
+	Page int64 `json:"page" form:"page"` // 页码
+	Size int64 `json:"size" form:"size"` // 每页数量
+}
+
+// ParamFeed 关注动态请求参数
+type ParamFeed struct {
+	Page int64 `json:"page" form:"page"` // 页码
+	Size int64 `json:"size" form:"size"` // 每页数量
+}
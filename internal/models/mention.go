@@ -0,0 +1,16 @@
+package models
+
+// Mention 帖子或评论中 @ 的用户
+type Mention struct {
+	TargetType int8   `db:"target_type"` // 取值同 VoteTargetPost/VoteTargetComment
+	TargetID   int64  `db:"target_id"`
+	UserID     int64  `db:"user_id"`
+	UserName   string `db:"username"` // 内容中 @ 的用户名（用户改名后仍可以与内容对应）
+}
+
+// ApiMention 返回给客户端的提及信息，客户端将内容中的 @username 渲染为用户链接
+type ApiMention struct {
+	UserID   int64  `json:"user_id,string" db:"user_id"`
+	UserName string `json:"username" db:"username"` // 内容中 @ 的用户名
+	Avatar   string `json:"avatar" db:"avatar"`     // 用户当前的头像
+}
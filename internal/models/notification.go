@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// 通知类型
+const (
+	NotificationTypeComment   int8 = 1 // 帖子收到评论
+	NotificationTypeReply     int8 = 2 // 评论收到回复
+	NotificationTypeMention   int8 = 3 // 被 @
+	NotificationTypeMilestone int8 = 4 // 帖子/评论的赞成票数达到里程碑
+)
+
+// Notification 通知
+type Notification struct {
+	NotificationID int64     `json:"notification_id,string" db:"notification_id"`
+	UserID         int64     `json:"user_id,string" db:"user_id"`   // 接收通知的用户
+	ActorID        int64     `json:"actor_id,string" db:"actor_id"` // 触发通知的用户，0 表示系统
+	Type           int8      `json:"type" db:"type"`
+	TargetType     int8      `json:"target_type" db:"target_type"` // 取值同 VoteTargetPost/VoteTargetComment
+	TargetID       int64     `json:"target_id,string" db:"target_id"`
+	PostID         int64     `json:"post_id,string" db:"post_id"` // 所属帖子，用于跳转
+	Content        string    `json:"content" db:"content"`        // 内容摘要
+	IsRead         bool      `json:"is_read" db:"is_read"`
+	CreateTime     time.Time `json:"create_time" db:"create_time"`
+}
+
+// ApiNotification 通知详情（附带触发通知的用户信息）
+type ApiNotification struct {
+	*Notification
+	ActorName   string `json:"actor_name"`
+	ActorAvatar string `json:"actor_avatar"`
+}
+
+// ApiNotificationListRes 通知列表返回模型
+type ApiNotificationListRes struct {
+	Page   Page               `json:"page"`
+	Unread int64              `json:"unread"` // 未读通知数量
+	List   []*ApiNotification `json:"list"`
+}
+
+// ParamNotificationList 通知列表请求参数
+type ParamNotificationList struct {
+	UnreadOnly bool  `json:"unread_only" form:"unread_only"` // 只返回未读通知
+	Page       int64 `json:"page" form:"page"`               // 页码
+	Size       int64 `json:"size" form:"size"`               // 每页数量
+}
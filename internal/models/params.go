@@ -4,13 +4,18 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"time"
 )
 
 // 定义请求参数的结构体
 
 const (
-	OrderTime  = "time"
-	OrderScore = "score"
+	OrderTime          = "time"          // 按发帖时间
+	OrderScore         = "score"         // 按简化版投票分数（发帖时间 + 432 × 净票数）
+	OrderHot           = "hot"           // Reddit hot 算法
+	OrderGravity       = "gravity"       // Hacker News 重力衰减算法
+	OrderBest          = "best"          // Wilson score 置信区间下限
+	OrderControversial = "controversial" // 争议度
 )
 
 // parseID 解析ID，支持字符串和数字类型
@@ -45,6 +50,19 @@ type Page struct {
 // ParamSignUp 注册请求参数
 type ParamSignUp struct {
 	UserName        string `json:"username" binding:"required"`
+	Email           string `json:"email" binding:"required,email,max=64"`
+	Password        string `json:"password" binding:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=Password"`
+}
+
+// ParamForgotPassword 找回密码请求参数
+type ParamForgotPassword struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ParamResetPassword 重置密码请求参数
+type ParamResetPassword struct {
+	Token           string `json:"token" binding:"required"`
 	Password        string `json:"password" binding:"required"`
 	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=Password"`
 }
@@ -60,6 +78,11 @@ type ParamUpdateUser struct {
 	Username string `json:"username" binding:"required"` // 用户名,必填
 }
 
+// ParamUpdateRole 修改用户角色的参数
+type ParamUpdateRole struct {
+	Role *int8 `json:"role" binding:"required,oneof=0 1 2"` // 角色(0普通用户,1版主,2管理员)
+}
+
 // ParamUpdatePassword 修改密码的参数
 type ParamUpdatePassword struct {
 	OldPassword string `json:"old_password" binding:"required"` // 旧密码
@@ -101,26 +124,33 @@ func (p *ParamPost) UnmarshalJSON(data []byte) error {
 
 // ParamPostListQueryNoSearch 获取帖子列表的请求参数（不搜索关键词）
 type ParamPostListQueryNoSearch struct {
-	CommunityID int64  `json:"community_id" form:"community_id"`   // 可以为空
-	Page        int64  `json:"page" form:"page"`                   // 页码
-	Size        int64  `json:"size" form:"size"`                   // 每页数量
-	Order       string `json:"order" form:"order" example:"score"` // 排序依据
+	CommunityID int64  `json:"community_id" form:"community_id"`                                                     // 可以为空
+	Page        int64  `json:"page" form:"page"`                                                                     // 页码
+	Size        int64  `json:"size" form:"size"`                                                                     // 每页数量
+	Order       string `json:"order" form:"order" example:"score" enums:"time,score,hot,gravity,best,controversial"` // 排序依据
 }
 
-// ParamPostListQueryWithSearch 获取帖子列表的请求参数（不搜索社区ID）
+// ParamPostListQueryWithSearch 搜索帖子的请求参数
 type ParamPostListQueryWithSearch struct {
-	Page   int64  `json:"page" form:"page"`     // 页码
-	Size   int64  `json:"size" form:"size"`     // 每页数量
-	Search string `json:"search" form:"search"` // 关键字搜索
+	Page        int64  `json:"page" form:"page"`                                  // 页码
+	Size        int64  `json:"size" form:"size"`                                  // 每页数量
+	Search      string `json:"search" form:"search"`                              // 关键字搜索，为空时返回空列表
+	CommunityID int64  `json:"community_id" form:"community_id"`                  // 按社区过滤，可以为空
+	AuthorID    int64  `json:"author_id" form:"author_id"`                        // 按作者过滤，可以为空
+	StartDate   string `json:"start_date" form:"start_date" example:"2024-01-01"` // 发布日期下限（包含），可以为空
+	EndDate     string `json:"end_date" form:"end_date" example:"2024-12-31"`     // 发布日期上限（包含），可以为空
 }
 
 // ParamPostList 获取帖子列表的请求参数
 type ParamPostList struct {
-	CommunityID int64  `json:"community_id" form:"community_id"`   // 可以为空
-	Page        int64  `json:"page" form:"page"`                   // 页码
-	Size        int64  `json:"size" form:"size"`                   // 每页数量
-	Order       string `json:"order" form:"order" example:"score"` // 排序依据
-	Search      string `json:"search" form:"search"`               // 关键字搜索
+	CommunityID int64     `json:"community_id" form:"community_id"`                      // 可以为空
+	Page        int64     `json:"page" form:"page"`                                      // 页码
+	Size        int64     `json:"size" form:"size"`                                      // 每页数量
+	Order       string    `json:"order" form:"order" example:"score"`                    // 排序依据
+	Search      string    `json:"search" form:"search"`                                  // 关键字搜索
+	AuthorID    int64     `json:"author_id" form:"author_id"`                            // 搜索时按作者过滤
+	StartDate   time.Time `json:"start_date" form:"start_date" time_format:"2006-01-02"` // 搜索时的发布日期下限（包含）
+	EndDate     time.Time `json:"end_date" form:"end_date" time_format:"2006-01-02"`     // 搜索时的发布日期上限（包含）
 }
 
 // ParamUpdatePost 更新帖子请求参数
@@ -239,12 +269,34 @@ func (p *ParamUpdateComment) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// 评论列表的排序方式
+const (
+	CommentOrderNew  = "new"  // 最新发布
+	CommentOrderOld  = "old"  // 最早发布
+	CommentOrderTop  = "top"  // 净票数最高
+	CommentOrderBest = "best" // Wilson score 最高
+)
+
 // ParamCommentList 获取评论列表的请求参数
 type ParamCommentList struct {
-	PostID    int64 `form:"post_id"`         // 帖子id,获取帖子评论时必填
-	CommentID int64 `form:"comment_id"`      // 评论id,获取评论回复时必填
-	Page      int64 `form:"page,default=1"`  // 页码
-	Size      int64 `form:"size,default=10"` // 每页数量
+	PostID    int64  `form:"post_id"`                                                      // 帖子id,获取帖子评论时必填
+	CommentID int64  `form:"comment_id"`                                                   // 评论id,获取评论回复时必填
+	Page      int64  `form:"page,default=1"`                                               // 页码
+	Size      int64  `form:"size,default=10"`                                              // 每页数量
+	Order     string `form:"order,default=new" binding:"omitempty,oneof=new old top best"` // 帖子评论的排序方式
+}
+
+// ParamCommentTree 获取评论树的请求参数
+// 按 post_id 分页查询一级评论及其回复；按 parent_id + cursor 加载某条评论下折叠的回复
+type ParamCommentTree struct {
+	PostID     int64  `form:"post_id"`                                                      // 帖子id,获取帖子评论树时必填
+	ParentID   int64  `form:"parent_id"`                                                    // 评论id,加载更多回复时必填
+	Cursor     string `form:"cursor"`                                                       // 加载更多回复的游标,为空时从头加载
+	Page       int64  `form:"page,default=1"`                                               // 一级评论页码
+	Size       int64  `form:"size,default=10"`                                              // 一级评论每页数量,最多50
+	Order      string `form:"order,default=new" binding:"omitempty,oneof=new old top best"` // 一级评论的排序方式
+	Depth      int    `form:"depth"`                                                        // 返回的层数,为空时使用配置的默认值
+	ReplySizes string `form:"reply_sizes"`                                                  // 每层回复的数量,逗号分隔,如 "5,3"
 }
 
 // ParamUpdateCommunity 更新社区请求参数
@@ -253,11 +305,38 @@ type ParamUpdateCommunity struct {
 	Introduction string `json:"introduction" binding:"required"`   // 评论内容
 }
 
+// ParamCommunityModerator 添加社区版主请求参数
+type ParamCommunityModerator struct {
+	UserID int64 `json:"user_id" binding:"required"` // 版主的用户id
+}
+
+// UnmarshalJSON 自定义反序列化方法
+func (p *ParamCommunityModerator) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		UserID interface{} `json:"user_id"`
+	}{}
+
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	// 处理必填字段 UserID
+	if tmp.UserID == nil {
+		return errors.New("user_id is required")
+	}
+	userID, err := parseID(tmp.UserID)
+	if err != nil {
+		return errors.New("invalid user_id")
+	}
+	p.UserID = userID
+	return nil
+}
+
 // ParamVoteData 投票数据
 type ParamVoteData struct {
-	TargetID   int64 `json:"target_id" binding:"required"`              // 投票目标ID
-	TargetType int8  `json:"target_type" binding:"required,oneof=1 2"`  // 投票目标类型(1:帖子 2:评论)
-	Direction  int8  `json:"direction" binding:"required,oneof=1 0 -1"` // 赞成票(1)、取消投票(0)、反对票(-1)
+	TargetID   int64 `json:"target_id" binding:"required"`             // 投票目标ID
+	TargetType int8  `json:"target_type" binding:"required,oneof=1 2"` // 投票目标类型(1:帖子 2:评论)
+	Direction  int8  `json:"direction" binding:"oneof=1 0 -1"`         // 赞成票(1)、取消投票(0)、反对票(-1)
 }
 
 // UnmarshalJSON 自定义反序列化方法
@@ -265,7 +344,7 @@ func (p *ParamVoteData) UnmarshalJSON(data []byte) error {
 	tmp := struct {
 		TargetID   interface{} `json:"target_id"`
 		TargetType int8        `json:"target_type"`
-		Direction  int8        `json:"direction"`
+		Direction  *int8       `json:"direction"` // 使用指针区分未传值和取消投票(0)
 	}{}
 
 	if err := json.Unmarshal(data, &tmp); err != nil {
@@ -292,13 +371,19 @@ func (p *ParamVoteData) UnmarshalJSON(data []byte) error {
 	p.TargetType = tmp.TargetType
 
 	// 处理必填字段 Direction
-	if tmp.Direction == 0 {
+	if tmp.Direction == nil {
 		return errors.New("缺少必填字段direction")
 	}
-	if tmp.Direction != 1 && tmp.Direction != -1 {
-		return errors.New("direction必须是1或-1")
+	if *tmp.Direction != 1 && *tmp.Direction != 0 && *tmp.Direction != -1 {
+		return errors.New("direction必须是1、0或-1")
 	}
-	p.Direction = tmp.Direction
+	p.Direction = *tmp.Direction
 
 	return nil
 }
+
+// ParamRetractVote 撤销投票请求参数
+type ParamRetractVote struct {
+	TargetID   int64 `json:"target_id" form:"target_id" binding:"required"`               // 投票目标ID
+	TargetType int8  `json:"target_type" form:"target_type" binding:"required,oneof=1 2"` // 投票目标类型(1:帖子 2:评论)
+}
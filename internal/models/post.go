@@ -46,10 +46,20 @@ func (p *Post) UnmarshalJSON(data []byte) (err error) {
 type ApiPostDetail struct {
 	AuthorName       string             `json:"author_name"`   // 作者名
 	AuthorAvatar     string             `json:"author_avatar"` // 头像相对路径
-	VoteNum          int64              `json:"vote_num"`      // 投票数量
+	VoteNum          int64              `json:"vote_num"`      // 投票数量（赞成票数）
 	CommentCount     int64              `json:"comment_count"` // 帖子评论的数量
+	VoteCount                           // 嵌入投票统计
 	*Post                               // 嵌入帖子结构体
 	*CommunityDetail `json:"community"` // 嵌入社区结构体
+	Highlight        *PostHighlight     `json:"highlight,omitempty"` // 搜索结果的高亮信息
+	Mentions         []*ApiMention      `json:"mentions,omitempty"`  // 正文中 @ 的用户（帖子详情）
+}
+
+// PostHighlight 搜索结果的高亮信息，关键词使用 <em> 标签包裹，其余内容已做 HTML 转义
+type PostHighlight struct {
+	Title   string  `json:"title"`   // 高亮后的标题
+	Snippet string  `json:"snippet"` // 高亮后的正文摘要
+	Score   float64 `json:"score"`   // 相关度分数
 }
 
 // ApiPostDetailRes 搜索帖子返回模型
@@ -0,0 +1,27 @@
+package models
+
+import "encoding/json"
+
+// 实时推送的事件名称（SSE 的 event 字段）
+const (
+	PushEventNotification = "notification" // 新通知，数据为 ApiNotification
+	PushEventUnread       = "unread"       // 未读通知数量变化，数据为 ApiUnreadCount
+	PushEventComment      = "comment"      // 帖子的新评论/回复，数据为 ApiCommentDetail
+)
+
+// PushMessage 通过 redis 频道转发的推送消息
+type PushMessage struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// ApiUnreadCount 未读通知数量
+type ApiUnreadCount struct {
+	Unread int64 `json:"unread"`
+}
+
+// ApiStreamTicket 推送连接的一次性票据
+type ApiStreamTicket struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int64  `json:"expires_in"` // 有效期（秒）
+}
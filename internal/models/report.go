@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 举报对象类型
+const (
+	ReportTargetPost      int8 = 1 // 帖子
+	ReportTargetComment   int8 = 2 // 评论
+	ReportTargetUser      int8 = 3 // 用户
+	ReportTargetCommunity int8 = 4 // 社区（只有系统举报）
+)
+
+// 举报原因
+const (
+	ReportReasonSpam      int8 = 1 // 垃圾广告
+	ReportReasonAbuse     int8 = 2 // 辱骂/人身攻击
+	ReportReasonPorn      int8 = 3 // 色情低俗
+	ReportReasonIllegal   int8 = 4 // 违法违规
+	ReportReasonOther     int8 = 5 // 其他
+	ReportReasonSensitive int8 = 6 // 命中敏感词（系统举报）
+)
+
+// 举报状态
+const (
+	ReportStatusPending  int8 = 0 // 待处理
+	ReportStatusClaimed  int8 = 1 // 处理中（已被认领）
+	ReportStatusResolved int8 = 2 // 已处理
+)
+
+// 举报的处理方式
+const (
+	ReportActionHide    int8 = 1 // 隐藏内容
+	ReportActionDelete  int8 = 2 // 删除内容
+	ReportActionDismiss int8 = 3 // 驳回举报
+	ReportActionBan     int8 = 4 // 封禁作者
+)
+
+// 帖子/评论的状态
+const (
+	ContentStatusDeleted int8 = 0 // 已删除
+	ContentStatusNormal  int8 = 1 // 正常
+	ContentStatusHidden  int8 = 2 // 被版主隐藏
+)
+
+// Report 举报
+type Report struct {
+	ReportID     int64      `json:"report_id,string" db:"report_id"`
+	TargetType   int8       `json:"target_type" db:"target_type"`
+	TargetID     int64      `json:"target_id,string" db:"target_id"`
+	TargetUserID int64      `json:"target_user_id,string" db:"target_user_id"` // 被举报内容的作者（举报用户时为该用户）
+	ReporterID   int64      `json:"reporter_id,string" db:"reporter_id"`       // 举报人，0 表示系统
+	Reason       int8       `json:"reason" db:"reason"`
+	Detail       string     `json:"detail" db:"detail"`
+	Status       int8       `json:"status" db:"status"`
+	HandlerID    int64      `json:"handler_id,string" db:"handler_id"` // 认领/处理人
+	Action       int8       `json:"action" db:"action"`
+	Note         string     `json:"note" db:"note"` // 处理备注
+	ClaimTime    *time.Time `json:"claim_time" db:"claim_time"`
+	ResolveTime  *time.Time `json:"resolve_time" db:"resolve_time"`
+	CreateTime   time.Time  `json:"create_time" db:"create_time"`
+}
+
+// ApiReportListRes 举报列表返回模型
+type ApiReportListRes struct {
+	Page Page      `json:"page"`
+	List []*Report `json:"list"`
+}
+
+// ParamReport 举报请求参数
+type ParamReport struct {
+	TargetType int8   `json:"target_type" binding:"required,oneof=1 2 3"` // 举报对象类型(1帖子,2评论,3用户)
+	TargetID   int64  `json:"target_id" binding:"required"`               // 举报对象id
+	Reason     int8   `json:"reason" binding:"required,oneof=1 2 3 4 5"`  // 举报原因(1垃圾广告,2辱骂/人身攻击,3色情低俗,4违法违规,5其他)
+	Detail     string `json:"detail" binding:"max=500"`                   // 补充说明
+}
+
+// UnmarshalJSON 自定义反序列化方法
+func (p *ParamReport) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		TargetType int8        `json:"target_type"`
+		TargetID   interface{} `json:"target_id"`
+		Reason     int8        `json:"reason"`
+		Detail     string      `json:"detail"`
+	}{}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	if tmp.TargetID == nil {
+		return errors.New("target_id is required")
+	}
+	targetID, err := parseID(tmp.TargetID)
+	if err != nil {
+		return errors.New("invalid target_id")
+	}
+	p.TargetType = tmp.TargetType
+	p.TargetID = targetID
+	p.Reason = tmp.Reason
+	p.Detail = tmp.Detail
+	return nil
+}
+
+// ParamReportList 举报列表请求参数
+type ParamReportList struct {
+	Status     *int8 `json:"status" form:"status" binding:"omitempty,oneof=0 1 2"`             // 状态(0待处理,1处理中,2已处理)，为空时返回未处理完的举报
+	TargetType int8  `json:"target_type" form:"target_type" binding:"omitempty,oneof=1 2 3 4"` // 举报对象类型，可以为空
+	Page       int64 `json:"page" form:"page"`                                                 // 页码
+	Size       int64 `json:"size" form:"size"`                                                 // 每页数量
+}
+
+// ParamResolveReport 处理举报请求参数
+type ParamResolveReport struct {
+	Action int8   `json:"action" binding:"required,oneof=1 2 3 4"` // 处理方式(1隐藏,2删除,3驳回,4封禁作者)
+	Note   string `json:"note" binding:"max=200"`                  // 处理备注
+}
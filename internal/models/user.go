@@ -7,15 +7,33 @@ import (
 	"strings"
 )
 
+// 用户角色
+const (
+	RoleUser      int8 = 0 // 普通用户
+	RoleModerator int8 = 1 // 版主
+	RoleAdmin     int8 = 2 // 管理员
+)
+
+// 用户状态
+const (
+	UserStatusDeleted int8 = 0 // 已删除
+	UserStatusNormal  int8 = 1 // 正常
+	UserStatusBanned  int8 = 2 // 封禁
+)
+
 // User 定义请求参数结构体
 type User struct {
-	UserID       int64  `json:"user_id,string" db:"user_id"`
-	UserName     string `json:"username" db:"username"`
-	Password     string `json:"password" db:"password"`
-	Avatar       string `json:"avatar" db:"avatar"` // 头像相对路径
-	Status       int8   `json:"status" db:"status"`
-	AccessToken  string
-	RefreshToken string
+	UserID        int64  `json:"user_id,string" db:"user_id"`
+	UserName      string `json:"username" db:"username"`
+	Password      string `json:"password" db:"password"`
+	Avatar        string `json:"avatar" db:"avatar"` // 头像相对路径
+	Role          int8   `json:"role" db:"role"`     // 用户角色
+	Status        int8   `json:"status" db:"status"`
+	Email         string `json:"email" db:"email"`
+	EmailVerified bool   `json:"email_verified" db:"email_verified"`
+	TokenVersion  int64  `json:"-" db:"token_version"` // token 版本，修改密码后递增
+	AccessToken   string
+	RefreshToken  string
 }
 
 // UnmarshalJSON 为User类型实现自定义的UnmarshalJSON方法
@@ -0,0 +1,24 @@
+package models
+
+// 投票目标类型
+const (
+	VoteTargetPost    int8 = 1 // 帖子
+	VoteTargetComment int8 = 2 // 评论
+)
+
+// VoteArchive 投票窗口关闭后归档到 mysql 的投票数据
+type VoteArchive struct {
+	TargetID   int64   `json:"target_id,string" db:"target_id"`
+	TargetType int8    `json:"target_type" db:"target_type"`
+	UpVotes    int64   `json:"up_votes" db:"up_votes"`
+	DownVotes  int64   `json:"down_votes" db:"down_votes"`
+	Score      float64 `json:"score" db:"score"`
+}
+
+// VoteCount 帖子/评论的投票统计及当前用户的投票状态
+type VoteCount struct {
+	UpVotes   int64 `json:"up_votes"`   // 赞成票数
+	DownVotes int64 `json:"down_votes"` // 反对票数
+	NetVotes  int64 `json:"net_votes"`  // 净票数（赞成票数 - 反对票数）
+	MyVote    int8  `json:"my_vote"`    // 当前用户的投票方向（1赞成 -1反对 0未投票或未登录）
+}
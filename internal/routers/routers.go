@@ -5,6 +5,7 @@ import (
 	"go_community/global"
 	controller "go_community/internal/controller"
 	"go_community/internal/middlewares"
+	"go_community/internal/models"
 	"net/http"
 
 	"github.com/gin-contrib/cors"
@@ -16,7 +17,7 @@ import (
 )
 
 // SetupRouter 设置路由
-func SetupRouter(mode string) *gin.Engine {
+func SetupRouter(mode string) (*gin.Engine, error) {
 	// 设置成发布模式
 	if mode == gin.ReleaseMode {
 		gin.SetMode(gin.ReleaseMode)
@@ -27,10 +28,10 @@ func SetupRouter(mode string) *gin.Engine {
 
 	// 初始化路由（没有默认中间件）
 	r := gin.New()
+	if err := setTrustedProxies(r, global.Conf.TrustedProxies); err != nil {
+		return nil, err
+	}
 	// 设置中间件
-	// r.Use(logger.GinLogger(), logger.GinRecovery(true), // Recovery 中间件：recover 项目可能出现的 panic，并使用 zap 记录相关日志
-	// 	middlewares.RateLimitMiddleware(2*time.Second, 1), // 限流中间件（全局限流）：每两秒钟添加1个令牌
-	// )
 	r.Use(middlewares.GinLogger(), middlewares.GinRecovery(true)) // Recovery 中间件：recover 项目可能出现的 panic，并使用 zap 记录相关日志
 	r.Use(cors.Default())                                         // 默认允许所有跨域请求
 	// 自定义跨域请求 CORS 相关配置项
@@ -52,53 +53,105 @@ func SetupRouter(mode string) *gin.Engine {
 
 	// 注册 swagger api 相关路由
 	r.GET("/swagger/*any", gs.WrapHandler(swaggerFiles.Handler))
+	// JWT 签名公钥
+	r.GET("/.well-known/jwks.json", controller.JWKSHandler)
 	// 注册路由
 	v1 := r.Group("/api/v1")
+	v1.Use(middlewares.RateLimitMiddleware("default")) // 接口限流（按客户端 IP）
 
 	// 无需认证的接口
+	// 可选认证：携带有效 token 时返回当前用户的投票方向
+	optionalAuth := middlewares.OptionalJWTAuthMiddleware()
 	{
 		// 用户业务
 		v1.POST("/signup", controller.SignUpHandler)
 		v1.POST("/login", controller.LoginHandler)
 		v1.GET("/refresh_token", controller.RefreshTokenHandler)
-		v1.GET("/user/:id", controller.GetUserInfoHandler) // 获取用户信息
+		v1.GET("/email/verify", controller.VerifyEmailHandler)                    // 验证邮箱
+		v1.POST("/password/forgot", controller.ForgotPasswordHandler)             // 找回密码（发送重置邮件）
+		v1.POST("/password/reset", controller.ResetPasswordHandler)               // 重置密码
+		v1.GET("/user/:id", controller.GetUserInfoHandler)                        // 获取用户信息
+		v1.GET("/user/:id/followers", controller.GetFollowerListHandler)          // 粉丝列表
+		v1.GET("/user/:id/following", controller.GetFollowingListHandler)         // 关注列表
+		v1.GET("/user/:id/communities", controller.GetJoinedCommunityListHandler) // 加入的社区列表
 		// 帖子业务
-		v1.GET("/posts", controller.GetPostListHandler)              // 获取帖子列表（带分页）
-		v1.GET("/posts2", controller.GetPostListHandler2)            // 获取帖子列表（带分页以及排序）
-		v1.GET("/posts/user/:id", controller.GetUserPostListHandler) // 获取帖子列表（根据用户ID）
-		v1.GET("/post/:id", controller.PostDetailHandler)            // 获取帖子详情
-		v1.GET("/search", controller.PostSearchHandler)              // 搜索帖子
+		v1.GET("/posts", optionalAuth, controller.GetPostListHandler)              // 获取帖子列表（带分页）
+		v1.GET("/posts2", optionalAuth, controller.GetPostListHandler2)            // 获取帖子列表（带分页以及排序）
+		v1.GET("/posts/user/:id", optionalAuth, controller.GetUserPostListHandler) // 获取帖子列表（根据用户ID）
+		v1.GET("/post/:id", optionalAuth, controller.PostDetailHandler)            // 获取帖子详情
+		v1.GET("/search", optionalAuth, controller.PostSearchHandler)              // 搜索帖子
 		// 社区业务
-		v1.GET("/community", controller.CommunityHandler)           // 获取分类社区列表
-		v1.GET("/community2", controller.CommunityHandler2)         // 获取分类社区列表（带分页）
-		v1.GET("/community/:id", controller.CommunityDetailHandler) // 根据ID查找社区详情
+		v1.GET("/community", controller.CommunityHandler)                             // 获取分类社区列表
+		v1.GET("/community2", controller.CommunityHandler2)                           // 获取分类社区列表（带分页）
+		v1.GET("/community/:id", controller.CommunityDetailHandler)                   // 根据ID查找社区详情
+		v1.GET("/community/:id/moderators", controller.GetCommunityModeratorsHandler) // 获取社区版主列表
 		// 评论业务
-		v1.GET("/comments", controller.GetCommentListHandler)      // 获取评论列表（支持获取帖子评论和评论回复）
-		v1.GET("/comment/:id", controller.GetCommentDetailHandler) // 获取评论详情
+		v1.GET("/comments", optionalAuth, controller.GetCommentListHandler)      // 获取评论列表（支持获取帖子评论和评论回复）
+		v1.GET("/comments/tree", optionalAuth, controller.GetCommentTreeHandler) // 获取评论树
+		v1.GET("/comment/:id", optionalAuth, controller.GetCommentDetailHandler) // 获取评论详情
+	}
+
+	// 实时推送（SSE），支持通过 ticket 参数携带一次性票据
+	stream := v1.Group("/stream", middlewares.StreamAuthMiddleware())
+	{
+		stream.GET("/notifications", controller.NotificationStreamHandler) // 个人通知
+		stream.GET("/post/:id/comments", controller.CommentStreamHandler)  // 帖子的新评论
 	}
 
 	// 需要认证的接口
 	v1.Use(middlewares.JWTAuthMiddleware())
+	// 按角色鉴权的中间件
+	adminOnly := middlewares.RoleAuthMiddleware(models.RoleAdmin)
+	moderatorOnly := middlewares.RoleAuthMiddleware(models.RoleModerator, models.RoleAdmin)
+	// 写操作按用户id限流
+	postLimit := middlewares.RateLimitMiddleware("post")
+	commentLimit := middlewares.RateLimitMiddleware("comment")
+	voteLimit := middlewares.RateLimitMiddleware("vote")
 	{
 		// 用户业务
-		v1.PUT("/user/name", controller.UpdateUserNameHandler)     // 修改用户名
-		v1.PUT("/user/password", controller.UpdatePasswordHandler) // 修改用户密码
-		v1.POST("/user/avatar", controller.UpdateAvatarHandler)    // 修改用户头像
+		v1.POST("/logout", controller.LogoutHandler)                          // 退出登录
+		v1.POST("/logout/all", controller.LogoutAllHandler)                   // 退出所有设备
+		v1.POST("/email/verify/resend", controller.ResendVerifyEmailHandler)  // 重新发送验证邮件
+		v1.PUT("/user/name", controller.UpdateUserNameHandler)                // 修改用户名
+		v1.PUT("/user/password", controller.UpdatePasswordHandler)            // 修改用户密码
+		v1.POST("/user/avatar", controller.UpdateAvatarHandler)               // 修改用户头像
+		v1.PUT("/user/:id/role", adminOnly, controller.UpdateUserRoleHandler) // 修改用户角色（管理员）
+		// 关注业务
+		v1.POST("/user/:id/follow", controller.FollowHandler)         // 关注用户
+		v1.DELETE("/user/:id/follow", controller.UnfollowHandler)     // 取消关注
+		v1.GET("/feed/following", controller.GetFollowingFeedHandler) // 关注动态
 		// 帖子业务
-		v1.POST("/post", controller.CreatePostHandler)       // 创建帖子
-		v1.PUT("/post", controller.UpdatePostHandler)        // 更新帖子
-		v1.DELETE("/post/:id", controller.DeletePostHandler) // 删除帖子
+		v1.POST("/post", postLimit, controller.CreatePostHandler) // 创建帖子
+		v1.PUT("/post", postLimit, controller.UpdatePostHandler)  // 更新帖子
+		v1.DELETE("/post/:id", controller.DeletePostHandler)      // 删除帖子
 		// 投票业务
-		v1.POST("/vote", controller.VoteHandler) // 投票（帖子/评论）
+		v1.POST("/vote", voteLimit, controller.VoteHandler)          // 投票（帖子/评论）
+		v1.DELETE("/vote", voteLimit, controller.RetractVoteHandler) // 撤销投票（帖子/评论）
 		// 社区业务
-		v1.POST("/community", controller.CreateCommunityHandler)       // 创建社区
-		v1.PUT("/community/:id", controller.UpdateCommunityHandler)    // 更新社区
-		v1.DELETE("/community/:id", controller.DeleteCommunityHandler) // 删除社区
+		v1.POST("/community", adminOnly, controller.CreateCommunityHandler)                         // 创建社区（管理员）
+		v1.PUT("/community/:id", adminOnly, controller.UpdateCommunityHandler)                      // 更新社区（管理员）
+		v1.DELETE("/community/:id", adminOnly, controller.DeleteCommunityHandler)                   // 删除社区（管理员）
+		v1.POST("/community/:id/moderators", controller.AddCommunityModeratorHandler)               // 添加社区版主
+		v1.DELETE("/community/:id/moderators/:user_id", controller.RemoveCommunityModeratorHandler) // 移除社区版主
+		v1.POST("/community/:id/join", controller.JoinCommunityHandler)                             // 加入社区
+		v1.DELETE("/community/:id/join", controller.LeaveCommunityHandler)                          // 退出社区
+		v1.GET("/feed/communities", controller.GetJoinedCommunityFeedHandler)                       // 加入的社区的帖子
 		// 评论业务
-		v1.POST("/comment", controller.CreateCommentHandler)                   // 创建评论/回复
-		v1.PUT("/comment", controller.UpdateCommentHandler)                    // 更新评论
+		v1.POST("/comment", commentLimit, controller.CreateCommentHandler)     // 创建评论/回复
+		v1.PUT("/comment", commentLimit, controller.UpdateCommentHandler)      // 更新评论
 		v1.DELETE("/comment/:id", controller.DeleteCommentHandler)             // 删除评论
 		v1.DELETE("/comments/:id", controller.DeleteCommentWithRepliesHandler) // 删除评论及其回复
+		// 举报业务
+		v1.POST("/report", controller.ReportHandler)                                   // 举报帖子/评论/用户
+		v1.GET("/reports", moderatorOnly, controller.GetReportListHandler)             // 审核队列（版主/管理员）
+		v1.POST("/report/:id/claim", moderatorOnly, controller.ClaimReportHandler)     // 认领举报（版主/管理员）
+		v1.POST("/report/:id/resolve", moderatorOnly, controller.ResolveReportHandler) // 处理举报（版主/管理员）
+		// 通知业务
+		v1.GET("/notifications", controller.GetNotificationListHandler)                     // 通知列表
+		v1.GET("/notifications/unread_count", controller.GetUnreadNotificationCountHandler) // 未读通知数量
+		v1.PUT("/notification/:id/read", controller.MarkNotificationReadHandler)            // 标记通知为已读
+		v1.PUT("/notifications/read", controller.MarkAllNotificationsReadHandler)           // 标记所有通知为已读
+		v1.POST("/stream/ticket", controller.CreateStreamTicketHandler)                     // 获取实时推送连接的票据
 	}
 
 	pprof.Register(r) // 注册 pprof 相关路由
@@ -109,5 +162,16 @@ func SetupRouter(mode string) *gin.Engine {
 		})
 	})
 
-	return r
+	return r, nil
+}
+
+// setTrustedProxies 设置受信任的反向代理，按 IP 限流和登录失败计数都依赖 c.ClientIP()
+// gin 默认信任所有地址，会直接使用客户端可以伪造的 X-Forwarded-For；
+// 设置后只解析来自代理的请求头，并从右向左跳过代理地址，取第一个不受信任的地址作为客户端 IP
+func setTrustedProxies(r *gin.Engine, proxies []string) error {
+	// 传入 nil 表示不信任任何代理，直接使用连接的远端地址
+	if len(proxies) == 0 {
+		proxies = nil
+	}
+	return r.SetTrustedProxies(proxies)
 }
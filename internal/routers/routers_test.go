@@ -0,0 +1,49 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clientIP 模拟一次请求，返回 gin 解析出的客户端 IP（即按 IP 限流时使用的 key）
+func clientIP(t *testing.T, proxies []string, remoteAddr, forwardedFor string) string {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	require.NoError(t, setTrustedProxies(r, proxies))
+	r.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Body.String()
+}
+
+func TestClientIPIgnoresSpoofedHeader(t *testing.T) {
+	proxies := []string{"127.0.0.1"}
+
+	// nginx 使用 $proxy_add_x_forwarded_for 在客户端伪造的值之后追加真实地址
+	assert.Equal(t, "203.0.113.7", clientIP(t, proxies, "127.0.0.1:40000", "203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", clientIP(t, proxies, "127.0.0.1:40000", "1.1.1.1, 203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", clientIP(t, proxies, "127.0.0.1:40000", "9.9.9.9, 8.8.8.8, 203.0.113.7"))
+
+	// 直连的客户端携带 X-Forwarded-For 时使用连接的远端地址
+	assert.Equal(t, "203.0.113.7", clientIP(t, proxies, "203.0.113.7:40000", "1.1.1.1"))
+
+	// 未配置代理时不信任任何请求头
+	assert.Equal(t, "127.0.0.1", clientIP(t, nil, "127.0.0.1:40000", "1.1.1.1"))
+}
+
+func TestSetTrustedProxiesInvalid(t *testing.T) {
+	assert.Error(t, setTrustedProxies(gin.New(), []string{"not-an-ip"}))
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	highlightPre  = "<em>"  // 高亮开始标签
+	highlightPost = "</em>" // 高亮结束标签
+	snippetLength = 120     // 正文摘要的长度（按 rune 计算）
+)
+
+// matchMask 标记文本中与词项匹配的位置（按 rune 计算，忽略大小写）
+func matchMask(runes []rune, terms []string) []bool {
+	lower := toLower(runes)
+	mask := make([]bool, len(lower))
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) != term {
+				continue
+			}
+			// 英文单词需要完整匹配，避免高亮单词的一部分
+			if isWordRune(t[0]) && ((i > 0 && isWordRune(lower[i-1])) ||
+				(i+len(t) < len(lower) && isWordRune(lower[i+len(t)]))) {
+				continue
+			}
+			for k := i; k < i+len(t); k++ {
+				mask[k] = true
+			}
+		}
+	}
+	return mask
+}
+
+// highlight 为文本中匹配的词项添加高亮标签，其余内容做 HTML 转义
+func highlight(runes []rune, mask []bool) string {
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && mask[j] == mask[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if mask[i] {
+			b.WriteString(highlightPre)
+			b.WriteString(segment)
+			b.WriteString(highlightPost)
+		} else {
+			b.WriteString(segment)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// highlightText 高亮整段文本（用于标题）
+func highlightText(text string, terms []string) string {
+	runes := []rune(text)
+	return highlight(runes, matchMask(runes, terms))
+}
+
+// makeSnippet 截取第一个匹配位置附近的正文作为摘要并高亮
+func makeSnippet(text string, terms []string) string {
+	if utf8.RuneCountInString(text) <= snippetLength {
+		return highlightText(text, terms)
+	}
+	runes := []rune(text)
+	mask := matchMask(runes, terms)
+
+	// 从第一个匹配位置往前保留一部分上下文
+	start := 0
+	for i, m := range mask {
+		if m {
+			start = i - snippetLength/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetLength
+	if end > len(runes) {
+		end = len(runes)
+		start = end - snippetLength
+	}
+
+	snippet := highlight(runes[start:end], mask[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
@@ -0,0 +1,184 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数
+const (
+	bm25K1      = 1.2  // 词频饱和度
+	bm25B       = 0.75 // 文档长度归一化程度
+	titleWeight = 2    // 标题中的词项按多次出现计算词频
+)
+
+// indexedDoc 倒排索引中保存的文档
+type indexedDoc struct {
+	*Document
+	terms  map[string]int // 词项及词频
+	length int            // 文档长度（词项总数）
+}
+
+// MemoryIndex 内嵌的倒排索引，数据保存在内存中，并发安全
+type MemoryIndex struct {
+	mu          sync.RWMutex
+	docs        map[int64]*indexedDoc
+	postings    map[string]map[int64]int // 词项 -> 文档id -> 词频
+	totalLength int64                    // 所有文档长度之和，用于计算平均长度
+}
+
+// NewMemoryIndex 创建内嵌的倒排索引
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[int64]*indexedDoc),
+		postings: make(map[string]map[int64]int),
+	}
+}
+
+// Index 添加或更新文档
+func (m *MemoryIndex) Index(doc *Document) error {
+	terms := make(map[string]int)
+	length := 0
+	for _, t := range tokenize(doc.Title) {
+		terms[t.term] += titleWeight
+		length += titleWeight
+	}
+	for _, t := range tokenize(doc.Content) {
+		terms[t.term]++
+		length++
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(doc.ID)
+	m.docs[doc.ID] = &indexedDoc{Document: doc, terms: terms, length: length}
+	m.totalLength += int64(length)
+	for term, tf := range terms {
+		posting, ok := m.postings[term]
+		if !ok {
+			posting = make(map[int64]int)
+			m.postings[term] = posting
+		}
+		posting[doc.ID] = tf
+	}
+	return nil
+}
+
+// Delete 删除文档
+func (m *MemoryIndex) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(id)
+	return nil
+}
+
+// remove 从索引中删除文档，调用方需持有写锁
+func (m *MemoryIndex) remove(id int64) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		posting := m.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(m.postings, term)
+		}
+	}
+	m.totalLength -= int64(doc.length)
+	delete(m.docs, id)
+}
+
+// Search 按 BM25 计算相关度并分页返回结果
+func (m *MemoryIndex) Search(q *Query) (*Result, error) {
+	terms := queryTerms(q.Keywords)
+	result := &Result{Hits: make([]*Hit, 0)}
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n := float64(len(m.docs))
+	if n == 0 {
+		return result, nil
+	}
+	avgLength := float64(m.totalLength) / n
+
+	scores := make(map[int64]float64)
+	for _, term := range terms {
+		posting := m.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			doc := m.docs[id]
+			if !matchFilters(doc.Document, q) {
+				continue
+			}
+			f := float64(tf)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(doc.length)/avgLength)
+			scores[id] += idf * f * (bm25K1 + 1) / (f + norm)
+		}
+	}
+
+	// 按相关度从高到低排序，相关度相同时新发布的在前
+	hits := make([]*Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, &Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return m.docs[hits[i].ID].CreateTime.After(m.docs[hits[j].ID].CreateTime)
+	})
+	result.Total = int64(len(hits))
+
+	// 分页
+	page, size := q.Page, q.Size
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+	start := (page - 1) * size
+	if start >= int64(len(hits)) {
+		return result, nil
+	}
+	end := start + size
+	if end > int64(len(hits)) {
+		end = int64(len(hits))
+	}
+
+	// 只为当前页的结果生成高亮
+	for _, hit := range hits[start:end] {
+		doc := m.docs[hit.ID]
+		hit.Title = highlightText(doc.Title, terms)
+		hit.Snippet = makeSnippet(doc.Content, terms)
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+// matchFilters 判断文档是否满足社区、作者和发布时间的过滤条件
+func matchFilters(doc *Document, q *Query) bool {
+	if q.CommunityID != 0 && doc.CommunityID != q.CommunityID {
+		return false
+	}
+	if q.AuthorID != 0 && doc.AuthorID != q.AuthorID {
+		return false
+	}
+	if !q.StartTime.IsZero() && doc.CreateTime.Before(q.StartTime) {
+		return false
+	}
+	if !q.EndTime.IsZero() && !doc.CreateTime.Before(q.EndTime) {
+		return false
+	}
+	return true
+}
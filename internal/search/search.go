@@ -0,0 +1,79 @@
+package search
+
+import "time"
+
+/*
+	帖子全文搜索
+	Searcher 定义搜索引擎需要实现的接口，默认使用内嵌的倒排索引（MemoryIndex）：
+		1.中文按二元组（bigram）切分，英文和数字按单词切分
+		2.按 BM25 计算相关度，返回标题高亮及正文摘要
+		3.帖子创建、更新、删除时同步更新索引，服务启动时从 mysql 全量构建，多实例之间通过 redis 频道同步（见 service/search.go）
+	需要替换为其他搜索引擎（如 Elasticsearch）时，实现 Searcher 接口并调用 SetEngine 即可
+*/
+
+// Document 被索引的帖子
+type Document struct {
+	ID          int64
+	CommunityID int64
+	AuthorID    int64
+	Title       string
+	Content     string
+	CreateTime  time.Time
+}
+
+// Query 搜索条件
+type Query struct {
+	Keywords    string    // 关键词，为空时不返回结果
+	CommunityID int64     // 社区id，为 0 时不过滤
+	AuthorID    int64     // 作者id，为 0 时不过滤
+	StartTime   time.Time // 发布时间下限（包含），为零值时不过滤
+	EndTime     time.Time // 发布时间上限（不包含），为零值时不过滤
+	Page        int64     // 页码
+	Size        int64     // 每页数量
+}
+
+// Hit 搜索命中的帖子
+type Hit struct {
+	ID      int64   // 帖子id
+	Score   float64 // 相关度分数
+	Title   string  // 高亮后的标题
+	Snippet string  // 高亮后的正文摘要
+}
+
+// Result 搜索结果
+type Result struct {
+	Total int64  // 命中的总数
+	Hits  []*Hit // 当前页的结果，按相关度从高到低排序
+}
+
+// Searcher 搜索引擎接口
+type Searcher interface {
+	// Index 添加或更新文档
+	Index(doc *Document) error
+	// Delete 删除文档
+	Delete(id int64) error
+	// Search 搜索文档
+	Search(q *Query) (*Result, error)
+}
+
+var engine Searcher = NewMemoryIndex()
+
+// SetEngine 替换默认的搜索引擎
+func SetEngine(s Searcher) {
+	engine = s
+}
+
+// Index 添加或更新文档
+func Index(doc *Document) error {
+	return engine.Index(doc)
+}
+
+// Delete 删除文档
+func Delete(id int64) error {
+	return engine.Delete(id)
+}
+
+// Search 搜索文档
+func Search(q *Query) (*Result, error) {
+	return engine.Search(q)
+}
@@ -0,0 +1,69 @@
+// search 单元测试
+
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	terms := make([]string, 0)
+	for _, tok := range tokenize("Go语言 社区") {
+		terms = append(terms, tok.term)
+	}
+	assert.Equal(t, []string{"go", "语", "语言", "言", "社", "社区", "区"}, terms)
+
+	// 查询时中文只使用二元组
+	assert.Equal(t, []string{"go", "语言", "社区"}, queryTerms("GO 语言 社区 go"))
+	assert.Equal(t, []string{"帖"}, queryTerms("帖"))
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	now := time.Now()
+	idx := NewMemoryIndex()
+	_ = idx.Index(&Document{ID: 1, CommunityID: 1, AuthorID: 10, Title: "Go 语言入门", Content: "介绍 Go 语言的基础语法", CreateTime: now})
+	_ = idx.Index(&Document{ID: 2, CommunityID: 2, AuthorID: 20, Title: "Redis 实战", Content: "使用 Go 语言操作 Redis", CreateTime: now.Add(-time.Hour)})
+	_ = idx.Index(&Document{ID: 3, CommunityID: 1, AuthorID: 10, Title: "MySQL 索引", Content: "联合索引的最左前缀原则", CreateTime: now.Add(-2 * time.Hour)})
+
+	// 标题命中的帖子相关度更高
+	res, err := idx.Search(&Query{Keywords: "go语言", Page: 1, Size: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), res.Total)
+	assert.Equal(t, int64(1), res.Hits[0].ID)
+	assert.Equal(t, "<em>Go</em> <em>语言</em>入门", res.Hits[0].Title)
+
+	// 过滤条件
+	res, _ = idx.Search(&Query{Keywords: "go", CommunityID: 2, Page: 1, Size: 10})
+	assert.Equal(t, int64(1), res.Total)
+	assert.Equal(t, int64(2), res.Hits[0].ID)
+	res, _ = idx.Search(&Query{Keywords: "索引", AuthorID: 20, Page: 1, Size: 10})
+	assert.Equal(t, int64(0), res.Total)
+	res, _ = idx.Search(&Query{Keywords: "go", StartTime: now.Add(-30 * time.Minute), Page: 1, Size: 10})
+	assert.Equal(t, int64(1), res.Total)
+
+	// 更新和删除
+	_ = idx.Index(&Document{ID: 3, CommunityID: 1, AuthorID: 10, Title: "Go 并发", Content: "goroutine", CreateTime: now})
+	res, _ = idx.Search(&Query{Keywords: "索引", Page: 1, Size: 10})
+	assert.Equal(t, int64(0), res.Total)
+	_ = idx.Delete(1)
+	res, _ = idx.Search(&Query{Keywords: "go", Page: 1, Size: 10})
+	assert.Equal(t, int64(2), res.Total)
+}
+
+func TestMakeSnippet(t *testing.T) {
+	// 高亮时转义 HTML
+	assert.Equal(t, "&lt;b&gt;<em>Go</em>", highlightText("<b>Go", []string{"go"}))
+	// 不高亮单词的一部分
+	assert.Equal(t, "golang", highlightText("golang", []string{"go"}))
+
+	long := ""
+	for i := 0; i < 100; i++ {
+		long += "无关内容"
+	}
+	snippet := makeSnippet(long+"关键词"+long, []string{"关键", "键词"})
+	assert.Contains(t, snippet, "<em>关键词</em>")
+	assert.True(t, len([]rune(snippet)) < len([]rune(long)))
+}
@@ -0,0 +1,93 @@
+package search
+
+import "unicode"
+
+// token 分词结果
+type token struct {
+	term string // 词项（已转小写）
+	pos  int    // 在原文中的位置（按 rune 计算）
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// isWordRune 判断是否为单词字符（字母或数字，不包括中日韩文字）
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// tokenize 索引时使用的分词
+// 英文和数字按单词切分；中文连续片段同时输出单字和二元组，
+// 既能匹配多字词，又能匹配单字查询
+func tokenize(text string) []token {
+	return split(text, true)
+}
+
+// queryTerms 查询时使用的分词，返回去重后的词项
+// 中文连续片段只输出二元组（片段只有一个字时输出单字），减少无关的单字匹配
+func queryTerms(text string) []string {
+	tokens := split(text, false)
+	seen := make(map[string]struct{}, len(tokens))
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if _, ok := seen[t.term]; ok {
+			continue
+		}
+		seen[t.term] = struct{}{}
+		terms = append(terms, t.term)
+	}
+	return terms
+}
+
+// split 按字符类别切分文本，withUnigram 为 true 时中文片段同时输出单字
+func split(text string, withUnigram bool) []token {
+	runes := toLower([]rune(text))
+	tokens := make([]token, 0, len(runes))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				j++
+			}
+			if j-i == 1 {
+				tokens = append(tokens, token{term: string(runes[i]), pos: i})
+			} else {
+				for k := i; k < j; k++ {
+					if withUnigram {
+						tokens = append(tokens, token{term: string(runes[k]), pos: k})
+					}
+					if k+1 < j {
+						tokens = append(tokens, token{term: string(runes[k : k+2]), pos: k})
+					}
+				}
+			}
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{term: string(runes[i:j]), pos: i})
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// toLower 逐个字符转小写，保证与原文的 rune 位置一一对应
+func toLower(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
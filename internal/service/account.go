@@ -0,0 +1,186 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go_community/global"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"go_community/pkg/mail"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+/*
+	邮箱验证和找回密码
+	1.注册后向邮箱发送验证链接，用户也可以重新发送
+	2.找回密码时向已注册的邮箱发送重置链接，邮箱未注册时同样返回成功，避免泄露注册信息
+	3.验证和重置 token 为随机字符串，只能使用一次，过期时间见配置文件 account 部分
+*/
+
+// 配置文件中未设置时使用的默认值
+const (
+	defaultVerifyTokenExpire = 24 * time.Hour
+	defaultResetTokenExpire  = 30 * time.Minute
+	accountTokenBytes        = 32
+)
+
+var (
+	ErrorInvalidAccountToken = errors.New("链接无效或已过期")
+	ErrorEmailVerified       = errors.New("邮箱已验证")
+	ErrorEmailNotSet         = errors.New("未设置邮箱")
+)
+
+// accountConfig 读取邮箱验证和找回密码配置，未设置的参数使用默认值
+func accountConfig() global.AccountConfig {
+	cfg := global.Conf.Account
+	if cfg.VerifyTokenExpire <= 0 {
+		cfg.VerifyTokenExpire = defaultVerifyTokenExpire
+	}
+	if cfg.ResetTokenExpire <= 0 {
+		cfg.ResetTokenExpire = defaultResetTokenExpire
+	}
+	return cfg
+}
+
+// newAccountToken 生成随机 token，返回 token 及其 sha256（保存到 redis）
+func newAccountToken() (token, tokenHash string, err error) {
+	b := make([]byte, accountTokenBytes)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(b)
+	return token, hashAccountToken(token), nil
+}
+
+// hashAccountToken 计算 token 的 sha256
+func hashAccountToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendMailAsync 后台发送邮件，失败时只记录日志
+func sendMailAsync(msg *mail.Message) {
+	go func() {
+		if err := mail.Send(msg); err != nil {
+			zap.L().Error("mail.Send failed",
+				zap.String("to", msg.To),
+				zap.String("subject", msg.Subject),
+				zap.Error(err))
+		}
+	}()
+}
+
+// sendVerifyEmail 生成邮箱验证 token 并发送验证邮件
+func sendVerifyEmail(user *models.User) error {
+	cfg := accountConfig()
+	token, tokenHash, err := newAccountToken()
+	if err != nil {
+		return err
+	}
+	if err := redis.SaveEmailVerifyToken(tokenHash, user.UserID, user.Email, cfg.VerifyTokenExpire); err != nil {
+		return err
+	}
+	sendMailAsync(&mail.Message{
+		To:      user.Email,
+		Subject: "验证你的邮箱",
+		Body: fmt.Sprintf("%s，你好：\n\n请在 %s 内打开以下链接完成邮箱验证：\n%s%s\n\n如果这不是你的操作，请忽略本邮件。\n",
+			user.UserName, cfg.VerifyTokenExpire, cfg.VerifyURL, token),
+	})
+	return nil
+}
+
+// VerifyEmail 使用邮箱验证 token 完成验证
+func VerifyEmail(token string) error {
+	userID, email, err := redis.ConsumeEmailVerifyToken(hashAccountToken(token))
+	if err != nil {
+		if err == redis.ErrorTokenNotExist {
+			return ErrorInvalidAccountToken
+		}
+		return err
+	}
+	// 发送验证邮件后邮箱已变更时，token 失效
+	if err := mysql.SetEmailVerified(userID, email); err != nil {
+		if err == mysql.ErrorInvalidID {
+			return ErrorInvalidAccountToken
+		}
+		return err
+	}
+	return nil
+}
+
+// ResendVerifyEmail 重新发送验证邮件
+func ResendVerifyEmail(userID int64) error {
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return ErrorEmailNotSet
+	}
+	if user.EmailVerified {
+		return ErrorEmailVerified
+	}
+	return sendVerifyEmail(user)
+}
+
+// ForgotPassword 发送重置密码邮件，邮箱未注册时直接返回
+func ForgotPassword(p *models.ParamForgotPassword) error {
+	user, err := mysql.GetUserByEmail(p.Email)
+	if err != nil {
+		if err == mysql.ErrorUserNotExist {
+			zap.L().Info("forgot password with unknown email", zap.String("email", p.Email))
+			return nil
+		}
+		return err
+	}
+
+	cfg := accountConfig()
+	token, tokenHash, err := newAccountToken()
+	if err != nil {
+		return err
+	}
+	if err := redis.SavePasswordResetToken(tokenHash, user.UserID, user.TokenVersion, cfg.ResetTokenExpire); err != nil {
+		return err
+	}
+	sendMailAsync(&mail.Message{
+		To:      user.Email,
+		Subject: "重置你的密码",
+		Body: fmt.Sprintf("%s，你好：\n\n请在 %s 内打开以下链接重置密码：\n%s%s\n\n如果这不是你的操作，请忽略本邮件，你的密码不会被修改。\n",
+			user.UserName, cfg.ResetTokenExpire, cfg.ResetURL, token),
+	})
+	return nil
+}
+
+// ResetPassword 使用重置密码 token 设置新密码，之前签发的所有 token 失效
+func ResetPassword(p *models.ParamResetPassword) error {
+	userID, tokenVersion, err := redis.ConsumePasswordResetToken(hashAccountToken(p.Token))
+	if err != nil {
+		if err == redis.ErrorTokenNotExist {
+			return ErrorInvalidAccountToken
+		}
+		return err
+	}
+	// 签发重置 token 后密码已被修改时，token 失效
+	current, err := mysql.GetUserTokenVersion(userID)
+	if err != nil {
+		if err == mysql.ErrorUserNotExist {
+			return ErrorInvalidAccountToken
+		}
+		return err
+	}
+	if current != tokenVersion {
+		return ErrorInvalidAccountToken
+	}
+
+	newVersion, err := mysql.UpdatePassword(userID, p.Password)
+	if err != nil {
+		return err
+	}
+	return revokeUserTokens(userID, newVersion)
+}
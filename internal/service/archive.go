@@ -0,0 +1,164 @@
+package service
+
+import (
+	"go_community/global"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultArchiveInterval  = 10 * time.Minute // 默认归档扫描间隔
+	defaultArchiveBatchSize = 100              // 默认每批归档数量
+)
+
+// StartVoteArchiver 启动后台投票数据归档任务
+// 定时扫描投票窗口已关闭的帖子和评论，将投票数据保存到 mysql 并清理 redis 中的投票记录
+// 每个实例都会启动，通过 redis 中的锁保证同一时间只有一个实例在归档
+func StartVoteArchiver() {
+	cfg := global.Conf.Archive
+	if !cfg.Enable {
+		return
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultArchiveInterval
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultArchiveBatchSize
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runExclusive(taskVoteArchive, interval, func() { ArchiveExpiredVotes(batchSize) })
+			<-ticker.C
+		}
+	}()
+}
+
+// ArchiveExpiredVotes 归档所有投票窗口已关闭的帖子和评论
+func ArchiveExpiredVotes(batchSize int64) {
+	postCount, err := archiveExpired(batchSize, redis.GetExpiredPostIds, redis.GetPostArchiveData, redis.FinishPostArchive)
+	if err != nil {
+		zap.L().Error("archive post votes failed", zap.Error(err))
+	}
+	commentCount, err := archiveExpired(batchSize, redis.GetExpiredCommentIds, redis.GetCommentArchiveData, redis.FinishCommentArchive)
+	if err != nil {
+		zap.L().Error("archive comment votes failed", zap.Error(err))
+	}
+	if postCount > 0 || commentCount > 0 {
+		zap.L().Info("archive expired votes success",
+			zap.Int("post_count", postCount),
+			zap.Int("comment_count", commentCount))
+	}
+}
+
+// archiveExpired 分批归档投票数据，返回归档的数量
+func archiveExpired(
+	batchSize int64,
+	getExpired func(int64) ([]goredis.Z, error),
+	getData func([]string) ([]*models.VoteArchive, error),
+	finish func([]string, float64) error,
+) (count int, err error) {
+	for {
+		// 1. 查询投票窗口已关闭的元素
+		items, err := getExpired(batchSize)
+		if err != nil {
+			return count, err
+		}
+		if len(items) == 0 {
+			return count, nil
+		}
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.Member.(string))
+		}
+
+		// 2. 从 redis 读取最终的投票数据
+		archives, err := getData(ids)
+		if err != nil {
+			return count, err
+		}
+
+		// 3. 保存到 mysql
+		if err := mysql.ArchiveVotes(archives); err != nil {
+			return count, err
+		}
+
+		// 4. 删除 redis 中的投票记录并推进游标
+		if err := finish(ids, items[len(items)-1].Score); err != nil {
+			return count, err
+		}
+		count += len(ids)
+	}
+}
+
+// getPostVoteCounts 批量查询帖子的投票统计，viewerID 不为 0 时同时查询该用户的投票方向
+func getPostVoteCounts(ids []string, viewerID int64) ([]*models.VoteCount, error) {
+	data, err := redis.GetPostVoteCounts(ids, formatViewerID(viewerID))
+	if err != nil {
+		return nil, err
+	}
+	fillArchivedVoteCounts(TypePost, ids, data)
+	return data, nil
+}
+
+// getCommentVoteCounts 批量查询评论的投票统计，viewerID 不为 0 时同时查询该用户的投票方向
+func getCommentVoteCounts(ids []string, viewerID int64) ([]*models.VoteCount, error) {
+	data, err := redis.GetCommentVoteCounts(ids, formatViewerID(viewerID))
+	if err != nil {
+		return nil, err
+	}
+	fillArchivedVoteCounts(TypeComment, ids, data)
+	return data, nil
+}
+
+// formatViewerID 未登录（viewerID 为 0）时返回空字符串，不查询投票方向
+func formatViewerID(viewerID int64) string {
+	if viewerID == 0 {
+		return ""
+	}
+	return strconv.FormatInt(viewerID, 10)
+}
+
+// fillArchivedVoteCounts redis 中没有投票记录的元素再到归档数据中查询
+func fillArchivedVoteCounts(targetType int8, ids []string, data []*models.VoteCount) {
+	missing := make([]string, 0)
+	for idx, id := range ids {
+		if data[idx].UpVotes == 0 && data[idx].DownVotes == 0 {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	archives, err := mysql.GetVoteArchives(targetType, missing)
+	if err != nil {
+		// 归档数据查询失败不影响列表展示
+		zap.L().Error("mysql.GetVoteArchives failed",
+			zap.Int8("target_type", targetType),
+			zap.Strings("target_ids", missing),
+			zap.Error(err))
+		return
+	}
+	archived := make(map[string]*models.VoteArchive, len(archives))
+	for _, a := range archives {
+		archived[strconv.FormatInt(a.TargetID, 10)] = a
+	}
+	for idx, id := range ids {
+		if a, ok := archived[id]; ok && data[idx].UpVotes == 0 && data[idx].DownVotes == 0 {
+			data[idx].UpVotes = a.UpVotes
+			data[idx].DownVotes = a.DownVotes
+			data[idx].NetVotes = a.UpVotes - a.DownVotes
+		}
+	}
+}
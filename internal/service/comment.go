@@ -4,6 +4,7 @@ import (
 	mysql "go_community/internal/dao/mysql"
 	redis "go_community/internal/dao/redis"
 	"go_community/internal/models"
+	"go_community/pkg/eventbus"
 	"go_community/pkg/snowflake"
 	"strconv"
 
@@ -40,6 +41,18 @@ func CreateComment(userID int64, p *models.ParamComment) error {
 		}
 	}
 
+	// 过滤敏感词
+	reviewWords, err := filterContent(&p.Content)
+	if err != nil {
+		return err
+	}
+
+	// 检查重复内容和评论频率
+	hash, err := guardPublish(guardKindComment, userID, p.Content)
+	if err != nil {
+		return err
+	}
+
 	// 生成评论ID
 	commentID := snowflake.GetID()
 
@@ -60,11 +73,28 @@ func CreateComment(userID int64, p *models.ParamComment) error {
 	}
 
 	// 保存到Redis
-	return redis.CreateComment(commentID)
+	if err := redis.CreateComment(commentID, p.PostID, p.ParentID); err != nil {
+		return err
+	}
+	recordPublish(guardKindComment, userID, hash)
+	submitReview(models.ReportTargetComment, commentID, userID, reviewWords)
+	saveMentions(TypeComment, commentID, p.PostID, userID, p.Content)
+	eventbus.Publish(models.TopicCommentCreated, &models.CommentCreatedEvent{
+		CommentID:    commentID,
+		PostID:       p.PostID,
+		ParentID:     p.ParentID,
+		AuthorID:     userID,
+		PostAuthorID: post.AuthorID,
+		ReplyToUID:   p.ReplyToUID,
+		Content:      p.Content,
+	})
+	return nil
 }
 
-// GetCommentList 获取评论列表
-func GetCommentList(postID int64, page, size int64) (*models.ApiCommentListRes, error) {
+// GetCommentList 获取帖子的评论列表，viewerID 为当前登录用户（未登录时为 0）
+func GetCommentList(p *models.ParamCommentList, viewerID int64) (*models.ApiCommentListRes, error) {
+	postID, page, size := p.PostID, p.Page, p.Size
+
 	// 获取评论总数
 	total, err := mysql.GetCommentCount(postID)
 	if err != nil {
@@ -72,14 +102,20 @@ func GetCommentList(postID int64, page, size int64) (*models.ApiCommentListRes,
 	}
 
 	// 获取分页数据
-	comments, err := mysql.GetCommentList(postID, page, size)
+	comments, err := getPostComments(postID, p.Order, page, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// 提前查询好每条评论的投票数据
+	voteData, err := getCommentVoteCounts(getCommentIds(comments), viewerID)
 	if err != nil {
 		return nil, err
 	}
 
 	// 组装评论详情
 	data := make([]*models.ApiCommentDetail, 0, len(comments))
-	for _, comment := range comments {
+	for idx, comment := range comments {
 		// 获取评论作者信息
 		user, err := mysql.GetUserById(comment.AuthorID)
 		if err != nil {
@@ -98,15 +134,6 @@ func GetCommentList(postID int64, page, size int64) (*models.ApiCommentListRes,
 			replyCount = 0
 		}
 
-		// 获取评论点赞数
-		voteNum, err := redis.GetCommentVoteNum(strconv.FormatInt(comment.CommentID, 10))
-		if err != nil {
-			zap.L().Error("redis.GetCommentVoteNum(comment.CommentID) failed",
-				zap.Int64("comment_id", comment.CommentID),
-				zap.Error(err))
-			voteNum = 0
-		}
-
 		commentDetail := &models.ApiCommentDetail{
 			CommentID:    comment.CommentID,
 			ParentID:     comment.ParentID,
@@ -116,11 +143,13 @@ func GetCommentList(postID int64, page, size int64) (*models.ApiCommentListRes,
 			AuthorName:   user.UserName,
 			AuthorAvatar: user.GetAvatarURL(),
 			ReplyCount:   replyCount,
-			VoteNum:      voteNum,
+			VoteNum:      voteData[idx].UpVotes,
 			CreateTime:   comment.CreateTime.Format("2006-01-02 15:04:05"),
+			VoteCount:    *voteData[idx],
 		}
 		data = append(data, commentDetail)
 	}
+	fillCommentMentions(data)
 
 	// 组装返回数据
 	return &models.ApiCommentListRes{
@@ -133,17 +162,38 @@ func GetCommentList(postID int64, page, size int64) (*models.ApiCommentListRes,
 	}, nil
 }
 
-// GetCommentReplyList 获取评论的回复列表
-func GetCommentReplyList(commentID int64) ([]*models.ApiCommentDetail, error) {
+// getPostComments 按排序方式分页查询帖子的一级评论
+// new/old 按发布时间从 mysql 分页查询，top/best 从 redis 中帖子的评论排序 ZSet 分页查询
+func getPostComments(postID int64, order string, page, size int64) ([]*models.Comment, error) {
+	switch order {
+	case models.CommentOrderTop, models.CommentOrderBest:
+		ids, err := redis.GetCommentIdsInOrder(postID, order, page, size)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.GetCommentListByIds(ids)
+	default:
+		return mysql.GetCommentList(postID, page, size, order == models.CommentOrderOld)
+	}
+}
+
+// GetCommentReplyList 获取评论的回复列表，viewerID 为当前登录用户（未登录时为 0）
+func GetCommentReplyList(commentID, viewerID int64) ([]*models.ApiCommentDetail, error) {
 	// 查询回复列表
 	comments, err := mysql.GetCommentReplyList(commentID)
 	if err != nil {
 		return nil, err
 	}
 
+	// 提前查询好每条回复的投票数据
+	voteData, err := getCommentVoteCounts(getCommentIds(comments), viewerID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 组装评论详情
 	data := make([]*models.ApiCommentDetail, 0, len(comments))
-	for _, comment := range comments {
+	for idx, comment := range comments {
 		// 查询评论作者信息
 		user, err := mysql.GetUserById(comment.AuthorID)
 		if err != nil {
@@ -174,15 +224,6 @@ func GetCommentReplyList(commentID int64) ([]*models.ApiCommentDetail, error) {
 			replyCount = 0
 		}
 
-		// 获取点赞数量
-		voteNum, err := redis.GetCommentVoteNum(strconv.FormatInt(comment.CommentID, 10))
-		if err != nil {
-			zap.L().Error("redis.GetCommentVoteNum(comment.CommentID), failed",
-				zap.Int64("comment_id", comment.CommentID),
-				zap.Error(err))
-			voteNum = 0
-		}
-
 		// 组装评论详情
 		commentDetail := &models.ApiCommentDetail{
 			CommentID:    comment.CommentID,
@@ -193,8 +234,9 @@ func GetCommentReplyList(commentID int64) ([]*models.ApiCommentDetail, error) {
 			AuthorName:   user.UserName,
 			AuthorAvatar: user.GetAvatarURL(),
 			ReplyCount:   replyCount,
-			VoteNum:      voteNum,
+			VoteNum:      voteData[idx].UpVotes,
 			CreateTime:   comment.CreateTime.Format("2006-01-02 15:04:05"),
+			VoteCount:    *voteData[idx],
 		}
 
 		// 只有在有被回复用户时才设置被回复人信息
@@ -206,12 +248,13 @@ func GetCommentReplyList(commentID int64) ([]*models.ApiCommentDetail, error) {
 
 		data = append(data, commentDetail)
 	}
+	fillCommentMentions(data)
 
 	return data, nil
 }
 
-// GetCommentById 根据ID获取评论详情
-func GetCommentById(commentID int64) (*models.ApiCommentDetail, error) {
+// GetCommentById 根据ID获取评论详情，viewerID 为当前登录用户（未登录时为 0）
+func GetCommentById(commentID, viewerID int64) (*models.ApiCommentDetail, error) {
 	// 查询评论
 	comment, err := mysql.GetCommentById(commentID)
 	if err != nil {
@@ -239,13 +282,15 @@ func GetCommentById(commentID int64) (*models.ApiCommentDetail, error) {
 		replyCount = 0
 	}
 
-	// 获取点赞数量
-	voteNum, err := redis.GetCommentVoteNum(strconv.FormatInt(comment.CommentID, 10))
+	// 获取投票数据
+	voteCount := &models.VoteCount{}
+	voteData, err := getCommentVoteCounts([]string{strconv.FormatInt(comment.CommentID, 10)}, viewerID)
 	if err != nil {
-		zap.L().Error("redis.GetCommentVoteNum(comment.CommentID) failed",
+		zap.L().Error("getCommentVoteCounts failed",
 			zap.Int64("comment_id", comment.CommentID),
 			zap.Error(err))
-		voteNum = 0
+	} else {
+		voteCount = voteData[0]
 	}
 
 	// 组装评论详情
@@ -257,8 +302,10 @@ func GetCommentById(commentID int64) (*models.ApiCommentDetail, error) {
 		Content:    comment.Content,
 		AuthorName: user.UserName,
 		ReplyCount: replyCount,
-		VoteNum:    voteNum,
+		VoteNum:    voteCount.UpVotes,
 		CreateTime: comment.CreateTime.Format("2006-01-02 15:04:05"),
+		VoteCount:  *voteCount,
+		Mentions:   getMentions(TypeComment, []int64{comment.CommentID})[comment.CommentID],
 	}
 
 	return commentDetail, nil
@@ -284,12 +331,23 @@ func UpdateComment(userID int64, p *models.ParamUpdateComment) error {
 		return mysql.ErrorNoPermission
 	}
 
+	// 过滤敏感词
+	reviewWords, err := filterContent(&p.Content)
+	if err != nil {
+		return err
+	}
+
 	// 更新评论内容
-	return mysql.UpdateComment(p.CommentID, p.Content)
+	if err := mysql.UpdateComment(p.CommentID, p.Content); err != nil {
+		return err
+	}
+	submitReview(models.ReportTargetComment, p.CommentID, userID, reviewWords)
+	saveMentions(TypeComment, p.CommentID, comment.PostID, userID, p.Content)
+	return nil
 }
 
-// DeleteComment 删除评论
-func DeleteComment(userID, commentID int64) error {
+// DeleteComment 删除评论（作者本人、版主/管理员或评论所在社区的版主）
+func DeleteComment(userID int64, role int8, commentID int64) error {
 	// 1. 检查评论是否存在
 	comment, err := mysql.GetCommentById(commentID)
 	if err != nil {
@@ -299,8 +357,8 @@ func DeleteComment(userID, commentID int64) error {
 		return mysql.ErrorInvalidID
 	}
 
-	// 2. 检查是否是评论作者
-	if comment.AuthorID != userID {
+	// 2. 检查是否是评论作者或有管理权限
+	if comment.AuthorID != userID && !canModerateComment(userID, role, comment) {
 		zap.L().Error("no permission to delete comment",
 			zap.Int64("comment_id", commentID),
 			zap.Int64("user_id", userID),
@@ -308,7 +366,15 @@ func DeleteComment(userID, commentID int64) error {
 		return mysql.ErrorNoPermission
 	}
 
-	// 3. 开启事务
+	// 3. 删除评论
+	return removeComment(comment)
+}
+
+// removeComment 软删除评论，并删除 redis 中的投票数据
+func removeComment(comment *models.Comment) (err error) {
+	commentID := comment.CommentID
+
+	// 1. 开启事务
 	tx, err := mysql.GetDB().Begin()
 	if err != nil {
 		return err
@@ -329,21 +395,21 @@ func DeleteComment(userID, commentID int64) error {
 		}
 	}()
 
-	// 4. 软删除评论（将状态设为0）
+	// 2. 软删除评论（将状态设为0）
 	if err = mysql.DeleteCommentWithTx(tx, commentID); err != nil {
 		return err // defer 中会处理回滚
 	}
 
-	// 5. 删除Redis中的评论数据
-	if err = redis.DeleteCommentVote(strconv.FormatInt(commentID, 10)); err != nil {
+	// 3. 删除Redis中的评论数据
+	if err = redis.DeleteCommentVote(strconv.FormatInt(commentID, 10), strconv.FormatInt(comment.PostID, 10)); err != nil {
 		return err // defer 中会处理回滚
 	}
 
 	return nil // defer 中会处理提交
 }
 
-// DeleteCommentWithReplies 删除评论及其所有回复
-func DeleteCommentWithReplies(userID, commentID int64) error {
+// DeleteCommentWithReplies 删除评论及其所有回复（作者本人、版主/管理员或评论所在社区的版主）
+func DeleteCommentWithReplies(userID int64, role int8, commentID int64) error {
 	// 1. 检查评论是否存在
 	comment, err := mysql.GetCommentById(commentID)
 	if err != nil {
@@ -353,8 +419,8 @@ func DeleteCommentWithReplies(userID, commentID int64) error {
 		return mysql.ErrorInvalidID
 	}
 
-	// 2. 检查是否有权限删除（是否是评论作者）
-	if comment.AuthorID != userID {
+	// 2. 检查是否有权限删除（是否是评论作者或有管理权限）
+	if comment.AuthorID != userID && !canModerateComment(userID, role, comment) {
 		zap.L().Error("no permission to delete comment",
 			zap.Int64("comment_id", commentID),
 			zap.Int64("user_id", userID),
@@ -396,9 +462,33 @@ func DeleteCommentWithReplies(userID, commentID int64) error {
 
 	// 7. 删除Redis中的相关数据
 	allCommentIDs := append([]string{strconv.FormatInt(commentID, 10)}, replyIDs...)
-	if err = redis.DeleteCommentsVoteData(allCommentIDs); err != nil {
+	if err = redis.DeleteCommentsVoteData(strconv.FormatInt(comment.PostID, 10), allCommentIDs); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// getCommentIds 按评论列表的顺序返回评论id
+func getCommentIds(comments []*models.Comment) []string {
+	ids := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, strconv.FormatInt(comment.CommentID, 10))
+	}
+	return ids
+}
+
+// fillCommentMentions 批量查询并设置评论中 @ 的用户
+func fillCommentMentions(details []*models.ApiCommentDetail) {
+	if len(details) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(details))
+	for _, detail := range details {
+		ids = append(ids, detail.CommentID)
+	}
+	mentions := getMentions(TypeComment, ids)
+	for _, detail := range details {
+		detail.Mentions = mentions[detail.CommentID]
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"errors"
+	"go_community/global"
+	mysql "go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+/*
+	评论树
+	1.按 post_id 查询：一级评论按 order 分页，逐层（广度优先）加载回复，每层回复按发布时间升序
+	2.超过 depth 的回复不再加载，节点的 has_more 为 true，前端使用 parent_id 加载
+	3.回复数量超过该层的 reply_size 时，节点返回 next_cursor（最后一条回复的id），
+	  前端使用 parent_id + cursor 加载更多回复，返回的同样是一棵子树
+*/
+
+const (
+	defaultCommentTreeDepth    = 3  // 默认返回的层数
+	defaultCommentTreeMaxDepth = 5  // 默认允许请求的最大层数
+	defaultCommentReplySize    = 5  // 默认每层回复的数量
+	defaultCommentMaxReplySize = 50 // 默认每层回复数量的上限
+	defaultCommentTreePageSize = 10 // 一级评论默认每页数量
+	maxCommentTreePageSize     = 50 // 一级评论每页数量的上限
+)
+
+var ErrorInvalidCursor = errors.New("无效的游标")
+
+// GetCommentTree 获取评论树，viewerID 为当前登录用户（未登录时为 0）
+func GetCommentTree(p *models.ParamCommentTree, viewerID int64) (*models.ApiCommentTreeRes, error) {
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 || p.Size > maxCommentTreePageSize {
+		p.Size = defaultCommentTreePageSize
+	}
+	depth, replySizes := commentTreeLimits(p)
+	data := &models.ApiCommentTreeRes{
+		List: make([]*models.ApiCommentTreeNode, 0),
+	}
+
+	var roots []*models.Comment
+	if p.ParentID != 0 {
+		// 加载某条评论下的更多回复
+		parent, err := mysql.GetCommentById(p.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		var afterID int64
+		if p.Cursor != "" {
+			afterID, err = strconv.ParseInt(p.Cursor, 10, 64)
+			if err != nil {
+				return nil, ErrorInvalidCursor
+			}
+		}
+		size := replySizeAt(replySizes, 0)
+		replies, err := mysql.GetCommentRepliesAfterID(parent.CommentID, afterID, size+1)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(replies)) > size {
+			replies = replies[:size]
+			data.HasMore = true
+			data.NextCursor = strconv.FormatInt(replies[len(replies)-1].CommentID, 10)
+		}
+		roots = replies
+		// 这一层已使用第一个 reply_size，后续层使用剩余的值
+		if len(replySizes) > 1 {
+			replySizes = replySizes[1:]
+		}
+	} else {
+		// 分页查询帖子的一级评论
+		total, err := mysql.GetCommentCount(p.PostID)
+		if err != nil {
+			return nil, err
+		}
+		data.Page = &models.Page{
+			Total: total,
+			Page:  p.Page,
+			Size:  p.Size,
+		}
+		roots, err = getPostComments(p.PostID, p.Order, p.Page, p.Size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	users := make(map[int64]*models.User)
+	level, err := buildCommentTreeNodes(roots, 1, viewerID, users)
+	if err != nil {
+		return nil, err
+	}
+	data.List = level
+
+	// 逐层加载回复
+	for d := 1; d < depth && len(level) > 0; d++ {
+		size := replySizeAt(replySizes, d-1)
+		next := make([]*models.ApiCommentTreeNode, 0)
+		for _, node := range level {
+			if node.ReplyCount == 0 {
+				continue
+			}
+			replies, err := mysql.GetCommentRepliesAfterID(node.CommentID, 0, size+1)
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(replies)) > size {
+				replies = replies[:size]
+				node.HasMore = true
+				node.NextCursor = strconv.FormatInt(replies[len(replies)-1].CommentID, 10)
+			}
+			children, err := buildCommentTreeNodes(replies, node.Depth+1, viewerID, users)
+			if err != nil {
+				return nil, err
+			}
+			node.Replies = children
+			next = append(next, children...)
+		}
+		level = next
+	}
+
+	// 最后一层有回复的节点折叠，前端使用 parent_id 加载
+	for _, node := range level {
+		if node.ReplyCount > 0 && len(node.Replies) == 0 {
+			node.HasMore = true
+		}
+	}
+	return data, nil
+}
+
+// commentTreeLimits 根据请求参数和配置确定返回的层数及每层回复的数量
+func commentTreeLimits(p *models.ParamCommentTree) (depth int, replySizes []int64) {
+	cfg := global.Conf.CommentTree
+	maxDepth := cfg.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultCommentTreeMaxDepth
+	}
+	maxReplySize := cfg.MaxReplySize
+	if maxReplySize <= 0 {
+		maxReplySize = defaultCommentMaxReplySize
+	}
+
+	depth = p.Depth
+	if depth <= 0 {
+		depth = cfg.DefaultDepth
+	}
+	if depth <= 0 {
+		depth = defaultCommentTreeDepth
+	}
+	if depth > maxDepth {
+		depth = maxDepth
+	}
+
+	replySizes = cfg.ReplySizes
+	if p.ReplySizes != "" {
+		replySizes = make([]int64, 0)
+		for _, s := range strings.Split(p.ReplySizes, ",") {
+			size, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil || size <= 0 {
+				continue
+			}
+			replySizes = append(replySizes, size)
+		}
+	}
+	if len(replySizes) == 0 {
+		replySizes = []int64{defaultCommentReplySize}
+	}
+	limited := make([]int64, 0, len(replySizes))
+	for _, size := range replySizes {
+		if size > maxReplySize {
+			size = maxReplySize
+		}
+		limited = append(limited, size)
+	}
+	return depth, limited
+}
+
+// replySizeAt 第 idx 层回复的数量，超出时沿用最后一个值
+func replySizeAt(replySizes []int64, idx int) int64 {
+	if len(replySizes) == 0 {
+		return defaultCommentReplySize
+	}
+	if idx >= len(replySizes) {
+		idx = len(replySizes) - 1
+	}
+	return replySizes[idx]
+}
+
+// buildCommentTreeNodes 组装同一层的评论树节点（批量查询投票数据和回复数量）
+// users 缓存已查询的用户信息，避免同一棵树中重复查询
+func buildCommentTreeNodes(comments []*models.Comment, depth int, viewerID int64, users map[int64]*models.User) ([]*models.ApiCommentTreeNode, error) {
+	nodes := make([]*models.ApiCommentTreeNode, 0, len(comments))
+	if len(comments) == 0 {
+		return nodes, nil
+	}
+
+	ids := getCommentIds(comments)
+	voteData, err := getCommentVoteCounts(ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	replyCounts, err := mysql.GetCommentReplyCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*models.ApiCommentDetail, 0, len(comments))
+	for idx, comment := range comments {
+		user, err := getCachedUser(users, comment.AuthorID)
+		if err != nil {
+			zap.L().Error("mysql.GetUserById(comment.AuthorID) failed",
+				zap.Int64("author_id", comment.AuthorID),
+				zap.Error(err))
+			continue
+		}
+		detail := &models.ApiCommentDetail{
+			CommentID:    comment.CommentID,
+			ParentID:     comment.ParentID,
+			PostID:       comment.PostID,
+			AuthorID:     comment.AuthorID,
+			Content:      comment.Content,
+			AuthorName:   user.UserName,
+			AuthorAvatar: user.GetAvatarURL(),
+			ReplyCount:   replyCounts[comment.CommentID],
+			VoteNum:      voteData[idx].UpVotes,
+			CreateTime:   comment.CreateTime.Format("2006-01-02 15:04:05"),
+			VoteCount:    *voteData[idx],
+		}
+		// 只有在有被回复用户时才设置被回复人信息
+		if comment.ReplyToUID != 0 {
+			if replyTo, err := getCachedUser(users, comment.ReplyToUID); err == nil {
+				detail.ReplyToUID = comment.ReplyToUID
+				detail.ReplyToUserName = replyTo.UserName
+				detail.ReplyToUserAvatar = replyTo.GetAvatarURL()
+			}
+		}
+		nodes = append(nodes, &models.ApiCommentTreeNode{
+			ApiCommentDetail: detail,
+			Depth:            depth,
+			Replies:          make([]*models.ApiCommentTreeNode, 0),
+		})
+		details = append(details, detail)
+	}
+	fillCommentMentions(details)
+	return nodes, nil
+}
+
+// getCachedUser 查询用户信息，优先使用缓存
+func getCachedUser(users map[int64]*models.User, userID int64) (*models.User, error) {
+	if user, ok := users[userID]; ok {
+		return user, nil
+	}
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return nil, err
+	}
+	users[userID] = user
+	return user, nil
+}
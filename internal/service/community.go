@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
 	"go_community/internal/models"
 	"go_community/pkg/snowflake"
 
@@ -32,6 +33,9 @@ func GetCommunityList2(p *models.ParamPage) (*models.ApiCommunityDetailRes, erro
 			zap.Any("params", p))
 		return nil, err
 	}
+	if err := fillMemberCounts(communities); err != nil {
+		return nil, err
+	}
 
 	// 组装返回数据
 	res := &models.ApiCommunityDetailRes{
@@ -48,11 +52,48 @@ func GetCommunityList2(p *models.ParamPage) (*models.ApiCommunityDetailRes, erro
 
 // GetCommunityDetailById 根据ID查询社区详情
 func GetCommunityDetailById(communityID int64) (*models.CommunityDetail, error) {
-	return mysql.GetCommunityDetailById(communityID)
+	community, err := mysql.GetCommunityDetailById(communityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillMemberCounts([]*models.CommunityDetail{community}); err != nil {
+		return nil, err
+	}
+	return community, nil
+}
+
+// fillMemberCounts 批量查询并填充社区的成员数量
+func fillMemberCounts(communities []*models.CommunityDetail) error {
+	ids := make([]int64, 0, len(communities))
+	for _, community := range communities {
+		ids = append(ids, community.CommunityID)
+	}
+	counts, err := mysql.GetCommunityMemberCounts(ids)
+	if err != nil {
+		zap.L().Error("mysql.GetCommunityMemberCounts failed",
+			zap.Int64s("community_ids", ids),
+			zap.Error(err))
+		return err
+	}
+	for _, community := range communities {
+		community.MemberCount = counts[community.CommunityID]
+	}
+	return nil
 }
 
 // CreateCommunity 创建社区
 func CreateCommunity(userID int64, community *models.CommunityDetail) error {
+	// 过滤社区名称和简介中的敏感词
+	reviewWords, err := filterName(community.CommunityName)
+	if err != nil {
+		return err
+	}
+	introWords, err := filterContent(&community.Introduction)
+	if err != nil {
+		return err
+	}
+	reviewWords = append(reviewWords, introWords...)
+
 	// 检查社区名称是否已存在
 	exists, err := mysql.GetCommunityDetailByName(community.CommunityName)
 	if err != nil && err != mysql.ErrorInvalidID {
@@ -65,8 +106,9 @@ func CreateCommunity(userID int64, community *models.CommunityDetail) error {
 		return errors.New("社区名称已存在")
 	}
 
-	// 生成社区ID
+	// 生成社区ID，并记录创建者
 	community.CommunityID = snowflake.GetID()
+	community.OwnerID = userID
 
 	// 创建社区
 	if err := mysql.CreateCommunity(community); err != nil {
@@ -75,6 +117,7 @@ func CreateCommunity(userID int64, community *models.CommunityDetail) error {
 			zap.Error(err))
 		return err
 	}
+	submitReview(models.ReportTargetCommunity, community.CommunityID, userID, reviewWords)
 	return nil
 }
 
@@ -85,6 +128,23 @@ func UpdateCommunity(userID, communityID int64, communityName, introduction stri
 		return errors.New("更新的内容不能为空")
 	}
 
+	// 过滤新的社区名称和简介中的敏感词，未修改的字段不再检查
+	var reviewWords []string
+	if communityName != "" {
+		nameWords, err := filterName(communityName)
+		if err != nil {
+			return err
+		}
+		reviewWords = append(reviewWords, nameWords...)
+	}
+	if introduction != "" {
+		introWords, err := filterContent(&introduction)
+		if err != nil {
+			return err
+		}
+		reviewWords = append(reviewWords, introWords...)
+	}
+
 	// 检查社区是否存在
 	existingCommunity, err := mysql.GetCommunityDetailById(communityID)
 	if err != nil {
@@ -127,6 +187,7 @@ func UpdateCommunity(userID, communityID int64, communityName, introduction stri
 			zap.Error(err))
 		return err
 	}
+	submitReview(models.ReportTargetCommunity, communityID, userID, reviewWords)
 	return nil
 }
 
@@ -162,3 +223,178 @@ func DeleteCommunity(userID int64, communityID int64) error {
 	}
 	return nil
 }
+
+// GetCommunityModerators 获取社区的版主列表
+func GetCommunityModerators(communityID int64) ([]*models.ApiCommunityModerator, error) {
+	// 检查社区是否存在
+	if _, err := mysql.GetCommunityDetailById(communityID); err != nil {
+		return nil, err
+	}
+
+	moderators, err := mysql.GetCommunityModerators(communityID)
+	if err != nil {
+		zap.L().Error("mysql.GetCommunityModerators(communityID) failed",
+			zap.Int64("community_id", communityID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	data := make([]*models.ApiCommunityModerator, 0, len(moderators))
+	for _, m := range moderators {
+		user, err := mysql.GetUserById(m.UserID)
+		if err != nil {
+			zap.L().Error("mysql.GetUserById(m.UserID) failed",
+				zap.Int64("user_id", m.UserID),
+				zap.Error(err))
+			continue
+		}
+		data = append(data, &models.ApiCommunityModerator{
+			UserID:     m.UserID,
+			UserName:   user.UserName,
+			Avatar:     user.GetAvatarURL(),
+			CreateTime: m.CreateTime.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return data, nil
+}
+
+// AddCommunityModerator 添加社区版主（管理员或社区创建者）
+func AddCommunityModerator(operatorID int64, role int8, communityID, userID int64) error {
+	// 检查社区是否存在
+	community, err := mysql.GetCommunityDetailById(communityID)
+	if err != nil {
+		return err
+	}
+
+	// 检查操作权限
+	if !canManageCommunity(operatorID, role, community) {
+		return mysql.ErrorNoPermission
+	}
+
+	// 检查被添加的用户是否存在
+	if _, err := mysql.GetUserById(userID); err != nil {
+		return err
+	}
+
+	return mysql.AddCommunityModerator(communityID, userID)
+}
+
+// RemoveCommunityModerator 移除社区版主（管理员或社区创建者）
+func RemoveCommunityModerator(operatorID int64, role int8, communityID, userID int64) error {
+	// 检查社区是否存在
+	community, err := mysql.GetCommunityDetailById(communityID)
+	if err != nil {
+		return err
+	}
+
+	// 检查操作权限
+	if !canManageCommunity(operatorID, role, community) {
+		return mysql.ErrorNoPermission
+	}
+
+	return mysql.RemoveCommunityModerator(communityID, userID)
+}
+
+// JoinCommunity 加入社区，重复加入时忽略
+func JoinCommunity(userID, communityID int64) error {
+	// 检查社区是否存在
+	if _, err := mysql.GetCommunityDetailById(communityID); err != nil {
+		return err
+	}
+	added, err := mysql.JoinCommunity(communityID, userID)
+	if err != nil {
+		return err
+	}
+	if added {
+		deleteJoinedCommunityFeed(userID)
+	}
+	return nil
+}
+
+// LeaveCommunity 退出社区，不是社区成员时忽略
+func LeaveCommunity(userID, communityID int64) error {
+	removed, err := mysql.LeaveCommunity(communityID, userID)
+	if err != nil {
+		return err
+	}
+	if removed {
+		deleteJoinedCommunityFeed(userID)
+	}
+	return nil
+}
+
+// deleteJoinedCommunityFeed 加入的社区变化后删除合并的帖子缓存，失败时只记录日志，缓存过期后自然更新
+func deleteJoinedCommunityFeed(userID int64) {
+	if err := redis.DeleteJoinedCommunityFeed(userID); err != nil {
+		zap.L().Error("redis.DeleteJoinedCommunityFeed failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+}
+
+// GetJoinedCommunityList 分页查询用户加入的社区
+func GetJoinedCommunityList(userID int64, p *models.ParamPage) (*models.ApiCommunityDetailRes, error) {
+	// 检查用户是否存在
+	if _, err := mysql.GetUserById(userID); err != nil {
+		return nil, err
+	}
+	total, err := mysql.GetJoinedCommunityCount(userID)
+	if err != nil {
+		return nil, err
+	}
+	communities, err := mysql.GetJoinedCommunityList(userID, p.Page, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillMemberCounts(communities); err != nil {
+		return nil, err
+	}
+	return &models.ApiCommunityDetailRes{
+		Page: &models.Page{
+			Page:  p.Page,
+			Size:  p.Size,
+			Total: total,
+		},
+		List: communities,
+	}, nil
+}
+
+// GetJoinedCommunityFeed 查询用户加入的所有社区中的帖子，排序方式与帖子列表相同
+func GetJoinedCommunityFeed(userID int64, p *models.ParamCommunityFeed) (*models.ApiPostDetailRes, error) {
+	data := &models.ApiPostDetailRes{
+		Page: models.Page{
+			Page: p.Page,
+			Size: p.Size,
+		},
+		List: make([]*models.ApiPostDetail, 0),
+	}
+	communityIDs, err := mysql.GetJoinedCommunityIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids, total, err := redis.GetJoinedCommunityPostIds(userID, communityIDs, p.Order, p.Page, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	data.Page.Total = total
+	if len(ids) == 0 {
+		return data, nil
+	}
+	list, invalidIds, err := getPostDetailsByIds(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	data.List = list
+
+	// 异步清理 redis 中已删除的帖子
+	if len(invalidIds) > 0 {
+		go func() {
+			if err := redis.RemoveInvalidPostIds(invalidIds); err != nil {
+				zap.L().Error("redis.RemoveInvalidPostIds failed",
+					zap.Strings("invalid_ids", invalidIds),
+					zap.Error(err))
+			}
+		}()
+	}
+	return data, nil
+}
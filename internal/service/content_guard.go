@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go_community/global"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/pkg/simhash"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+/*
+	反垃圾内容
+	1.发布冷却：同一用户两次发帖/评论之间至少间隔 interval，24 小时内最多发布 daily_limit 次
+	2.重复内容：计算内容的 SimHash 指纹，与该用户 duplicate_window 内发布内容的指纹
+	  汉明距离不超过 duplicate_distance 时视为重复内容
+	3.新账号：注册时长不足 new_account_age 且 karma（最近帖子的净票数之和）不足 new_account_karma 时
+	  使用 new_account_post/new_account_comment 中更严格的限制
+*/
+
+// 发布内容的类型
+const (
+	guardKindPost    = "post"
+	guardKindComment = "comment"
+)
+
+// 配置文件中未设置时使用的默认值
+const (
+	defaultDuplicateWindow  = 24 * time.Hour
+	defaultGuardDailyWindow = 24 * time.Hour // 发布数量的统计窗口
+	karmaPostSize           = 100            // 计算 karma 时统计的最近帖子数量
+)
+
+var ErrorDuplicateContent = errors.New("请勿重复发布相似的内容")
+
+// PublishLimitedError 发布过于频繁，RetryAfter 为需要等待的时长
+type PublishLimitedError struct {
+	RetryAfter time.Duration
+	DailyLimit bool // 是否因为达到 24 小时内的发布数量上限
+}
+
+func (e *PublishLimitedError) Error() string {
+	if e.DailyLimit {
+		return fmt.Sprintf("今日发布数量已达上限，请 %s 后再试", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("发布过于频繁，请 %s 后再试", e.RetryAfter.Round(time.Second))
+}
+
+// contentGuardConfig 读取反垃圾内容配置，未设置的参数使用默认值
+func contentGuardConfig() global.ContentGuardConfig {
+	cfg := global.Conf.ContentGuard
+	if cfg.DuplicateWindow <= 0 {
+		cfg.DuplicateWindow = defaultDuplicateWindow
+	}
+	return cfg
+}
+
+// publishLimit 根据内容类型和是否为新账号选择发布限制
+func publishLimit(cfg global.ContentGuardConfig, kind string, newAccount bool) global.ContentGuardLimit {
+	switch {
+	case kind == guardKindPost && newAccount:
+		return cfg.NewAccountPost
+	case kind == guardKindPost:
+		return cfg.Post
+	case newAccount:
+		return cfg.NewAccountComment
+	default:
+		return cfg.Comment
+	}
+}
+
+// isNewAccount 判断用户是否为新账号：注册时长和 karma 都未达到阈值
+func isNewAccount(cfg global.ContentGuardConfig, userID int64) (bool, error) {
+	if cfg.NewAccountAge <= 0 {
+		return false, nil
+	}
+	createTime, err := mysql.GetUserCreateTime(userID)
+	if err != nil {
+		return false, err
+	}
+	if time.Since(createTime) >= cfg.NewAccountAge {
+		return false, nil
+	}
+	if cfg.NewAccountKarma <= 0 {
+		return true, nil
+	}
+	karma, err := getUserKarma(userID)
+	if err != nil {
+		return false, err
+	}
+	return karma < cfg.NewAccountKarma, nil
+}
+
+// getUserKarma 用户最近帖子的净票数之和
+func getUserKarma(userID int64) (int64, error) {
+	posts, err := mysql.GetUserPostList(userID, 1, karmaPostSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(posts) == 0 {
+		return 0, nil
+	}
+	voteData, err := getPostVoteCounts(getPostIds(posts), 0)
+	if err != nil {
+		return 0, err
+	}
+	var karma int64
+	for _, v := range voteData {
+		karma += v.UpVotes - v.DownVotes
+	}
+	return karma, nil
+}
+
+// guardPublish 发布内容前检查重复内容和发布频率，返回内容的指纹
+// 通过检查后开始新的冷却，发布成功后需要调用 recordPublish 记录指纹
+func guardPublish(kind string, userID int64, content string) (hash uint64, err error) {
+	cfg := contentGuardConfig()
+	if !cfg.Enable {
+		return 0, nil
+	}
+
+	// 1. 重复内容，只有表情或标点的内容没有指纹（0），跳过检查
+	hash = simhash.Fingerprint(content)
+	var hashes []uint64
+	if hash != 0 {
+		if hashes, err = redis.GetRecentSimHashes(kind, userID, cfg.DuplicateWindow); err != nil {
+			return 0, err
+		}
+	}
+	for _, h := range hashes {
+		if simhash.Similar(hash, h, cfg.DuplicateDistance) {
+			zap.L().Warn("duplicate content rejected",
+				zap.String("kind", kind),
+				zap.Int64("user_id", userID))
+			return 0, ErrorDuplicateContent
+		}
+	}
+
+	// 2. 发布频率
+	newAccount, err := isNewAccount(cfg, userID)
+	if err != nil {
+		return 0, err
+	}
+	limit := publishLimit(cfg, kind, newAccount)
+	reason, wait, err := redis.AcquirePublish(kind, userID, limit.Interval, limit.DailyLimit, defaultGuardDailyWindow)
+	if err != nil {
+		return 0, err
+	}
+	if reason != redis.PublishAllowed {
+		return 0, &PublishLimitedError{
+			RetryAfter: wait,
+			DailyLimit: reason == redis.PublishDailyCap,
+		}
+	}
+	return hash, nil
+}
+
+// recordPublish 发布成功后记录内容的指纹，失败时只记录日志
+func recordPublish(kind string, userID int64, hash uint64) {
+	cfg := contentGuardConfig()
+	if !cfg.Enable || hash == 0 {
+		return
+	}
+	if err := redis.AddSimHash(kind, userID, hash, cfg.DuplicateWindow); err != nil {
+		zap.L().Error("redis.AddSimHash failed",
+			zap.String("kind", kind),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+}
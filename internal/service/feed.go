@@ -0,0 +1,441 @@
+package service
+
+import (
+	"errors"
+	"go_community/global"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"go_community/pkg/eventbus"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+/*
+	关注及关注动态
+	1.关注关系保存在 mysql，关注动态的时间线保存在 redis（见 dao/redis/feed.go）
+	2.发帖事件由 StartFeedDispatcher 订阅：普通用户的帖子按批推送到粉丝的时间线，大V的帖子只记录在自己的帖子 ZSet 中
+	3.粉丝数量达到阈值的用户标记为大V后不再切换回推模式，避免切换前后的帖子在时间线中缺失
+	4.用户帖子 ZSet 和时间线丢失时从 mysql 按需加载，也可以使用 -rebuild-redis 重建
+*/
+
+// 配置文件中未设置时使用的默认值
+const (
+	defaultBigAccountFollowers int64 = 10000
+	defaultTimelineSize        int64 = 1000
+	defaultFanoutBatchSize     int64 = 500
+)
+
+var ErrorCannotFollowSelf = errors.New("不能关注自己")
+
+// feedConfig 读取关注动态配置，未设置的参数使用默认值
+func feedConfig() global.FeedConfig {
+	cfg := global.Conf.Feed
+	if cfg.BigAccountFollowers <= 0 {
+		cfg.BigAccountFollowers = defaultBigAccountFollowers
+	}
+	if cfg.TimelineSize <= 0 {
+		cfg.TimelineSize = defaultTimelineSize
+	}
+	if cfg.FanoutBatchSize <= 0 {
+		cfg.FanoutBatchSize = defaultFanoutBatchSize
+	}
+	return cfg
+}
+
+// StartFeedDispatcher 订阅发帖事件，将帖子推送到粉丝的时间线
+func StartFeedDispatcher() {
+	eventbus.Subscribe(models.TopicPostCreated, onPostCreated)
+}
+
+// onPostCreated 记录用户发布的帖子，普通用户的帖子推送到粉丝的时间线
+func onPostCreated(_ string, payload interface{}) {
+	e, ok := payload.(*models.PostCreatedEvent)
+	if !ok {
+		return
+	}
+	cfg := feedConfig()
+	// 先加载之前的帖子，避免 ZSet 中只有新帖子
+	ensureUserPosts([]int64{e.AuthorID})
+	if err := redis.AddUserPost(e.AuthorID, e.PostID, e.CreateTime.Unix(), cfg.TimelineSize); err != nil {
+		zap.L().Error("redis.AddUserPost failed",
+			zap.Int64("author_id", e.AuthorID),
+			zap.Int64("post_id", e.PostID),
+			zap.Error(err))
+		return
+	}
+
+	big, err := redis.IsBigAccount(e.AuthorID)
+	if err != nil {
+		zap.L().Error("redis.IsBigAccount failed",
+			zap.Int64("author_id", e.AuthorID),
+			zap.Error(err))
+		return
+	}
+	if big {
+		return
+	}
+	var lastID int64
+	for {
+		follows, err := mysql.GetFollowersAfterID(e.AuthorID, lastID, cfg.FanoutBatchSize)
+		if err != nil {
+			zap.L().Error("mysql.GetFollowersAfterID failed",
+				zap.Int64("author_id", e.AuthorID),
+				zap.Int64("last_id", lastID),
+				zap.Error(err))
+			return
+		}
+		if len(follows) == 0 {
+			return
+		}
+		userIDs := make([]int64, 0, len(follows))
+		for _, f := range follows {
+			userIDs = append(userIDs, f.FollowerID)
+		}
+		if err := redis.PushToTimelines(userIDs, e.PostID, e.CreateTime.Unix(), cfg.TimelineSize); err != nil {
+			zap.L().Error("redis.PushToTimelines failed",
+				zap.Int64("post_id", e.PostID),
+				zap.Error(err))
+			return
+		}
+		lastID = follows[len(follows)-1].ID
+	}
+}
+
+// ensureUserPosts 从 mysql 加载没有缓存的用户最近的帖子，失败时只记录日志
+func ensureUserPosts(userIDs []int64) {
+	missing, err := redis.GetMissingUserPosts(userIDs)
+	if err != nil {
+		zap.L().Error("redis.GetMissingUserPosts failed", zap.Error(err))
+		return
+	}
+	size := feedConfig().TimelineSize
+	for _, userID := range missing {
+		posts, err := mysql.GetUserRecentPosts(userID, size)
+		if err != nil {
+			zap.L().Error("mysql.GetUserRecentPosts failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+			continue
+		}
+		if err := redis.SetUserPosts(userID, posts); err != nil {
+			zap.L().Error("redis.SetUserPosts failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		}
+	}
+}
+
+// mergeIntoTimeline 将用户的帖子合并到粉丝的时间线，失败时只记录日志
+func mergeIntoTimeline(userID int64, authorIDs []int64) {
+	if len(authorIDs) == 0 {
+		return
+	}
+	ensureUserPosts(authorIDs)
+	if err := redis.MergeIntoTimeline(userID, authorIDs, feedConfig().TimelineSize); err != nil {
+		zap.L().Error("redis.MergeIntoTimeline failed",
+			zap.Int64("user_id", userID),
+			zap.Int64s("author_ids", authorIDs),
+			zap.Error(err))
+	}
+}
+
+// Follow 关注用户
+func Follow(userID, followeeID int64) error {
+	if userID == followeeID {
+		return ErrorCannotFollowSelf
+	}
+	if _, err := mysql.GetUserById(followeeID); err != nil {
+		return err
+	}
+	added, err := mysql.Follow(userID, followeeID)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return nil
+	}
+
+	// 粉丝数量达到阈值时标记为大V
+	count, err := mysql.GetFollowerCount(followeeID)
+	if err != nil {
+		return err
+	}
+	if count >= feedConfig().BigAccountFollowers {
+		if err := redis.AddBigAccount(followeeID); err != nil {
+			zap.L().Error("redis.AddBigAccount failed",
+				zap.Int64("user_id", followeeID),
+				zap.Error(err))
+		}
+	}
+
+	big, err := redis.IsBigAccount(followeeID)
+	if err != nil {
+		zap.L().Error("redis.IsBigAccount failed",
+			zap.Int64("user_id", followeeID),
+			zap.Error(err))
+		return nil
+	}
+	if big {
+		// 读取时合并，只需要让合并缓存失效
+		if err := redis.DeleteFeedCache(userID); err != nil {
+			zap.L().Error("redis.DeleteFeedCache failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		}
+		return nil
+	}
+	// 补齐之前的帖子
+	mergeIntoTimeline(userID, []int64{followeeID})
+	return nil
+}
+
+// Unfollow 取消关注
+func Unfollow(userID, followeeID int64) error {
+	removed, err := mysql.Unfollow(userID, followeeID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return nil
+	}
+	ensureUserPosts([]int64{followeeID})
+	if err := redis.RemoveFromTimeline(userID, followeeID); err != nil {
+		zap.L().Error("redis.RemoveFromTimeline failed",
+			zap.Int64("user_id", userID),
+			zap.Int64("followee_id", followeeID),
+			zap.Error(err))
+	}
+	return nil
+}
+
+// GetFollowCounts 查询用户的粉丝数量和关注数量
+func GetFollowCounts(userID int64) (followers, following int64, err error) {
+	if followers, err = mysql.GetFollowerCount(userID); err != nil {
+		return
+	}
+	following, err = mysql.GetFollowingCount(userID)
+	return
+}
+
+// GetFollowerList 分页查询用户的粉丝
+func GetFollowerList(userID int64, p *models.ParamFollowList) (*models.ApiFollowListRes, error) {
+	return getFollowList(userID, p, mysql.GetFollowerCount, mysql.GetFollowerList)
+}
+
+// GetFollowingList 分页查询用户关注的人
+func GetFollowingList(userID int64, p *models.ParamFollowList) (*models.ApiFollowListRes, error) {
+	return getFollowList(userID, p, mysql.GetFollowingCount, mysql.GetFollowingList)
+}
+
+func getFollowList(userID int64, p *models.ParamFollowList,
+	count func(int64) (int64, error),
+	list func(int64, int64, int64) ([]*models.ApiFollowUser, error)) (*models.ApiFollowListRes, error) {
+	if _, err := mysql.GetUserById(userID); err != nil {
+		return nil, err
+	}
+	total, err := count(userID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := list(userID, p.Page, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		user.Avatar = (&models.User{Avatar: user.Avatar}).GetAvatarURL()
+	}
+	return &models.ApiFollowListRes{
+		Page: models.Page{
+			Total: total,
+			Page:  p.Page,
+			Size:  p.Size,
+		},
+		List: users,
+	}, nil
+}
+
+// GetFollowingFeed 分页获取关注的人发布的帖子（按发帖时间从新到旧）
+func GetFollowingFeed(userID int64, p *models.ParamFeed) (*models.ApiPostDetailRes, error) {
+	data := &models.ApiPostDetailRes{
+		Page: models.Page{
+			Page: p.Page,
+			Size: p.Size,
+		},
+		List: make([]*models.ApiPostDetail, 0),
+	}
+	followees, err := mysql.GetFolloweeIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(followees) == 0 {
+		return data, nil
+	}
+
+	bigAccounts, err := redis.FilterBigAccounts(followees)
+	if err != nil {
+		return nil, err
+	}
+	// 时间线不存在时（redis 数据丢失）从关注的普通用户的帖子重建
+	exists, err := redis.TimelineExists(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		big := make(map[int64]bool, len(bigAccounts))
+		for _, id := range bigAccounts {
+			big[id] = true
+		}
+		normal := make([]int64, 0, len(followees))
+		for _, id := range followees {
+			if !big[id] {
+				normal = append(normal, id)
+			}
+		}
+		mergeIntoTimeline(userID, normal)
+	}
+	ensureUserPosts(bigAccounts)
+
+	ids, total, err := redis.GetFeedPostIds(userID, bigAccounts, p.Page, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	data.Page.Total = total
+	if len(ids) == 0 {
+		return data, nil
+	}
+	list, invalidIds, err := getPostDetailsByIds(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	data.List = list
+
+	// 清理时间线中已删除的帖子
+	if len(invalidIds) > 0 {
+		go func() {
+			if err := redis.RemoveFeedPosts(userID, invalidIds); err != nil {
+				zap.L().Error("redis.RemoveFeedPosts failed",
+					zap.Int64("user_id", userID),
+					zap.Strings("invalid_ids", invalidIds),
+					zap.Error(err))
+			}
+		}()
+	}
+	return data, nil
+}
+
+// getPostDetailsByIds 按 ids 的顺序查询帖子详情，返回 mysql 中不存在（已删除或隐藏）的帖子id
+func getPostDetailsByIds(ids []string, viewerID int64) (list []*models.ApiPostDetail, invalidIds []string, err error) {
+	posts, err := mysql.GetPostListByIds(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[string]bool, len(posts))
+	for _, post := range posts {
+		existing[strconv.FormatInt(post.PostID, 10)] = true
+	}
+	for _, id := range ids {
+		if !existing[id] {
+			invalidIds = append(invalidIds, id)
+		}
+	}
+
+	voteData, err := getPostVoteCounts(getPostIds(posts), viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list = make([]*models.ApiPostDetail, 0, len(posts))
+	users := make(map[int64]*models.User)
+	communities := make(map[int64]*models.CommunityDetail)
+	for idx, post := range posts {
+		user, err := getCachedUser(users, post.AuthorID)
+		if err != nil {
+			zap.L().Error("mysql.GetUserById(post.AuthorID) failed",
+				zap.Int64("author_id", post.AuthorID),
+				zap.Error(err))
+			continue
+		}
+		community, ok := communities[post.CommunityID]
+		if !ok {
+			community, err = mysql.GetCommunityDetailById(post.CommunityID)
+			if err != nil {
+				zap.L().Error("mysql.GetCommunityDetailById(post.CommunityID) failed",
+					zap.Int64("community_id", post.CommunityID),
+					zap.Error(err))
+				continue
+			}
+			communities[post.CommunityID] = community
+		}
+		commentCount, err := mysql.GetCommentCount(post.PostID)
+		if err != nil {
+			zap.L().Error("mysql.GetCommentCount(post.PostID) failed",
+				zap.Int64("post_id", post.PostID),
+				zap.Error(err))
+			commentCount = 0
+		}
+		list = append(list, &models.ApiPostDetail{
+			AuthorName:      user.UserName,
+			AuthorAvatar:    user.GetAvatarURL(),
+			VoteNum:         voteData[idx].UpVotes,
+			VoteCount:       *voteData[idx],
+			CommentCount:    commentCount,
+			Post:            post,
+			CommunityDetail: community,
+		})
+	}
+	return list, invalidIds, nil
+}
+
+// rebuildFeeds 重建大V集合及所有用户的关注动态时间线
+func rebuildFeeds(batchSize int64) (count int, err error) {
+	bigAccounts, err := mysql.GetUserIDsByFollowerCount(feedConfig().BigAccountFollowers)
+	if err != nil {
+		return 0, err
+	}
+	if err := redis.SetBigAccounts(bigAccounts); err != nil {
+		return 0, err
+	}
+	big := make(map[int64]bool, len(bigAccounts))
+	for _, id := range bigAccounts {
+		big[id] = true
+	}
+
+	var lastID int64
+	for {
+		follows, err := mysql.GetFollowsAfterID(lastID, batchSize)
+		if err != nil {
+			zap.L().Error("mysql.GetFollowsAfterID failed",
+				zap.Int64("last_id", lastID),
+				zap.Error(err))
+			return count, err
+		}
+		if len(follows) == 0 {
+			return count, nil
+		}
+		// 同一批中按粉丝合并
+		followees := make(map[int64][]int64)
+		for _, f := range follows {
+			if !big[f.FolloweeID] {
+				followees[f.FollowerID] = append(followees[f.FollowerID], f.FolloweeID)
+			}
+		}
+		for userID, authorIDs := range followees {
+			mergeIntoTimeline(userID, authorIDs)
+		}
+		count += len(follows)
+		lastID = follows[len(follows)-1].ID
+	}
+}
+
+// removeUserPost 帖子删除或隐藏后从作者的帖子 ZSet 中删除，失败时只记录日志
+// 粉丝时间线中的帖子在读取时清理
+func removeUserPost(authorID, postID int64) {
+	if err := redis.RemoveUserPost(authorID, postID); err != nil {
+		zap.L().Error("redis.RemoveUserPost failed",
+			zap.Int64("author_id", authorID),
+			zap.Int64("post_id", postID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	mysql "go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"go_community/pkg/eventbus"
+	"go_community/pkg/mention"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// saveMentions 解析内容中 @ 的用户名并保存提及记录，不存在的用户名按普通文本处理
+// 发布或修改后调用，只通知新增的被提及用户；失败时只记录日志，不影响内容的保存
+func saveMentions(targetType int8, targetID, postID, authorID int64, content string) {
+	names := mention.Parse(content)
+	mentions := make([]*models.Mention, 0, len(names))
+	if len(names) > 0 {
+		users, err := mysql.GetUsersByNames(names)
+		if err != nil {
+			zap.L().Error("mysql.GetUsersByNames failed",
+				zap.Strings("names", names),
+				zap.Error(err))
+			return
+		}
+		// 用户名比较不区分大小写（与 mysql 的排序规则一致），保存内容中的写法
+		userIDs := make(map[string]int64, len(users))
+		for _, user := range users {
+			userIDs[strings.ToLower(user.UserName)] = user.UserID
+		}
+		for _, name := range names {
+			if userID, ok := userIDs[strings.ToLower(name)]; ok {
+				mentions = append(mentions, &models.Mention{
+					TargetType: targetType,
+					TargetID:   targetID,
+					UserID:     userID,
+					UserName:   name,
+				})
+				delete(userIDs, strings.ToLower(name))
+			}
+		}
+	}
+
+	added, err := mysql.SaveMentions(targetType, targetID, mentions)
+	if err != nil {
+		zap.L().Error("mysql.SaveMentions failed",
+			zap.Int8("target_type", targetType),
+			zap.Int64("target_id", targetID),
+			zap.Error(err))
+		return
+	}
+	if len(added) > 0 {
+		eventbus.Publish(models.TopicUserMentioned, &models.UserMentionedEvent{
+			TargetType: targetType,
+			TargetID:   targetID,
+			PostID:     postID,
+			AuthorID:   authorID,
+			UserIDs:    added,
+			Content:    content,
+		})
+	}
+}
+
+// getMentions 批量查询帖子或评论中 @ 的用户，失败时只记录日志并返回空结果
+func getMentions(targetType int8, targetIDs []int64) map[int64][]*models.ApiMention {
+	data, err := mysql.GetMentions(targetType, targetIDs)
+	if err != nil {
+		zap.L().Error("mysql.GetMentions failed",
+			zap.Int8("target_type", targetType),
+			zap.Error(err))
+		return map[int64][]*models.ApiMention{}
+	}
+	for _, mentions := range data {
+		for _, m := range mentions {
+			m.Avatar = (&models.User{Avatar: m.Avatar}).GetAvatarURL()
+		}
+	}
+	return data
+}
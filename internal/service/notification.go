@@ -0,0 +1,282 @@
+package service
+
+import (
+	"go_community/global"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"go_community/pkg/eventbus"
+	"go_community/pkg/snowflake"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+/*
+	通知
+	1.评论、@、投票等业务逻辑只向事件总线发布事件，由这里订阅事件生成通知
+	2.通知保存在 mysql，redis 中缓存每个用户的未读数量，缓存不存在时从 mysql 统计
+	3.点赞里程碑在 redis 中记录已通知过的值，同一个值只通知一次
+*/
+
+const notificationContentLength = 100 // 通知中内容摘要的最大长度（字符数）
+
+// 配置文件中未设置时使用的默认值
+var defaultVoteMilestones = []int64{10, 50, 100, 500, 1000}
+
+// StartNotifier 订阅事件总线中需要生成通知的事件
+func StartNotifier() {
+	eventbus.Subscribe(models.TopicCommentCreated, onCommentCreated)
+	eventbus.Subscribe(models.TopicUserMentioned, onUserMentioned)
+	eventbus.Subscribe(models.TopicVoted, onVoted)
+}
+
+// voteMilestones 读取点赞里程碑配置
+func voteMilestones() []int64 {
+	if milestones := global.Conf.Notification.Milestones; len(milestones) > 0 {
+		return milestones
+	}
+	return defaultVoteMilestones
+}
+
+// onCommentCreated 帖子作者收到评论通知，被回复人收到回复通知
+func onCommentCreated(_ string, payload interface{}) {
+	e, ok := payload.(*models.CommentCreatedEvent)
+	if !ok {
+		return
+	}
+	notifications := make([]*models.Notification, 0, 2)
+	newNotification := func(userID int64, typ int8) *models.Notification {
+		return &models.Notification{
+			UserID:     userID,
+			ActorID:    e.AuthorID,
+			Type:       typ,
+			TargetType: models.VoteTargetComment,
+			TargetID:   e.CommentID,
+			PostID:     e.PostID,
+			Content:    e.Content,
+		}
+	}
+	if e.ReplyToUID != 0 && e.ReplyToUID != e.AuthorID {
+		notifications = append(notifications, newNotification(e.ReplyToUID, models.NotificationTypeReply))
+	}
+	// 回复的就是帖子作者时只发送回复通知
+	if e.PostAuthorID != e.AuthorID && e.PostAuthorID != e.ReplyToUID {
+		notifications = append(notifications, newNotification(e.PostAuthorID, models.NotificationTypeComment))
+	}
+	createNotifications(notifications)
+}
+
+// onUserMentioned 被 @ 的用户收到提及通知
+func onUserMentioned(_ string, payload interface{}) {
+	e, ok := payload.(*models.UserMentionedEvent)
+	if !ok {
+		return
+	}
+	notifications := make([]*models.Notification, 0, len(e.UserIDs))
+	for _, userID := range e.UserIDs {
+		if userID == e.AuthorID {
+			continue
+		}
+		notifications = append(notifications, &models.Notification{
+			UserID:     userID,
+			ActorID:    e.AuthorID,
+			Type:       models.NotificationTypeMention,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			PostID:     e.PostID,
+			Content:    e.Content,
+		})
+	}
+	createNotifications(notifications)
+}
+
+// onVoted 赞成票数达到里程碑时通知作者
+func onVoted(_ string, payload interface{}) {
+	e, ok := payload.(*models.VotedEvent)
+	if !ok || e.Direction != 1 {
+		return
+	}
+	reached := false
+	for _, milestone := range voteMilestones() {
+		if e.UpVotes == milestone {
+			reached = true
+			break
+		}
+	}
+	if !reached {
+		return
+	}
+
+	n := &models.Notification{
+		Type:       models.NotificationTypeMilestone,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+	}
+	kind := guardKindPost
+	switch e.TargetType {
+	case TypePost:
+		post, err := mysql.GetPostById(e.TargetID)
+		if err != nil {
+			zap.L().Error("mysql.GetPostById failed",
+				zap.Int64("post_id", e.TargetID),
+				zap.Error(err))
+			return
+		}
+		n.UserID, n.PostID, n.Content = post.AuthorID, post.PostID, post.Title
+	case TypeComment:
+		comment, err := mysql.GetCommentById(e.TargetID)
+		if err != nil {
+			zap.L().Error("mysql.GetCommentById failed",
+				zap.Int64("comment_id", e.TargetID),
+				zap.Error(err))
+			return
+		}
+		n.UserID, n.PostID, n.Content = comment.AuthorID, comment.PostID, comment.Content
+		kind = guardKindComment
+	default:
+		return
+	}
+
+	// 撤销后重新投票会再次达到同一个里程碑，只通知一次
+	added, err := redis.AddVoteMilestone(kind, e.TargetID, e.UpVotes)
+	if err != nil {
+		zap.L().Error("redis.AddVoteMilestone failed",
+			zap.Int64("target_id", e.TargetID),
+			zap.Int64("milestone", e.UpVotes),
+			zap.Error(err))
+		return
+	}
+	if added {
+		createNotifications([]*models.Notification{n})
+	}
+}
+
+// createNotifications 保存通知并更新未读数量，失败时只记录日志
+func createNotifications(notifications []*models.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, n := range notifications {
+		n.NotificationID = snowflake.GetID()
+		n.CreateTime = now
+		n.Content = truncateRunes(n.Content, notificationContentLength)
+	}
+	if err := mysql.CreateNotifications(notifications); err != nil {
+		zap.L().Error("mysql.CreateNotifications failed", zap.Error(err))
+		return
+	}
+	for _, n := range notifications {
+		if err := redis.IncrUnreadCount(n.UserID, 1); err != nil {
+			zap.L().Error("redis.IncrUnreadCount failed",
+				zap.Int64("user_id", n.UserID),
+				zap.Error(err))
+		}
+		eventbus.Publish(models.TopicNotification, n)
+	}
+}
+
+// truncateRunes 截取字符串的前 n 个字符
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
+// GetNotificationList 分页获取当前用户的通知
+func GetNotificationList(userID int64, p *models.ParamNotificationList) (*models.ApiNotificationListRes, error) {
+	total, err := mysql.GetNotificationCount(userID, p.UnreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := mysql.GetNotificationList(userID, p.UnreadOnly, p.Page, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := GetUnreadNotificationCount(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &models.ApiNotificationListRes{
+		Page: models.Page{
+			Total: total,
+			Page:  p.Page,
+			Size:  p.Size,
+		},
+		Unread: unread,
+		List:   make([]*models.ApiNotification, 0, len(notifications)),
+	}
+	users := make(map[int64]*models.User)
+	for _, n := range notifications {
+		detail := &models.ApiNotification{Notification: n}
+		// actor_id 为 0 的是系统通知
+		if n.ActorID != 0 {
+			if actor, err := getCachedUser(users, n.ActorID); err == nil {
+				detail.ActorName = actor.UserName
+				detail.ActorAvatar = actor.GetAvatarURL()
+			} else {
+				zap.L().Error("mysql.GetUserById(n.ActorID) failed",
+					zap.Int64("actor_id", n.ActorID),
+					zap.Error(err))
+			}
+		}
+		data.List = append(data.List, detail)
+	}
+	return data, nil
+}
+
+// GetUnreadNotificationCount 获取未读通知数量，优先使用 redis 缓存
+func GetUnreadNotificationCount(userID int64) (int64, error) {
+	count, ok, err := redis.GetUnreadCount(userID)
+	if err != nil {
+		zap.L().Error("redis.GetUnreadCount failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+	if ok {
+		return count, nil
+	}
+	count, err = mysql.GetNotificationCount(userID, true)
+	if err != nil {
+		return 0, err
+	}
+	if err := redis.SetUnreadCount(userID, count); err != nil {
+		zap.L().Error("redis.SetUnreadCount failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+	return count, nil
+}
+
+// MarkNotificationRead 将一条通知标记为已读
+func MarkNotificationRead(userID, notificationID int64) error {
+	changed, err := mysql.MarkNotificationRead(userID, notificationID)
+	if err != nil {
+		return err
+	}
+	if changed {
+		if err := redis.IncrUnreadCount(userID, -1); err != nil {
+			zap.L().Error("redis.IncrUnreadCount failed",
+				zap.Int64("user_id", userID),
+				zap.Error(err))
+		}
+		pushUnreadCount(userID)
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead 将当前用户的所有通知标记为已读
+func MarkAllNotificationsRead(userID int64) error {
+	if _, err := mysql.MarkAllNotificationsRead(userID); err != nil {
+		return err
+	}
+	if err := redis.SetUnreadCount(userID, 0); err != nil {
+		return err
+	}
+	pushUnreadCount(userID)
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	mysql "go_community/internal/dao/mysql"
+	"go_community/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// isGlobalModerator 判断用户角色是否为全站版主或管理员
+func isGlobalModerator(role int8) bool {
+	return role == models.RoleModerator || role == models.RoleAdmin
+}
+
+// canModerate 判断用户是否有权限管理（删除）指定社区内他人发布的内容
+// 全站版主/管理员、社区创建者以及该社区的版主拥有该权限
+func canModerate(userID int64, role int8, communityID int64) bool {
+	if isGlobalModerator(role) {
+		return true
+	}
+
+	community, err := mysql.GetCommunityDetailById(communityID)
+	if err != nil {
+		zap.L().Error("mysql.GetCommunityDetailById(communityID) failed",
+			zap.Int64("community_id", communityID),
+			zap.Error(err))
+		return false
+	}
+	if community.OwnerID == userID {
+		return true
+	}
+
+	ok, err := mysql.IsCommunityModerator(communityID, userID)
+	if err != nil {
+		zap.L().Error("mysql.IsCommunityModerator failed",
+			zap.Int64("community_id", communityID),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return false
+	}
+	return ok
+}
+
+// canModerateComment 判断用户是否有权限删除他人的评论（按评论所属帖子的社区判断）
+func canModerateComment(userID int64, role int8, comment *models.Comment) bool {
+	if isGlobalModerator(role) {
+		return true
+	}
+
+	post, err := mysql.GetPostById(comment.PostID)
+	if err != nil {
+		zap.L().Error("mysql.GetPostById(comment.PostID) failed",
+			zap.Int64("post_id", comment.PostID),
+			zap.Error(err))
+		return false
+	}
+	return canModerate(userID, role, post.CommunityID)
+}
+
+// canManageCommunity 判断用户是否有权限管理社区的版主列表（管理员或社区创建者）
+func canManageCommunity(userID int64, role int8, community *models.CommunityDetail) bool {
+	return role == models.RoleAdmin || community.OwnerID == userID
+}
@@ -4,14 +4,28 @@ import (
 	mysql "go_community/internal/dao/mysql"
 	redis "go_community/internal/dao/redis"
 	"go_community/internal/models"
+	"go_community/pkg/eventbus"
 	"go_community/pkg/snowflake"
 	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 // CreatePost 创建帖子
 func CreatePost(p *models.Post) (err error) {
+	// 过滤敏感词
+	reviewWords, err := filterContent(&p.Title, &p.Content)
+	if err != nil {
+		return err
+	}
+
+	// 检查重复内容和发帖频率
+	hash, err := guardPublish(guardKindPost, p.AuthorID, p.Title+"\n"+p.Content)
+	if err != nil {
+		return err
+	}
+
 	// 生成帖子ID
 	p.PostID = snowflake.GetID()
 
@@ -24,11 +38,26 @@ func CreatePost(p *models.Post) (err error) {
 		zap.L().Error("redis.CreatePost failed", zap.Error(err))
 		return err
 	}
+
+	// 更新搜索索引
+	if p.CreateTime.IsZero() {
+		p.CreateTime = time.Now()
+	}
+	indexPost(p)
+	recordPublish(guardKindPost, p.AuthorID, hash)
+	submitReview(models.ReportTargetPost, p.PostID, p.AuthorID, reviewWords)
+	saveMentions(TypePost, p.PostID, p.PostID, p.AuthorID, p.Content)
+	eventbus.Publish(models.TopicPostCreated, &models.PostCreatedEvent{
+		PostID:      p.PostID,
+		AuthorID:    p.AuthorID,
+		CommunityID: p.CommunityID,
+		CreateTime:  p.CreateTime,
+	})
 	return nil
 }
 
-// GetPostById 根据帖子ID查询帖子详情
-func GetPostById(postID int64) (data *models.ApiPostDetail, err error) {
+// GetPostById 根据帖子ID查询帖子详情，viewerID 为当前登录用户（未登录时为 0）
+func GetPostById(postID, viewerID int64) (data *models.ApiPostDetail, err error) {
 	// 查询帖子信息
 	post, err := mysql.GetPostById(postID)
 	if err != nil {
@@ -56,13 +85,15 @@ func GetPostById(postID int64) (data *models.ApiPostDetail, err error) {
 		return nil, err
 	}
 
-	// 获取帖子投票数
-	voteNum, err := redis.GetPostVoteNum(strconv.FormatInt(postID, 10))
+	// 获取帖子投票数据
+	voteCount := &models.VoteCount{}
+	voteData, err := getPostVoteCounts([]string{strconv.FormatInt(postID, 10)}, viewerID)
 	if err != nil {
-		zap.L().Error("redis.GetPostVoteNum failed",
+		zap.L().Error("getPostVoteCounts failed",
 			zap.Int64("post_id", postID),
 			zap.Error(err))
-		voteNum = 0
+	} else {
+		voteCount = voteData[0]
 	}
 
 	// 获取评论数量
@@ -78,16 +109,18 @@ func GetPostById(postID int64) (data *models.ApiPostDetail, err error) {
 	data = &models.ApiPostDetail{
 		AuthorName:      user.UserName,
 		AuthorAvatar:    user.GetAvatarURL(),
-		VoteNum:         voteNum,
+		VoteNum:         voteCount.UpVotes,
 		CommentCount:    commentCount,
+		VoteCount:       *voteCount,
 		Post:            post,
 		CommunityDetail: community,
+		Mentions:        getMentions(TypePost, []int64{postID})[postID],
 	}
 	return
 }
 
 // GetPostList 获取帖子列表
-func GetPostList(page, size int64) (data []*models.ApiPostDetail, err error) {
+func GetPostList(page, size, viewerID int64) (data []*models.ApiPostDetail, err error) {
 	// 查询并组合接口需要的数据
 	// 查询帖子信息
 	posts, err := mysql.GetPostList(page, size)
@@ -95,9 +128,15 @@ func GetPostList(page, size int64) (data []*models.ApiPostDetail, err error) {
 		zap.L().Error("mysql.GetPostList failed", zap.Error(err))
 		return
 	}
+	// 提前查询好每篇帖子的投票数据
+	voteData, err := getPostVoteCounts(getPostIds(posts), viewerID)
+	if err != nil {
+		zap.L().Error("getPostVoteCounts failed", zap.Error(err))
+		return
+	}
 	// 初始化返回数据结构
 	data = make([]*models.ApiPostDetail, 0, len(posts))
-	for _, post := range posts {
+	for idx, post := range posts {
 		// 根据作者id查询作者信息
 		user, err := mysql.GetUserById(post.AuthorID)
 		if err != nil {
@@ -117,6 +156,8 @@ func GetPostList(page, size int64) (data []*models.ApiPostDetail, err error) {
 		// 接口数据拼接
 		postDetail := &models.ApiPostDetail{
 			AuthorName:      user.UserName,
+			VoteNum:         voteData[idx].UpVotes,
+			VoteCount:       *voteData[idx],
 			Post:            post,
 			CommunityDetail: community,
 		}
@@ -126,7 +167,7 @@ func GetPostList(page, size int64) (data []*models.ApiPostDetail, err error) {
 }
 
 // GetPostList2 获取帖子列表（按帖子的创建时间或者分数排序）
-func GetPostList2(p *models.ParamPostList) (data *models.ApiPostDetailRes, err error) {
+func GetPostList2(p *models.ParamPostList, viewerID int64) (data *models.ApiPostDetailRes, err error) {
 	// 初始化返回数据结构
 	data = &models.ApiPostDetailRes{
 		Page: models.Page{},
@@ -194,8 +235,8 @@ func GetPostList2(p *models.ParamPostList) (data *models.ApiPostDetailRes, err e
 		}
 	}
 
-	// 提前查询好每篇帖子的投票数
-	voteData, err := redis.GetPostVoteData(ids)
+	// 提前查询好每篇帖子的投票数据（按 mysql 返回的帖子顺序）
+	voteData, err := getPostVoteCounts(getPostIds(posts), viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +275,8 @@ func GetPostList2(p *models.ParamPostList) (data *models.ApiPostDetailRes, err e
 		postDetail := &models.ApiPostDetail{
 			AuthorName:      user.UserName,
 			AuthorAvatar:    user.GetAvatarURL(),
-			VoteNum:         voteData[idx],
+			VoteNum:         voteData[idx].UpVotes,
+			VoteCount:       *voteData[idx],
 			CommentCount:    commentCount,
 			Post:            post,
 			CommunityDetail: community,
@@ -245,7 +287,7 @@ func GetPostList2(p *models.ParamPostList) (data *models.ApiPostDetailRes, err e
 }
 
 // GetCommunityPostList 根据社区id去查询帖子列表
-func GetCommunityPostList(p *models.ParamPostList) (data *models.ApiPostDetailRes, err error) {
+func GetCommunityPostList(p *models.ParamPostList, viewerID int64) (data *models.ApiPostDetailRes, err error) {
 	// 初始化返回数据结构
 	data = &models.ApiPostDetailRes{
 		Page: models.Page{},
@@ -280,8 +322,8 @@ func GetCommunityPostList(p *models.ParamPostList) (data *models.ApiPostDetailRe
 	}
 	zap.L().Debug("GetCommunityPostList", zap.Any("posts: ", posts))
 
-	// 提前查询好每篇帖子的投票数
-	voteData, err := redis.GetPostVoteData(ids)
+	// 提前查询好每篇帖子的投票数据（按 mysql 返回的帖子顺序）
+	voteData, err := getPostVoteCounts(getPostIds(posts), viewerID)
 	if err != nil {
 		return
 	}
@@ -325,7 +367,8 @@ func GetCommunityPostList(p *models.ParamPostList) (data *models.ApiPostDetailRe
 		postDetail := &models.ApiPostDetail{
 			AuthorName:      user.UserName,
 			AuthorAvatar:    user.GetAvatarURL(),
-			VoteNum:         voteData[idx],
+			VoteNum:         voteData[idx].UpVotes,
+			VoteCount:       *voteData[idx],
 			CommentCount:    commentCount,
 			Post:            post,
 			CommunityDetail: community,
@@ -336,14 +379,14 @@ func GetCommunityPostList(p *models.ParamPostList) (data *models.ApiPostDetailRe
 }
 
 // GetPostListNew 将两个查询帖子列表的逻辑合二为一
-func GetPostListNew(p *models.ParamPostList) (data *models.ApiPostDetailRes, err error) {
+func GetPostListNew(p *models.ParamPostList, viewerID int64) (data *models.ApiPostDetailRes, err error) {
 	// 根据请求参数的不同，执行不同的业务逻辑
 	if p.CommunityID == 0 {
 		// 查询所有帖子
-		data, err = GetPostList2(p)
+		data, err = GetPostList2(p, viewerID)
 	} else {
 		// 根据社区id查询
-		data, err = GetCommunityPostList(p)
+		data, err = GetCommunityPostList(p, viewerID)
 	}
 
 	if err != nil {
@@ -354,74 +397,6 @@ func GetPostListNew(p *models.ParamPostList) (data *models.ApiPostDetailRes, err
 	return data, nil
 }
 
-// PostSearch 搜索帖子
-func PostSearch(p *models.ParamPostList) (data *models.ApiPostDetailRes, err error) {
-	// 初始化返回数据结构
-	data = &models.ApiPostDetailRes{
-		Page: models.Page{},
-		List: make([]*models.ApiPostDetail, 0),
-	}
-
-	// 根据搜索条件去mysql查询符合条件的帖子列表总数
-	total, err := mysql.GetPostListTotalCount(p)
-	if err != nil {
-		return nil, err
-	}
-	data.Page.Total = total
-	data.Page.Size = p.Size
-	data.Page.Page = p.Page
-
-	// 根据搜索条件去mysql分页查询符合条件的帖子列表
-	posts, err := mysql.GetPostListByKeywords(p)
-	if err != nil {
-		return nil, err
-	}
-	if len(posts) == 0 {
-		return data, nil
-	}
-	// 查询出来的帖子id列表传入到redis接口获取帖子的投票数
-	ids := make([]string, 0, len(posts))
-	for _, post := range posts {
-		ids = append(ids, strconv.Itoa(int(post.PostID)))
-	}
-	voteData, err := redis.GetPostVoteData(ids)
-	if err != nil {
-		return nil, err
-	}
-
-	// 组合数据
-	for idx, post := range posts {
-		// 根据作者id查询作者信息
-		user, err := mysql.GetUserById(post.AuthorID)
-		if err != nil {
-			zap.L().Error("mysql.GetUserById(post.AuthorID) failed",
-				zap.Int64("author_id", post.AuthorID),
-				zap.Error(err))
-			continue
-		}
-
-		// 根据社区id查询社区详细信息
-		community, err := mysql.GetCommunityDetailById(post.CommunityID)
-		if err != nil {
-			zap.L().Error("mysql.GetCommunityDetailById(post.CommunityID) failed",
-				zap.Int64("community_id", post.CommunityID),
-				zap.Error(err))
-			continue
-		}
-
-		// 接口数据拼接
-		postDetail := &models.ApiPostDetail{
-			AuthorName:      user.UserName,
-			AuthorAvatar:    user.GetAvatarURL(),
-			VoteNum:         voteData[idx],
-			Post:            post,
-			CommunityDetail: community,
-		}
-		data.List = append(data.List, postDetail)
-	}
-	return data, nil
-}
-
 // UpdatePost 编辑帖子
 func UpdatePost(userID int64, p *models.ParamUpdatePost) (err error) {
 	// 判断帖子是否存在
@@ -435,12 +410,27 @@ func UpdatePost(userID int64, p *models.ParamUpdatePost) (err error) {
 		return mysql.ErrorNoPermission
 	}
 
+	// 过滤敏感词
+	reviewWords, err := filterContent(&p.Title, &p.Content)
+	if err != nil {
+		return err
+	}
+
 	// 更新帖子
-	return mysql.UpdatePost(p.PostID, p.Title, p.Content)
+	if err = mysql.UpdatePost(p.PostID, p.Title, p.Content); err != nil {
+		return err
+	}
+
+	// 更新搜索索引
+	post.Title, post.Content = p.Title, p.Content
+	indexPost(post)
+	submitReview(models.ReportTargetPost, p.PostID, userID, reviewWords)
+	saveMentions(TypePost, p.PostID, p.PostID, userID, p.Content)
+	return nil
 }
 
 // GetUserPostList 获取用户的帖子列表
-func GetUserPostList(userID, page, size int64) (data *models.ApiPostDetailRes, err error) {
+func GetUserPostList(userID, page, size, viewerID int64) (data *models.ApiPostDetailRes, err error) {
 	// 初始化返回数据结构
 	data = &models.ApiPostDetailRes{
 		Page: models.Page{},
@@ -465,12 +455,8 @@ func GetUserPostList(userID, page, size int64) (data *models.ApiPostDetailRes, e
 		return data, nil
 	}
 
-	// 提前查询好每篇帖子的投票数
-	ids := make([]string, 0, len(posts))
-	for _, post := range posts {
-		ids = append(ids, strconv.FormatInt(post.PostID, 10))
-	}
-	voteData, err := redis.GetPostVoteData(ids)
+	// 提前查询好每篇帖子的投票数据
+	voteData, err := getPostVoteCounts(getPostIds(posts), viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -508,7 +494,8 @@ func GetUserPostList(userID, page, size int64) (data *models.ApiPostDetailRes, e
 		postDetail := &models.ApiPostDetail{
 			AuthorName:      user.UserName,
 			AuthorAvatar:    user.GetAvatarURL(),
-			VoteNum:         voteData[idx],
+			VoteNum:         voteData[idx].UpVotes,
+			VoteCount:       *voteData[idx],
 			CommentCount:    commentCount,
 			Post:            post,
 			CommunityDetail: community,
@@ -519,8 +506,8 @@ func GetUserPostList(userID, page, size int64) (data *models.ApiPostDetailRes, e
 	return data, nil
 }
 
-// DeletePost 删除帖子
-func DeletePost(userID, postID int64) error {
+// DeletePost 删除帖子（作者本人、版主/管理员或帖子所在社区的版主）
+func DeletePost(userID int64, role int8, postID int64) error {
 	// 1. 检查帖子是否存在
 	post, err := mysql.GetPostById(postID)
 	if err != nil {
@@ -530,8 +517,8 @@ func DeletePost(userID, postID int64) error {
 		return mysql.ErrorInvalidID
 	}
 
-	// 2. 检查是否有权限删除（是否是帖子作者）
-	if post.AuthorID != userID {
+	// 2. 检查是否有权限删除（是否是帖子作者或有管理权限）
+	if post.AuthorID != userID && !canModerate(userID, role, post.CommunityID) {
 		zap.L().Error("no permission to delete post",
 			zap.Int64("post_id", postID),
 			zap.Int64("user_id", userID),
@@ -539,7 +526,13 @@ func DeletePost(userID, postID int64) error {
 		return mysql.ErrorNoPermission
 	}
 
-	// 3. 开启事务
+	// 3. 删除帖子及其评论
+	return removePost(postID, post.AuthorID)
+}
+
+// removePost 软删除帖子及其评论，并删除 redis 数据和搜索索引
+func removePost(postID, authorID int64) (err error) {
+	// 1. 开启事务
 	tx, err := mysql.GetDB().Begin()
 	if err != nil {
 		return err
@@ -555,26 +548,39 @@ func DeletePost(userID, postID int64) error {
 		}
 	}()
 
-	// 4. 获取需要删除的评论ID列表
+	// 2. 获取需要删除的评论ID列表
 	commentIDs, err := mysql.GetPostCommentIDs(tx, postID)
 	if err != nil {
 		return err
 	}
 
-	// 5. 删除帖子(软删除)
+	// 3. 删除帖子(软删除)
 	if err = mysql.DeletePostWithTx(tx, postID); err != nil {
 		return err
 	}
 
-	// 6. 删除帖子下的所有评论(软删除)
+	// 4. 删除帖子下的所有评论(软删除)
 	if err = mysql.DeletePostCommentsWithTx(tx, postID); err != nil {
 		return err
 	}
 
-	// 7. 删除Redis中的相关数据(包括帖子和评论的数据)
+	// 5. 删除Redis中的相关数据(包括帖子和评论的数据)
 	if err = redis.DeletePostData(strconv.FormatInt(postID, 10), commentIDs); err != nil {
 		return err
 	}
 
+	// 6. 删除搜索索引及作者的帖子 ZSet（关注动态）
+	unindexPost(postID)
+	removeUserPost(authorID, postID)
+
 	return nil
 }
+
+// getPostIds 按帖子列表的顺序返回帖子id
+func getPostIds(posts []*models.Post) []string {
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, strconv.FormatInt(post.PostID, 10))
+	}
+	return ids
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"go_community/global"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"go_community/pkg/eventbus"
+	"go_community/pkg/hub"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+/*
+	实时推送
+	1.客户端通过 SSE 订阅个人通知或帖子的新评论，每个连接在本地 hub 中订阅对应的 redis 频道
+	2.推送消息统一发布到 redis 频道，每个实例只订阅本地有连接的频道，再由 hub 分发给本地的连接，
+	  因此多个实例部署在负载均衡之后时，连接在任意实例上都能收到消息
+	3.推送的数据来自事件总线（新通知、新评论），业务逻辑不直接调用推送
+	4.浏览器的 EventSource 不能设置请求头，先用 access token 换取短期的一次性票据，再通过 URI 参数携带票据建立连接，
+	  避免 access token 出现在 URI 和访问日志中
+*/
+
+// 配置文件中未设置时使用的默认值
+const (
+	defaultPushHeartbeat   = 30 * time.Second
+	defaultPushMaxLifetime = 30 * time.Minute
+	defaultPushBufferSize  = 16
+	streamTicketExpire     = 30 * time.Second // 推送连接票据的有效期
+)
+
+var ErrorInvalidStreamTicket = errors.New("票据无效或已过期")
+
+var pushHub *hub.Hub
+
+// pushConfig 读取实时推送配置，未设置的参数使用默认值
+func pushConfig() global.PushConfig {
+	cfg := global.Conf.Push
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = defaultPushHeartbeat
+	}
+	if cfg.MaxLifetime <= 0 {
+		cfg.MaxLifetime = defaultPushMaxLifetime
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultPushBufferSize
+	}
+	return cfg
+}
+
+// PushTimeouts 推送连接的心跳间隔和最长时间
+func PushTimeouts() (heartbeat, maxLifetime time.Duration) {
+	cfg := pushConfig()
+	return cfg.Heartbeat, cfg.MaxLifetime
+}
+
+// StartPusher 订阅 redis 推送频道并转发给本地连接，同时订阅事件总线中需要推送的事件
+func StartPusher() {
+	subscriber := redis.NewPushSubscriber()
+	pushHub = hub.New(pushConfig().BufferSize, subscriber.Subscribe, subscriber.Unsubscribe)
+	go subscriber.Receive(func(channel string, payload []byte) {
+		pushHub.Broadcast(channel, payload)
+	})
+
+	eventbus.Subscribe(models.TopicNotification, pushNotification)
+	eventbus.Subscribe(models.TopicCommentCreated, pushComment)
+}
+
+// CreateStreamTicket 为当前会话签发推送连接的一次性票据，返回票据及有效期
+func CreateStreamTicket(userID int64, role int8, sessionID string) (string, time.Duration, error) {
+	tokenVersion, err := getTokenVersion(userID)
+	if err != nil {
+		return "", 0, err
+	}
+	ticket, ticketHash, err := newAccountToken()
+	if err != nil {
+		return "", 0, err
+	}
+	if err := redis.SaveStreamTicket(ticketHash, userID, role, sessionID, tokenVersion, streamTicketExpire); err != nil {
+		return "", 0, err
+	}
+	return ticket, streamTicketExpire, nil
+}
+
+// RedeemStreamTicket 使用推送连接的票据，返回签发票据时的用户id、角色及会话id
+// 与 access token 相同，会话已注销或修改过密码时票据失效
+func RedeemStreamTicket(ticket string) (userID int64, role int8, sessionID string, err error) {
+	userID, role, sessionID, tokenVersion, err := redis.ConsumeStreamTicket(hashAccountToken(ticket))
+	if err != nil {
+		if err == redis.ErrorTokenNotExist {
+			return 0, 0, "", ErrorInvalidStreamTicket
+		}
+		return 0, 0, "", err
+	}
+	if err := CheckSession(userID, sessionID, tokenVersion); err != nil {
+		if err == ErrorTokenRevoked {
+			return 0, 0, "", ErrorInvalidStreamTicket
+		}
+		return 0, 0, "", err
+	}
+	return userID, role, sessionID, nil
+}
+
+// SubscribeNotifications 订阅当前用户的通知
+func SubscribeNotifications(userID int64) (*hub.Subscription, error) {
+	return pushHub.Subscribe(redis.UserPushChannel(userID))
+}
+
+// SubscribePostComments 订阅帖子的新评论
+func SubscribePostComments(postID int64) (*hub.Subscription, error) {
+	// 帖子不存在时为 mysql.ErrorInvalidID，其他错误原样返回
+	if _, err := mysql.GetPostById(postID); err != nil {
+		return nil, err
+	}
+	return pushHub.Subscribe(redis.PostPushChannel(postID))
+}
+
+// publishPush 将推送消息发布到 redis 频道，失败时只记录日志
+func publishPush(channel, event string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		zap.L().Error("json.Marshal push data failed",
+			zap.String("event", event),
+			zap.Error(err))
+		return
+	}
+	msg, err := json.Marshal(&models.PushMessage{
+		Event: event,
+		Data:  raw,
+	})
+	if err != nil {
+		zap.L().Error("json.Marshal push message failed",
+			zap.String("event", event),
+			zap.Error(err))
+		return
+	}
+	if err := redis.Publish(channel, msg); err != nil {
+		zap.L().Error("redis.Publish failed",
+			zap.String("channel", channel),
+			zap.Error(err))
+	}
+}
+
+// pushNotification 推送新通知及最新的未读数量
+func pushNotification(_ string, payload interface{}) {
+	n, ok := payload.(*models.Notification)
+	if !ok {
+		return
+	}
+	detail := &models.ApiNotification{Notification: n}
+	if n.ActorID != 0 {
+		if actor, err := mysql.GetUserById(n.ActorID); err == nil {
+			detail.ActorName = actor.UserName
+			detail.ActorAvatar = actor.GetAvatarURL()
+		}
+	}
+	channel := redis.UserPushChannel(n.UserID)
+	publishPush(channel, models.PushEventNotification, detail)
+	pushUnreadCount(n.UserID)
+}
+
+// pushUnreadCount 推送最新的未读通知数量，用于同步同一用户的多个客户端
+func pushUnreadCount(userID int64) {
+	count, err := GetUnreadNotificationCount(userID)
+	if err != nil {
+		zap.L().Error("GetUnreadNotificationCount failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+		return
+	}
+	publishPush(redis.UserPushChannel(userID), models.PushEventUnread, &models.ApiUnreadCount{Unread: count})
+}
+
+// pushComment 推送帖子的新评论/回复
+func pushComment(_ string, payload interface{}) {
+	e, ok := payload.(*models.CommentCreatedEvent)
+	if !ok {
+		return
+	}
+	detail, err := GetCommentById(e.CommentID, 0)
+	if err != nil {
+		zap.L().Error("GetCommentById failed",
+			zap.Int64("comment_id", e.CommentID),
+			zap.Error(err))
+		return
+	}
+	publishPush(redis.PostPushChannel(e.PostID), models.PushEventComment, detail)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"go_community/global"
+	redis "go_community/internal/dao/redis"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRankingRefreshInterval  = 5 * time.Minute // 默认重力衰减分数刷新间隔
+	defaultRankingRefreshBatchSize = 500             // 默认每批刷新数量
+)
+
+// StartRankingRefresher 启动后台排序分数刷新任务
+// 重力衰减算法的分数随时间变化，需要定时重新计算
+// 每个实例都会启动，通过 redis 中的锁保证同一时间只有一个实例在刷新
+func StartRankingRefresher() {
+	interval := global.Conf.Ranking.RefreshInterval
+	if interval <= 0 {
+		interval = defaultRankingRefreshInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runExclusive(taskRankingRefresh, interval, RefreshRankingScores)
+			<-ticker.C
+		}
+	}()
+}
+
+// RefreshRankingScores 重新计算投票窗口内帖子的重力衰减分数
+func RefreshRankingScores() {
+	count, err := redis.RefreshGravityScores(defaultRankingRefreshBatchSize)
+	if err != nil {
+		zap.L().Error("redis.RefreshGravityScores failed", zap.Error(err))
+		return
+	}
+	zap.L().Debug("refresh ranking scores success", zap.Int("count", count))
+}
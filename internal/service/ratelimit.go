@@ -0,0 +1,25 @@
+package service
+
+import (
+	"go_community/global"
+	redis "go_community/internal/dao/redis"
+	"strconv"
+)
+
+// RateLimit 按限流策略检查请求，登录用户按用户id限流，未登录时按客户端 IP 限流
+// 未启用限流或策略不存在时返回 nil，表示不限流
+func RateLimit(policy string, userID int64, clientIP string) (*redis.RateLimitResult, error) {
+	cfg := global.Conf.RateLimit
+	if !cfg.Enable {
+		return nil, nil
+	}
+	p, ok := cfg.Policies[policy]
+	if !ok || p.Limit <= 0 || p.Period <= 0 {
+		return nil, nil
+	}
+	key := policy + ":ip:" + clientIP
+	if userID != 0 {
+		key = policy + ":user:" + strconv.FormatInt(userID, 10)
+	}
+	return redis.AllowRequest(key, p.Limit, p.Period)
+}
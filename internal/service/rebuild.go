@@ -0,0 +1,124 @@
+package service
+
+import (
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// RebuildRedis 从 mysql 中读取所有正常状态的帖子和评论，分批重建 redis 中的排序数据及关注动态
+// 用于 redis 数据丢失后的冷启动/恢复
+func RebuildRedis(batchSize int64) error {
+	if batchSize <= 0 {
+		batchSize = defaultArchiveBatchSize
+	}
+
+	postCount, err := rebuildPosts(batchSize)
+	if err != nil {
+		return err
+	}
+	commentCount, err := rebuildComments(batchSize)
+	if err != nil {
+		return err
+	}
+	followCount, err := rebuildFeeds(batchSize)
+	if err != nil {
+		return err
+	}
+	zap.L().Info("rebuild redis success",
+		zap.Int("post_count", postCount),
+		zap.Int("comment_count", commentCount),
+		zap.Int("follow_count", followCount))
+	return nil
+}
+
+// rebuildPosts 分批重建帖子的时间/分数/排序 ZSet 及社区 Set
+func rebuildPosts(batchSize int64) (count int, err error) {
+	var lastID int64
+	for {
+		posts, err := mysql.GetPostListAfterID(lastID, batchSize)
+		if err != nil {
+			zap.L().Error("mysql.GetPostListAfterID failed",
+				zap.Int64("last_id", lastID),
+				zap.Error(err))
+			return count, err
+		}
+		if len(posts) == 0 {
+			return count, nil
+		}
+
+		// 已归档的帖子使用归档时的投票数据
+		ids := make([]string, 0, len(posts))
+		for _, post := range posts {
+			ids = append(ids, strconv.FormatInt(post.PostID, 10))
+		}
+		archives, err := mysql.GetVoteArchives(TypePost, ids)
+		if err != nil {
+			return count, err
+		}
+		archived := make(map[int64]*models.VoteArchive, len(archives))
+		for _, a := range archives {
+			archived[a.TargetID] = a
+		}
+
+		if err := redis.RestorePosts(posts, archived); err != nil {
+			zap.L().Error("redis.RestorePosts failed",
+				zap.Int64("last_id", lastID),
+				zap.Error(err))
+			return count, err
+		}
+
+		count += len(posts)
+		lastID = posts[len(posts)-1].PostID
+		zap.L().Info("rebuild posts in progress",
+			zap.Int("count", count),
+			zap.Int64("last_id", lastID))
+	}
+}
+
+// rebuildComments 分批重建评论的时间/净票数 ZSet
+func rebuildComments(batchSize int64) (count int, err error) {
+	var lastID int64
+	for {
+		comments, err := mysql.GetCommentListAfterID(lastID, batchSize)
+		if err != nil {
+			zap.L().Error("mysql.GetCommentListAfterID failed",
+				zap.Int64("last_id", lastID),
+				zap.Error(err))
+			return count, err
+		}
+		if len(comments) == 0 {
+			return count, nil
+		}
+
+		// 已归档的评论使用归档时的投票数据
+		ids := make([]string, 0, len(comments))
+		for _, comment := range comments {
+			ids = append(ids, strconv.FormatInt(comment.CommentID, 10))
+		}
+		archives, err := mysql.GetVoteArchives(TypeComment, ids)
+		if err != nil {
+			return count, err
+		}
+		archived := make(map[int64]*models.VoteArchive, len(archives))
+		for _, a := range archives {
+			archived[a.TargetID] = a
+		}
+
+		if err := redis.RestoreComments(comments, archived); err != nil {
+			zap.L().Error("redis.RestoreComments failed",
+				zap.Int64("last_id", lastID),
+				zap.Error(err))
+			return count, err
+		}
+
+		count += len(comments)
+		lastID = comments[len(comments)-1].CommentID
+		zap.L().Info("rebuild comments in progress",
+			zap.Int("count", count),
+			zap.Int64("last_id", lastID))
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	mysql "go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"go_community/pkg/snowflake"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+/*
+	举报及审核队列
+	1.用户举报帖子、评论或其他用户；命中 review 词库的内容由系统举报（reporter_id 为 0）
+	2.全站版主/管理员从队列中认领举报，认领超过 claimTimeout 未处理的举报可以被其他人重新认领
+	3.认领人处理举报：隐藏、删除（软删除）、驳回或封禁作者，同一对象未处理完的举报一并标记为已处理，
+	  并记录处理人、处理方式和时间
+*/
+
+const claimTimeout = 30 * time.Minute // 认领后未处理的超时时间
+
+var (
+	ErrorReportNotClaimed    = errors.New("请先认领该举报")
+	ErrorUnsupportedAction   = errors.New("该举报对象不支持此处理方式")
+	ErrorCannotReportSelf    = errors.New("不能举报自己")
+	ErrorReportAlreadyClosed = errors.New("举报已处理")
+)
+
+// CreateReport 用户举报帖子、评论或用户
+func CreateReport(userID int64, p *models.ParamReport) error {
+	// 查询被举报内容的作者
+	targetUserID, err := getReportTargetUser(p.TargetType, p.TargetID)
+	if err != nil {
+		return err
+	}
+	if targetUserID == userID {
+		return ErrorCannotReportSelf
+	}
+
+	// 同一对象未处理完时不能重复举报
+	if err := mysql.CheckReportExist(userID, p.TargetType, p.TargetID); err != nil {
+		return err
+	}
+
+	return mysql.CreateReport(&models.Report{
+		ReportID:     snowflake.GetID(),
+		TargetType:   p.TargetType,
+		TargetID:     p.TargetID,
+		TargetUserID: targetUserID,
+		ReporterID:   userID,
+		Reason:       p.Reason,
+		Detail:       p.Detail,
+	})
+}
+
+// getReportTargetUser 查询举报对象的作者（举报用户时为该用户），对象不存在时返回 mysql.ErrorInvalidID
+func getReportTargetUser(targetType int8, targetID int64) (int64, error) {
+	switch targetType {
+	case models.ReportTargetPost:
+		post, err := mysql.GetPostById(targetID)
+		if err != nil {
+			return 0, err
+		}
+		return post.AuthorID, nil
+	case models.ReportTargetComment:
+		comment, err := mysql.GetCommentById(targetID)
+		if err != nil {
+			return 0, err
+		}
+		return comment.AuthorID, nil
+	case models.ReportTargetUser:
+		user, err := mysql.GetUserById(targetID)
+		if err == mysql.ErrorUserNotExist {
+			return 0, mysql.ErrorInvalidID
+		}
+		if err != nil {
+			return 0, err
+		}
+		return user.UserID, nil
+	default:
+		return 0, mysql.ErrorInvalidID
+	}
+}
+
+// GetReportList 分页查询举报列表
+func GetReportList(p *models.ParamReportList) (*models.ApiReportListRes, error) {
+	total, err := mysql.GetReportCount(p.Status, p.TargetType)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := mysql.GetReportList(p.Status, p.TargetType, p.Page, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ApiReportListRes{
+		Page: models.Page{
+			Total: total,
+			Page:  p.Page,
+			Size:  p.Size,
+		},
+		List: reports,
+	}, nil
+}
+
+// ClaimReport 认领举报
+func ClaimReport(userID, reportID int64) error {
+	report, err := mysql.GetReportById(reportID)
+	if err != nil {
+		return err
+	}
+	if report.Status == models.ReportStatusResolved {
+		return ErrorReportAlreadyClosed
+	}
+	return mysql.ClaimReport(reportID, userID, time.Now().Add(-claimTimeout))
+}
+
+// ResolveReport 处理已认领的举报
+func ResolveReport(userID int64, role int8, reportID int64, p *models.ParamResolveReport) error {
+	report, err := mysql.GetReportById(reportID)
+	if err != nil {
+		return err
+	}
+	if report.Status == models.ReportStatusResolved {
+		return ErrorReportAlreadyClosed
+	}
+	if report.Status != models.ReportStatusClaimed || report.HandlerID != userID {
+		return ErrorReportNotClaimed
+	}
+
+	// 执行处理方式
+	switch p.Action {
+	case models.ReportActionHide:
+		err = hideReportTarget(report)
+	case models.ReportActionDelete:
+		err = deleteReportTarget(report)
+	case models.ReportActionBan:
+		err = banReportTarget(role, report)
+	case models.ReportActionDismiss:
+	default:
+		err = ErrorUnsupportedAction
+	}
+	if err != nil {
+		return err
+	}
+
+	// 同一对象未处理完的举报一并标记为已处理
+	count, err := mysql.ResolveReports(report.TargetType, report.TargetID, userID, p.Action, p.Note)
+	if err != nil {
+		return err
+	}
+	zap.L().Info("report resolved",
+		zap.Int64("report_id", reportID),
+		zap.Int64("handler_id", userID),
+		zap.Int8("action", p.Action),
+		zap.Int64("count", count))
+	return nil
+}
+
+// hideReportTarget 隐藏被举报的帖子或评论，内容已不存在时视为成功
+func hideReportTarget(report *models.Report) error {
+	var err error
+	switch report.TargetType {
+	case models.ReportTargetPost:
+		if err = mysql.HidePost(report.TargetID); err == nil {
+			unindexPost(report.TargetID)
+			removeUserPost(report.TargetUserID, report.TargetID)
+		}
+	case models.ReportTargetComment:
+		err = mysql.HideComment(report.TargetID)
+	default:
+		return ErrorUnsupportedAction
+	}
+	if err == mysql.ErrorInvalidID {
+		return nil
+	}
+	return err
+}
+
+// deleteReportTarget 删除（软删除）被举报的帖子或评论，内容已不存在时视为成功
+func deleteReportTarget(report *models.Report) error {
+	switch report.TargetType {
+	case models.ReportTargetPost:
+		post, err := mysql.GetPostById(report.TargetID)
+		if err != nil {
+			if err == mysql.ErrorInvalidID {
+				return nil
+			}
+			return err
+		}
+		return removePost(post.PostID, post.AuthorID)
+	case models.ReportTargetComment:
+		comment, err := mysql.GetCommentById(report.TargetID)
+		if err != nil {
+			if err == mysql.ErrorInvalidID {
+				return nil
+			}
+			return err
+		}
+		return removeComment(comment)
+	default:
+		return ErrorUnsupportedAction
+	}
+}
+
+// banReportTarget 封禁被举报内容的作者并注销其所有会话，版主和管理员只能由管理员封禁
+func banReportTarget(role int8, report *models.Report) error {
+	if report.TargetUserID == 0 {
+		return ErrorUnsupportedAction
+	}
+	user, err := mysql.GetUserById(report.TargetUserID)
+	if err != nil {
+		return err
+	}
+	if user.Status == models.UserStatusBanned {
+		return nil
+	}
+	if user.Role != models.RoleUser && role != models.RoleAdmin {
+		return mysql.ErrorNoPermission
+	}
+	tokenVersion, err := mysql.BanUser(user.UserID)
+	if err != nil {
+		return err
+	}
+	return revokeUserTokens(user.UserID, tokenVersion)
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"go_community/internal/search"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+/*
+	搜索索引
+	1.索引保存在每个实例的内存中，服务启动时从 mysql 全量构建
+	2.帖子的发布、更新和删除在处理请求的实例上立即更新索引，同时向 redis 频道发布帖子id，
+	  其他实例收到后从 mysql 读取帖子的最新状态更新本地索引（帖子不存在或已隐藏时删除）
+	3.全量构建期间不直接处理其他实例的消息，只记录有变化的帖子id（本实例的变化照常更新索引并记录），
+	  构建完成后按 mysql 中的最新状态重新同步这些帖子，避免构建时读取的旧数据覆盖构建期间的删除
+	4.redis 连接断开期间的消息会丢失，重启实例即可重新构建
+*/
+
+const defaultSearchIndexBatchSize = 500 // 构建搜索索引时每批读取的帖子数量
+
+// searchInstanceID 当前实例的id，忽略自己发布的索引更新
+var searchInstanceID = newSearchInstanceID()
+
+// searchBuild 全量构建索引的状态，dirty 为构建期间有变化的帖子id
+var searchBuild struct {
+	sync.Mutex
+	building bool
+	dirty    map[int64]struct{}
+}
+
+// searchIndexMessage 通过 redis 频道同步的索引更新
+type searchIndexMessage struct {
+	Instance string `json:"instance"`
+	PostID   int64  `json:"post_id"`
+	Deleted  bool   `json:"deleted"`
+}
+
+// newSearchInstanceID 生成随机的实例id
+func newSearchInstanceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// StartSearchIndexer 订阅其他实例的索引更新，并在后台从 mysql 全量构建搜索索引
+// 之后帖子的发布、更新和删除在对应的业务逻辑中同步更新索引
+func StartSearchIndexer() {
+	// 订阅之前开始记录变化，构建完成前收到的消息都会在构建后重新同步
+	beginSearchBuild()
+	subscriber := redis.NewPushSubscriber()
+	if err := subscriber.Subscribe(redis.SearchIndexChannel()); err != nil {
+		zap.L().Error("subscribe search index channel failed", zap.Error(err))
+	}
+	go subscriber.Receive(func(_ string, payload []byte) {
+		onSearchIndexMessage(payload)
+	})
+
+	go func() {
+		if _, err := BuildSearchIndex(defaultSearchIndexBatchSize); err != nil {
+			zap.L().Error("build search index failed", zap.Error(err))
+		}
+		finishSearchBuild()
+	}()
+}
+
+// beginSearchBuild 开始记录有变化的帖子
+func beginSearchBuild() {
+	searchBuild.Lock()
+	defer searchBuild.Unlock()
+	searchBuild.building = true
+	searchBuild.dirty = make(map[int64]struct{})
+}
+
+// finishSearchBuild 重新同步构建期间有变化的帖子，直到没有新的变化后停止记录
+func finishSearchBuild() {
+	for {
+		searchBuild.Lock()
+		dirty := searchBuild.dirty
+		if len(dirty) == 0 {
+			searchBuild.building = false
+			searchBuild.dirty = nil
+			searchBuild.Unlock()
+			return
+		}
+		searchBuild.dirty = make(map[int64]struct{})
+		searchBuild.Unlock()
+
+		for postID := range dirty {
+			syncPostIndex(postID)
+		}
+	}
+}
+
+// markSearchDirty 构建期间记录有变化的帖子，返回是否正在构建
+func markSearchDirty(postID int64) bool {
+	searchBuild.Lock()
+	defer searchBuild.Unlock()
+	if !searchBuild.building {
+		return false
+	}
+	searchBuild.dirty[postID] = struct{}{}
+	return true
+}
+
+// BuildSearchIndex 分批读取所有正常状态的帖子并添加到搜索索引，返回索引的帖子数量
+func BuildSearchIndex(batchSize int64) (count int, err error) {
+	var lastID int64
+	for {
+		posts, err := mysql.GetPostListAfterID(lastID, batchSize)
+		if err != nil {
+			zap.L().Error("mysql.GetPostListAfterID failed",
+				zap.Int64("last_id", lastID),
+				zap.Error(err))
+			return count, err
+		}
+		if len(posts) == 0 {
+			zap.L().Info("build search index success", zap.Int("count", count))
+			return count, nil
+		}
+		for _, post := range posts {
+			addToIndex(post)
+		}
+		count += len(posts)
+		lastID = posts[len(posts)-1].PostID
+	}
+}
+
+// indexPost 添加或更新帖子的搜索索引，并通知其他实例
+func indexPost(post *models.Post) {
+	addToIndex(post)
+	markSearchDirty(post.PostID)
+	publishSearchIndex(post.PostID, false)
+}
+
+// unindexPost 删除帖子的搜索索引，并通知其他实例
+func unindexPost(postID int64) {
+	removeFromIndex(postID)
+	markSearchDirty(postID)
+	publishSearchIndex(postID, true)
+}
+
+// publishSearchIndex 发布索引更新，失败时只记录日志
+func publishSearchIndex(postID int64, deleted bool) {
+	msg, err := json.Marshal(&searchIndexMessage{
+		Instance: searchInstanceID,
+		PostID:   postID,
+		Deleted:  deleted,
+	})
+	if err != nil {
+		zap.L().Error("json.Marshal search index message failed", zap.Error(err))
+		return
+	}
+	if err := redis.Publish(redis.SearchIndexChannel(), msg); err != nil {
+		zap.L().Error("redis.Publish search index message failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err))
+	}
+}
+
+// onSearchIndexMessage 处理其他实例发布的索引更新
+func onSearchIndexMessage(payload []byte) {
+	msg := new(searchIndexMessage)
+	if err := json.Unmarshal(payload, msg); err != nil {
+		zap.L().Error("json.Unmarshal search index message failed", zap.Error(err))
+		return
+	}
+	if msg.Instance == searchInstanceID {
+		return
+	}
+	// 正在构建时等构建完成后再同步
+	if markSearchDirty(msg.PostID) {
+		return
+	}
+	if msg.Deleted {
+		removeFromIndex(msg.PostID)
+		return
+	}
+	syncPostIndex(msg.PostID)
+}
+
+// syncPostIndex 以 mysql 中的最新状态更新本地索引，消息乱序时也不会索引已删除的帖子
+func syncPostIndex(postID int64) {
+	post, err := mysql.GetPostById(postID)
+	if err == mysql.ErrorInvalidID {
+		removeFromIndex(postID)
+		return
+	}
+	if err != nil {
+		zap.L().Error("mysql.GetPostById failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err))
+		return
+	}
+	addToIndex(post)
+}
+
+// addToIndex 添加或更新本地的搜索索引，失败时只记录日志
+func addToIndex(post *models.Post) {
+	err := search.Index(&search.Document{
+		ID:          post.PostID,
+		CommunityID: post.CommunityID,
+		AuthorID:    post.AuthorID,
+		Title:       post.Title,
+		Content:     post.Content,
+		CreateTime:  post.CreateTime,
+	})
+	if err != nil {
+		zap.L().Error("search.Index failed",
+			zap.Int64("post_id", post.PostID),
+			zap.Error(err))
+	}
+}
+
+// removeFromIndex 删除本地的搜索索引，失败时只记录日志
+func removeFromIndex(postID int64) {
+	if err := search.Delete(postID); err != nil {
+		zap.L().Error("search.Delete failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err))
+	}
+}
+
+// PostSearch 搜索帖子：按 BM25 相关度排序，返回标题高亮及正文摘要
+func PostSearch(p *models.ParamPostList, viewerID int64) (data *models.ApiPostDetailRes, err error) {
+	// 初始化返回数据结构
+	data = &models.ApiPostDetailRes{
+		Page: models.Page{
+			Page: p.Page,
+			Size: p.Size,
+		},
+		List: make([]*models.ApiPostDetail, 0),
+	}
+
+	// 结束日期包含当天
+	q := &search.Query{
+		Keywords:    p.Search,
+		CommunityID: p.CommunityID,
+		AuthorID:    p.AuthorID,
+		StartTime:   p.StartDate,
+		Page:        p.Page,
+		Size:        p.Size,
+	}
+	if !p.EndDate.IsZero() {
+		q.EndTime = p.EndDate.Add(24 * time.Hour)
+	}
+	result, err := search.Search(q)
+	if err != nil {
+		return nil, err
+	}
+	data.Page.Total = result.Total
+	if len(result.Hits) == 0 {
+		return data, nil
+	}
+
+	// 根据搜索结果的 id 在 mysql 中查询帖子详细信息（保持相关度顺序）
+	ids := make([]string, 0, len(result.Hits))
+	hits := make(map[int64]*search.Hit, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, strconv.FormatInt(hit.ID, 10))
+		hits[hit.ID] = hit
+	}
+	posts, err := mysql.GetPostListByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询出来的帖子id列表传入到redis接口获取帖子的投票数据
+	voteData, err := getPostVoteCounts(getPostIds(posts), viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组合数据
+	for idx, post := range posts {
+		// 根据作者id查询作者信息
+		user, err := mysql.GetUserById(post.AuthorID)
+		if err != nil {
+			zap.L().Error("mysql.GetUserById(post.AuthorID) failed",
+				zap.Int64("author_id", post.AuthorID),
+				zap.Error(err))
+			continue
+		}
+
+		// 根据社区id查询社区详细信息
+		community, err := mysql.GetCommunityDetailById(post.CommunityID)
+		if err != nil {
+			zap.L().Error("mysql.GetCommunityDetailById(post.CommunityID) failed",
+				zap.Int64("community_id", post.CommunityID),
+				zap.Error(err))
+			continue
+		}
+
+		// 接口数据拼接
+		hit := hits[post.PostID]
+		postDetail := &models.ApiPostDetail{
+			AuthorName:      user.UserName,
+			AuthorAvatar:    user.GetAvatarURL(),
+			VoteNum:         voteData[idx].UpVotes,
+			VoteCount:       *voteData[idx],
+			Post:            post,
+			CommunityDetail: community,
+			Highlight: &models.PostHighlight{
+				Title:   hit.Title,
+				Snippet: hit.Snippet,
+				Score:   hit.Score,
+			},
+		}
+		data.List = append(data.List, postDetail)
+	}
+	return data, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	mysql "go_community/internal/dao/mysql"
+	"go_community/internal/models"
+	"go_community/pkg/sensitive"
+	"go_community/pkg/snowflake"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const maxReviewWordsLength = 512 // 系统举报中敏感词的最大长度，与 report.detail 一致
+
+var ErrorSensitiveWord = errors.New("内容包含敏感词")
+
+// filterContent 过滤帖子、评论等正文内容，mask 词库命中的敏感词直接替换 texts 中的内容
+// 命中 reject 词库时返回 ErrorSensitiveWord；命中 review 词库时返回命中的敏感词，保存后调用 submitReview
+func filterContent(texts ...*string) (reviewWords []string, err error) {
+	var review bool
+	for _, text := range texts {
+		result := sensitive.Check(*text)
+		if !result.Hit() {
+			continue
+		}
+		if result.Reject {
+			return nil, ErrorSensitiveWord
+		}
+		*text = result.Text
+		review = review || result.Review
+		reviewWords = append(reviewWords, result.Words...)
+	}
+	if !review {
+		return nil, nil
+	}
+	return reviewWords, nil
+}
+
+// filterName 过滤用户名、社区名称等标识，命中 reject 或 mask 词库时都拒绝
+func filterName(name string) (reviewWords []string, err error) {
+	result := sensitive.Check(name)
+	if result.Reject || result.Masked {
+		return nil, ErrorSensitiveWord
+	}
+	if result.Review {
+		return result.Words, nil
+	}
+	return nil, nil
+}
+
+// submitReview 内容保存后以系统的名义举报，加入人工审核队列，失败时只记录日志
+func submitReview(targetType int8, targetID, userID int64, words []string) {
+	if len(words) == 0 {
+		return
+	}
+	report := &models.Report{
+		ReportID:     snowflake.GetID(),
+		TargetType:   targetType,
+		TargetID:     targetID,
+		TargetUserID: userID,
+		Reason:       models.ReportReasonSensitive,
+		Detail:       truncateWords(words),
+	}
+	if err := mysql.CreateReport(report); err != nil {
+		zap.L().Error("mysql.CreateReport failed",
+			zap.Int8("target_type", targetType),
+			zap.Int64("target_id", targetID),
+			zap.Error(err))
+	}
+}
+
+// truncateWords 去重后拼接敏感词，超出长度时截断
+func truncateWords(words []string) string {
+	seen := make(map[string]bool, len(words))
+	unique := make([]string, 0, len(words))
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+	s := strings.Join(unique, ",")
+	for utf8.RuneCountInString(s) > maxReviewWordsLength {
+		unique = unique[:len(unique)-1]
+		s = strings.Join(unique, ",")
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	mysql "go_community/internal/dao/mysql"
+	redis "go_community/internal/dao/redis"
+	"go_community/internal/models"
+	"go_community/pkg/jwt"
+	"go_community/pkg/snowflake"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrorInvalidRefreshToken = errors.New("无效的 refresh token")
+	ErrorTokenRevoked        = errors.New("token 已失效")
+)
+
+// newSession 创建登录会话并签发 token
+func newSession(user *models.User) error {
+	sessionID := snowflake.GetIDStr()
+	refreshID := snowflake.GetIDStr()
+	accessToken, refreshToken, err := jwt.GenToken(user.UserID, user.Role, user.TokenVersion, sessionID, refreshID)
+	if err != nil {
+		return err
+	}
+	if err := redis.CreateSession(user.UserID, sessionID, refreshID, jwt.RefreshTokenExpire()); err != nil {
+		zap.L().Error("redis.CreateSession failed",
+			zap.Int64("user_id", user.UserID),
+			zap.Error(err))
+		return err
+	}
+	user.AccessToken = accessToken
+	user.RefreshToken = refreshToken
+	return nil
+}
+
+// RefreshToken 使用 refresh token 签发新的 token，旧的 refresh token 随即失效
+func RefreshToken(rToken string) (newAToken, newRToken string, err error) {
+	claims, err := jwt.ParseRefreshToken(rToken)
+	if err != nil {
+		return "", "", ErrorInvalidRefreshToken
+	}
+
+	// 使用用户最新的角色签发 access token
+	user, err := mysql.GetUserById(claims.UserID)
+	if err != nil {
+		if err == mysql.ErrorUserNotExist {
+			return "", "", ErrorInvalidRefreshToken
+		}
+		return "", "", err
+	}
+	// 修改密码前签发的 refresh token 已失效
+	if claims.TokenVersion != user.TokenVersion {
+		return "", "", ErrorInvalidRefreshToken
+	}
+	refreshID := snowflake.GetIDStr()
+	newAToken, newRToken, err = jwt.GenToken(user.UserID, user.Role, user.TokenVersion, claims.SessionID, refreshID)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 轮换会话中的 refresh token id
+	err = redis.RotateSession(user.UserID, claims.SessionID, claims.Id, refreshID, jwt.RefreshTokenExpire())
+	if err == redis.ErrorRefreshTokenReused {
+		zap.L().Warn("refresh token reused, session revoked",
+			zap.Int64("user_id", user.UserID),
+			zap.String("session_id", claims.SessionID))
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return newAToken, newRToken, nil
+}
+
+// CheckSession 检查 access token 所属的会话及 token 版本是否有效
+// 会话已注销或 token 版本已过期时返回 ErrorTokenRevoked
+func CheckSession(userID int64, sessionID string, tokenVersion int64) error {
+	if err := redis.CheckSession(userID, sessionID); err != nil {
+		if err == redis.ErrorSessionNotExist {
+			return ErrorTokenRevoked
+		}
+		return err
+	}
+	current, err := getTokenVersion(userID)
+	if err != nil {
+		return err
+	}
+	if tokenVersion != current {
+		return ErrorTokenRevoked
+	}
+	return nil
+}
+
+// getTokenVersion 获取用户当前的 token 版本，优先读取 redis 缓存
+func getTokenVersion(userID int64) (int64, error) {
+	tokenVersion, ok, err := redis.GetTokenVersion(userID)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		return tokenVersion, nil
+	}
+	if tokenVersion, err = mysql.GetUserTokenVersion(userID); err != nil {
+		return 0, err
+	}
+	if err := redis.SetTokenVersion(userID, tokenVersion); err != nil {
+		zap.L().Error("redis.SetTokenVersion failed",
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+	return tokenVersion, nil
+}
+
+// revokeUserTokens token 版本递增后，使用户之前签发的所有 token 失效
+func revokeUserTokens(userID int64, tokenVersion int64) error {
+	if err := redis.SetTokenVersion(userID, tokenVersion); err != nil {
+		return err
+	}
+	_, err := redis.DeleteUserSessions(userID)
+	return err
+}
+
+// Logout 注销当前会话
+func Logout(userID int64, sessionID string) error {
+	return redis.DeleteSession(userID, sessionID)
+}
+
+// LogoutAll 注销用户的所有会话（所有设备），返回注销的会话数量
+func LogoutAll(userID int64) (int64, error) {
+	return redis.DeleteUserSessions(userID)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	redis "go_community/internal/dao/redis"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 后台定时任务的名称，用于 redis 中的锁
+const (
+	taskVoteArchive    = "vote_archive"
+	taskRankingRefresh = "ranking_refresh"
+)
+
+// runExclusive 多个实例同时运行时，同一个任务只由获取到锁的实例执行一次
+// expire 为锁的过期时间，防止实例在执行过程中退出后锁无法释放
+func runExclusive(task string, expire time.Duration, run func()) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		zap.L().Error("generate task lock owner failed", zap.String("task", task), zap.Error(err))
+		return
+	}
+	owner := hex.EncodeToString(b)
+	ok, err := redis.AcquireTaskLock(task, owner, expire)
+	if err != nil {
+		zap.L().Error("redis.AcquireTaskLock failed", zap.String("task", task), zap.Error(err))
+		return
+	}
+	if !ok {
+		zap.L().Debug("task is running on another instance", zap.String("task", task))
+		return
+	}
+	defer func() {
+		if err := redis.ReleaseTaskLock(task, owner); err != nil {
+			zap.L().Error("redis.ReleaseTaskLock failed", zap.String("task", task), zap.Error(err))
+		}
+	}()
+	run()
+}
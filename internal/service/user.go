@@ -5,23 +5,33 @@ import (
 	mysql "go_community/internal/dao/mysql"
 	"go_community/internal/models"
 	pkg_file "go_community/pkg/file"
-	"go_community/pkg/jwt"
 	"go_community/pkg/snowflake"
 	"io"
 	"mime/multipart"
 	"os"
 	"path"
 	"strings"
+
+	"go.uber.org/zap"
 )
 
 // 存放业务逻辑的代码
 
 // SignUp 注册业务逻辑
 func SignUp(p *models.ParamSignUp) (err error) {
+	// 过滤用户名中的敏感词
+	reviewWords, err := filterName(p.UserName)
+	if err != nil {
+		return err
+	}
 	// 判断用户是否存在
 	if err := mysql.CheckUserExist(p.UserName); err != nil {
 		return err
 	}
+	// 判断邮箱是否已被注册
+	if err := mysql.CheckEmailExist(p.Email); err != nil {
+		return err
+	}
 	// 生成 UID
 	UserID := snowflake.GetID()
 	// 构造一个 User 实例
@@ -29,30 +39,38 @@ func SignUp(p *models.ParamSignUp) (err error) {
 		UserID:   UserID,
 		UserName: p.UserName,
 		Password: p.Password,
+		Email:    p.Email,
 	}
 	// 保存进数据库
-	return mysql.InsertUser(user)
-	// redis.xxx
+	if err := mysql.InsertUser(user); err != nil {
+		return err
+	}
+	submitReview(models.ReportTargetUser, user.UserID, user.UserID, reviewWords)
+	// 发送验证邮件，失败时用户可以重新发送
+	if err := sendVerifyEmail(user); err != nil {
+		zap.L().Error("sendVerifyEmail failed",
+			zap.Int64("user_id", user.UserID),
+			zap.Error(err))
+	}
+	return nil
 }
 
-// Login 登录业务逻辑
-func Login(p *models.ParamLogin) (user *models.User, err error) {
+// Login 登录业务逻辑，clientIP 用于统计登录失败次数
+func Login(p *models.ParamLogin, clientIP string) (user *models.User, err error) {
 	user = &models.User{
 		UserName: p.UserName,
 		Password: p.Password,
 	}
-	// 用户登录，传递的是指针
-	if err := mysql.Login(user); err != nil {
+	// 用户登录，传递的是指针；连续失败次数过多时锁定
+	if err := guardLogin(p.UserName, clientIP, func() error {
+		return mysql.Login(user)
+	}); err != nil {
 		return nil, err
 	}
-	// 生成 JWT token
-	//return jwt.GenToken(user.UserID)
-	accessToken, refreshToken, err := jwt.GenToken(user.UserID)
-	if err != nil {
-		return
+	// 创建登录会话并生成 JWT token
+	if err := newSession(user); err != nil {
+		return nil, err
 	}
-	user.AccessToken = accessToken
-	user.RefreshToken = refreshToken
 	return
 }
 
@@ -71,24 +89,66 @@ func GetUserInfo(UserID int64) (*models.User, error) {
 
 // UpdateUserName 更新用户名
 func UpdateUserName(UserID int64, p *models.ParamUpdateUser) error {
+	// 过滤用户名中的敏感词
+	reviewWords, err := filterName(p.Username)
+	if err != nil {
+		return err
+	}
+
 	// 检查用户名是否已存在
 	if err := mysql.CheckUserExist(p.Username); err != nil {
 		return err
 	}
 
 	// 更新用户名
-	return mysql.UpdateUserName(UserID, p)
+	if err := mysql.UpdateUserName(UserID, p); err != nil {
+		return err
+	}
+	submitReview(models.ReportTargetUser, UserID, UserID, reviewWords)
+	return nil
 }
 
-// UpdatePassword 修改密码
-func UpdatePassword(UserID int64, p *models.ParamUpdatePassword) error {
+// UpdateUserRole 修改用户角色（仅管理员可调用）
+// 角色保存在 token 中，修改后注销该用户的所有会话，使新角色立即生效
+func UpdateUserRole(UserID int64, role int8) error {
+	// 检查用户是否存在
+	if _, err := mysql.GetUserById(UserID); err != nil {
+		return err
+	}
+
+	// 更新角色
+	tokenVersion, changed, err := mysql.UpdateUserRole(UserID, role)
+	if err != nil || !changed {
+		return err
+	}
+	return revokeUserTokens(UserID, tokenVersion)
+}
+
+// UpdatePassword 修改密码，之前签发的所有 token 失效，返回当前设备新的 token
+func UpdatePassword(UserID int64, p *models.ParamUpdatePassword) (*models.User, error) {
 	// 验证旧密码是否正确
 	if err := mysql.CheckPassword(UserID, p.OldPassword); err != nil {
-		return err
+		return nil, err
 	}
 
 	// 更新密码
-	return mysql.UpdatePassword(UserID, p.NewPassword)
+	tokenVersion, err := mysql.UpdatePassword(UserID, p.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	if err := revokeUserTokens(UserID, tokenVersion); err != nil {
+		return nil, err
+	}
+
+	// 为当前设备创建新的登录会话
+	user, err := mysql.GetUserById(UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := newSession(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 // UpdateAvatar 更新用户头像
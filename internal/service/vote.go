@@ -2,16 +2,18 @@ package service
 
 import (
 	"errors"
+	mysql "go_community/internal/dao/mysql"
 	"go_community/internal/dao/redis"
 	"go_community/internal/models"
+	"go_community/pkg/eventbus"
 	"strconv"
 
 	"go.uber.org/zap"
 )
 
 const (
-	TypePost    = 1 // 帖子
-	TypeComment = 2 // 评论
+	TypePost    = models.VoteTargetPost    // 帖子
+	TypeComment = models.VoteTargetComment // 评论
 )
 
 // VoteForTarget 为帖子或评论投票
@@ -25,16 +27,50 @@ func VoteForTarget(userID int64, p *models.ParamVoteData) (voteNum int64, err er
 	// 根据目标类型调用不同的投票函数
 	switch p.TargetType {
 	case TypePost:
-		return redis.VoteForPost(
+		voteNum, err = redis.VoteForPost(
 			strconv.FormatInt(userID, 10),
 			strconv.FormatInt(p.TargetID, 10),
 			float64(p.Direction))
 	case TypeComment:
-		return redis.VoteForComment(
+		// 一级评论需要同时更新所属帖子的评论排序
+		comment, err := mysql.GetCommentById(p.TargetID)
+		if err != nil {
+			return 0, err
+		}
+		var postID string
+		if comment.ParentID == 0 {
+			postID = strconv.FormatInt(comment.PostID, 10)
+		}
+		voteNum, err = redis.VoteForComment(
 			strconv.FormatInt(userID, 10),
 			strconv.FormatInt(p.TargetID, 10),
+			postID,
 			float64(p.Direction))
+		if err != nil {
+			return 0, err
+		}
 	default:
 		return 0, errors.New("无效的投票目标类型")
 	}
+	if err != nil {
+		return 0, err
+	}
+
+	eventbus.Publish(models.TopicVoted, &models.VotedEvent{
+		TargetType: p.TargetType,
+		TargetID:   p.TargetID,
+		UserID:     userID,
+		Direction:  p.Direction,
+		UpVotes:    voteNum,
+	})
+	return voteNum, nil
+}
+
+// RetractVote 撤销对帖子或评论的投票，返回最新点赞数
+func RetractVote(userID int64, p *models.ParamRetractVote) (voteNum int64, err error) {
+	return VoteForTarget(userID, &models.ParamVoteData{
+		TargetID:   p.TargetID,
+		TargetType: p.TargetType,
+		Direction:  0,
+	})
 }
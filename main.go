@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"go.uber.org/zap"
 	"go_community/global"
@@ -9,6 +10,10 @@ import (
 	"go_community/internal/dao/redis"
 	"go_community/internal/middlewares"
 	"go_community/internal/routers"
+	"go_community/internal/service"
+	"go_community/pkg/jwt"
+	"go_community/pkg/mail"
+	"go_community/pkg/sensitive"
 	"go_community/pkg/snowflake"
 )
 
@@ -23,6 +28,12 @@ import (
 // @BasePath /api/v1
 
 func main() {
+	// 命令行参数
+	// go_community -rebuild-redis [-batch-size 500]：从 MySQL 重建 Redis 中的排序数据后退出
+	rebuildRedis := flag.Bool("rebuild-redis", false, "rebuild redis ranking data from mysql and exit")
+	batchSize := flag.Int64("batch-size", 500, "batch size used by -rebuild-redis")
+	flag.Parse()
+
 	// 1. 加载配置
 	if err := global.Init(); err != nil {
 		fmt.Printf("init settings failed, err:%v\n", err)
@@ -54,14 +65,57 @@ func main() {
 		fmt.Printf("init snowflake failed, err:%v\n", err)
 		return
 	}
+	// 加载 JWT 签名密钥
+	if err := jwt.Init(global.Conf.JWT); err != nil {
+		fmt.Printf("init jwt failed, err:%v\n", err)
+		return
+	}
+	// 初始化邮件发送
+	if err := mail.Init(global.Conf.Mail); err != nil {
+		fmt.Printf("init mail failed, err:%v\n", err)
+		return
+	}
+	// 加载敏感词库，配置文件修改后重新加载
+	if err := sensitive.Init(global.Conf.Sensitive); err != nil {
+		fmt.Printf("init sensitive words failed, err:%v\n", err)
+		return
+	}
+	global.OnConfigChange(func() {
+		if err := sensitive.Init(global.Conf.Sensitive); err != nil {
+			zap.L().Error("reload sensitive words failed", zap.Error(err))
+		}
+	})
 	// 初始化gin框架内置的校验器使用的翻译器
 	if err := controller.InitTrans("zh"); err != nil {
 		fmt.Printf("init validator trans failed, err:%v\n", err)
 		return
 	}
+	// 重建 Redis 数据（冷启动/恢复），完成后退出
+	if *rebuildRedis {
+		if err := service.RebuildRedis(*batchSize); err != nil {
+			fmt.Printf("rebuild redis failed, err:%v\n", err)
+		}
+		return
+	}
+	// 启动后台投票数据归档任务
+	service.StartVoteArchiver()
+	// 启动后台排序分数刷新任务
+	service.StartRankingRefresher()
+	// 构建帖子搜索索引
+	service.StartSearchIndexer()
+	// 订阅需要生成通知的事件
+	service.StartNotifier()
+	// 启动实时推送
+	service.StartPusher()
+	// 订阅发帖事件，推送到粉丝的关注动态
+	service.StartFeedDispatcher()
 	// 5. 注册路由
-	r := routers.SetupRouter(global.Conf.Mode)
-	err := r.Run(fmt.Sprintf(":%d", global.Conf.Port))
+	r, err := routers.SetupRouter(global.Conf.Mode)
+	if err != nil {
+		fmt.Printf("setup router failed, err:%v\n", err)
+		return
+	}
+	err = r.Run(fmt.Sprintf(":%d", global.Conf.Port))
 	if err != nil {
 		fmt.Printf("run server failed, err:%v\n", err)
 		return
@@ -0,0 +1,134 @@
+package eventbus
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+/*
+	进程内事件总线
+	1.业务代码通过 Publish 发布事件，不需要知道有哪些订阅者（通知、推送等）
+	2.事件放入缓冲队列后由固定数量的 worker 异步分发，不阻塞发布者；队列已满时丢弃并记录日志
+	3.订阅者的 panic 会被恢复并记录日志，不影响其他订阅者
+*/
+
+// 默认的 worker 数量及队列长度
+const (
+	defaultWorkers   = 4
+	defaultQueueSize = 1024
+)
+
+// Handler 事件处理函数，payload 为发布时传入的事件数据
+type Handler func(topic string, payload interface{})
+
+type message struct {
+	topic   string
+	payload interface{}
+}
+
+// Bus 事件总线
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	queue    chan message
+	wg       sync.WaitGroup
+	closed   bool
+}
+
+// New 创建事件总线并启动 worker
+func New(workers, queueSize int) *Bus {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	b := &Bus{
+		handlers: make(map[string][]Handler),
+		queue:    make(chan message, queueSize),
+	}
+	b.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go b.run()
+	}
+	return b
+}
+
+// Subscribe 订阅主题
+func (b *Bus) Subscribe(topic string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = append(b.handlers[topic], handler)
+}
+
+// Publish 发布事件，返回 false 表示总线已关闭或队列已满，事件被丢弃
+func (b *Bus) Publish(topic string, payload interface{}) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return false
+	}
+	select {
+	case b.queue <- message{topic: topic, payload: payload}:
+		return true
+	default:
+		zap.L().Warn("eventbus queue is full, event dropped", zap.String("topic", topic))
+		return false
+	}
+}
+
+// Close 停止接收新的事件，并等待队列中的事件分发完成
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+func (b *Bus) run() {
+	defer b.wg.Done()
+	for msg := range b.queue {
+		b.mu.RLock()
+		handlers := b.handlers[msg.topic]
+		b.mu.RUnlock()
+		for _, handler := range handlers {
+			b.dispatch(handler, msg)
+		}
+	}
+}
+
+// dispatch 调用订阅者，恢复订阅者的 panic
+func (b *Bus) dispatch(handler Handler, msg message) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("eventbus handler panic",
+				zap.String("topic", msg.topic),
+				zap.Any("recover", r))
+		}
+	}()
+	handler(msg.topic, msg.payload)
+}
+
+// 默认的事件总线，业务代码使用包级函数
+var defaultBus = New(defaultWorkers, defaultQueueSize)
+
+// Subscribe 订阅默认事件总线的主题
+func Subscribe(topic string, handler Handler) {
+	defaultBus.Subscribe(topic, handler)
+}
+
+// Publish 向默认事件总线发布事件
+func Publish(topic string, payload interface{}) bool {
+	return defaultBus.Publish(topic, payload)
+}
+
+// Close 关闭默认事件总线，等待队列中的事件分发完成
+func Close() {
+	defaultBus.Close()
+}
@@ -0,0 +1,69 @@
+// eventbus 单元测试
+
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	b := New(2, 16)
+	var mu sync.Mutex
+	received := make(map[string][]interface{})
+	handler := func(topic string, payload interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[topic] = append(received[topic], payload)
+	}
+	b.Subscribe("a", handler)
+	b.Subscribe("a", handler)
+	b.Subscribe("b", handler)
+
+	assert.True(t, b.Publish("a", 1))
+	assert.True(t, b.Publish("b", 2))
+	assert.True(t, b.Publish("c", 3)) // 没有订阅者
+	b.Close()
+
+	// 每个订阅者都会收到事件
+	assert.Equal(t, []interface{}{1, 1}, received["a"])
+	assert.Equal(t, []interface{}{2}, received["b"])
+	assert.Empty(t, received["c"])
+
+	// 关闭后不再接收事件
+	assert.False(t, b.Publish("a", 4))
+}
+
+func TestHandlerPanic(t *testing.T) {
+	b := New(1, 16)
+	var count int32
+	b.Subscribe("a", func(string, interface{}) { panic("boom") })
+	b.Subscribe("a", func(string, interface{}) { atomic.AddInt32(&count, 1) })
+
+	b.Publish("a", nil)
+	b.Publish("a", nil)
+	b.Close()
+
+	// panic 不影响其他订阅者和后续事件
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestQueueFull(t *testing.T) {
+	b := New(1, 1)
+	block := make(chan struct{})
+	b.Subscribe("a", func(string, interface{}) { <-block })
+
+	// 第一个事件被 worker 取出后阻塞，第二个事件占满队列
+	dropped := false
+	for i := 0; i < 3; i++ {
+		if !b.Publish("a", i) {
+			dropped = true
+		}
+	}
+	close(block)
+	b.Close()
+	assert.True(t, dropped)
+}
@@ -0,0 +1,117 @@
+package hub
+
+import (
+	"sync"
+)
+
+/*
+	本地订阅者的消息分发中心
+	1.同一个主题可以有多个订阅者，每个订阅者有自己的缓冲 channel
+	2.主题出现第一个订阅者时调用 onFirst，最后一个订阅者取消时调用 onLast，用于按需订阅外部的消息源（如 redis pub/sub）
+	3.订阅者的缓冲区已满时关闭该订阅，由客户端重新连接，避免慢速的订阅者阻塞分发或悄悄丢失消息
+*/
+
+const defaultBufferSize = 16 // 默认每个订阅者的缓冲消息数量
+
+// Hub 消息分发中心
+type Hub struct {
+	mu         sync.Mutex
+	topics     map[string]map[*Subscription]struct{}
+	bufferSize int
+	onFirst    func(topic string) error
+	onLast     func(topic string) error
+}
+
+// Subscription 订阅，从 C 中读取消息，channel 关闭表示订阅已结束
+type Subscription struct {
+	C     <-chan []byte
+	topic string
+	ch    chan []byte
+	hub   *Hub
+}
+
+// New 创建消息分发中心，onFirst 和 onLast 可以为 nil
+func New(bufferSize int, onFirst, onLast func(topic string) error) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &Hub{
+		topics:     make(map[string]map[*Subscription]struct{}),
+		bufferSize: bufferSize,
+		onFirst:    onFirst,
+		onLast:     onLast,
+	}
+}
+
+// Subscribe 订阅主题，onFirst 返回错误时订阅失败
+func (h *Hub) Subscribe(topic string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs, ok := h.topics[topic]
+	if !ok {
+		if h.onFirst != nil {
+			if err := h.onFirst(topic); err != nil {
+				return nil, err
+			}
+		}
+		subs = make(map[*Subscription]struct{})
+		h.topics[topic] = subs
+	}
+	ch := make(chan []byte, h.bufferSize)
+	sub := &Subscription{
+		C:     ch,
+		topic: topic,
+		ch:    ch,
+		hub:   h,
+	}
+	subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Broadcast 将消息分发给主题的所有订阅者，返回收到消息的订阅者数量
+func (h *Hub) Broadcast(topic string, msg []byte) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count := 0
+	for sub := range h.topics[topic] {
+		select {
+		case sub.ch <- msg:
+			count++
+		default:
+			// 缓冲区已满
+			h.remove(sub)
+		}
+	}
+	return count
+}
+
+// Count 主题当前的订阅者数量
+func (h *Hub) Count(topic string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// Close 取消订阅，可以重复调用
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// remove 移除订阅者并关闭其 channel，调用前需要持有锁
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.topics[sub.topic]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.ch)
+	if len(subs) == 0 {
+		delete(h.topics, sub.topic)
+		if h.onLast != nil {
+			// 取消外部订阅失败只会多收到一些消息，不影响本地订阅
+			_ = h.onLast(sub.topic)
+		}
+	}
+}
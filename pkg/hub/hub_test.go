@@ -0,0 +1,80 @@
+// hub 单元测试
+
+package hub
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcast(t *testing.T) {
+	h := New(4, nil, nil)
+	a1, err := h.Subscribe("a")
+	require.NoError(t, err)
+	a2, err := h.Subscribe("a")
+	require.NoError(t, err)
+	b, err := h.Subscribe("b")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, h.Broadcast("a", []byte("1")))
+	assert.Equal(t, 0, h.Broadcast("c", []byte("2"))) // 没有订阅者
+
+	assert.Equal(t, []byte("1"), <-a1.C)
+	assert.Equal(t, []byte("1"), <-a2.C)
+	assert.Empty(t, b.C)
+}
+
+func TestFirstAndLast(t *testing.T) {
+	var events []string
+	h := New(4, func(topic string) error {
+		events = append(events, "first:"+topic)
+		return nil
+	}, func(topic string) error {
+		events = append(events, "last:"+topic)
+		return nil
+	})
+
+	s1, _ := h.Subscribe("a")
+	s2, _ := h.Subscribe("a")
+	assert.Equal(t, []string{"first:a"}, events)
+
+	s1.Close()
+	s1.Close() // 重复取消订阅
+	assert.Equal(t, 1, h.Count("a"))
+	assert.Equal(t, []string{"first:a"}, events)
+
+	s2.Close()
+	assert.Equal(t, 0, h.Count("a"))
+	assert.Equal(t, []string{"first:a", "last:a"}, events)
+
+	// 取消订阅后 channel 关闭
+	_, ok := <-s2.C
+	assert.False(t, ok)
+}
+
+func TestSubscribeError(t *testing.T) {
+	h := New(4, func(topic string) error {
+		return errors.New("subscribe failed")
+	}, nil)
+	_, err := h.Subscribe("a")
+	assert.Error(t, err)
+	assert.Equal(t, 0, h.Count("a"))
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	h := New(1, nil, nil)
+	slow, _ := h.Subscribe("a")
+
+	assert.Equal(t, 1, h.Broadcast("a", []byte("1")))
+	// 缓冲区已满，订阅被关闭
+	assert.Equal(t, 0, h.Broadcast("a", []byte("2")))
+	assert.Equal(t, 0, h.Count("a"))
+
+	assert.Equal(t, []byte("1"), <-slow.C)
+	_, ok := <-slow.C
+	assert.False(t, ok)
+	slow.Close()
+}
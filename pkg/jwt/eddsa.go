@@ -0,0 +1,49 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA Ed25519 签名算法（jwt-go v3 不支持 EdDSA，这里自行实现并注册）
+// 签名使用 ed25519.PrivateKey，验证使用 ed25519.PublicKey
+type SigningMethodEdDSA struct{}
+
+var SigningMethodEd25519 = &SigningMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEd25519.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEd25519
+	})
+}
+
+// Alg 算法名称
+func (m *SigningMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+// Verify 验证签名
+func (m *SigningMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Sign 生成签名
+func (m *SigningMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
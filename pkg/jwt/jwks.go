@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK 公开的公钥（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`           // 密钥类型：RSA/OKP
+	Kid string `json:"kid"`           // 密钥 id
+	Use string `json:"use"`           // 用途：sig
+	Alg string `json:"alg"`           // 签名算法
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 公钥指数
+	Crv string `json:"crv,omitempty"` // OKP 曲线：Ed25519
+	X   string `json:"x,omitempty"`   // OKP 公钥
+}
+
+// JWKS 公钥集合
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeys 返回密钥环中所有非对称密钥的公钥，包括只用于验证的旧密钥
+func PublicKeys() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0)}
+	if ring == nil {
+		return jwks
+	}
+	for _, key := range ring.order {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch publicKey := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeJWKValue(publicKey.N.Bytes())
+			jwk.E = encodeJWKValue(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encodeJWKValue(publicKey)
+		default:
+			// HS256 等对称密钥不公开
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// encodeJWKValue base64url 编码（无填充）
+func encodeJWKValue(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
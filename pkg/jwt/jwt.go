@@ -2,6 +2,8 @@ package jwt
 
 import (
 	"errors"
+	"go_community/global"
+	"strconv"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -12,47 +14,126 @@ import (
 // 我们这里需要额外记录一个 UserID 字段，所以要自定义结构体
 // 如果想要保存更多信息，都可以添加到这个结构体中
 type MyClaims struct {
-	UserID int64 `json:"user_id"`
+	UserID       int64  `json:"user_id"`
+	Role         int8   `json:"role"`       // 用户角色
+	TokenVersion int64  `json:"ver"`        // 用户的 token 版本，修改密码后递增，旧版本的 token 失效
+	SessionID    string `json:"sid"`        // 登录会话id，用于注销
+	TokenType    string `json:"token_type"` // token 类型，防止 refresh token 被当作 access token 使用
 	jwt.StandardClaims
 }
 
-// mySecret 用于加密的字符串
-var mySecret = []byte("夏天夏天悄悄过去")
+// RefreshClaims refresh token 的声明，绑定用户和登录会话
+// Subject 为用户id，Id（jti）为本次签发的 refresh token id，刷新后旧的 jti 失效
+type RefreshClaims struct {
+	UserID       int64  `json:"user_id"`
+	TokenVersion int64  `json:"ver"`
+	SessionID    string `json:"sid"`
+	TokenType    string `json:"token_type"`
+	jwt.StandardClaims
+}
+
+// token 类型
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// 配置文件中未设置时使用的默认值
+const (
+	defaultIssuer             = "go_community"
+	defaultAccessTokenExpire  = time.Hour * 24
+	defaultRefreshTokenExpire = time.Hour * 24 * 7
+)
+
+var (
+	ErrorNotInit      = errors.New("jwt 未初始化")
+	ErrorInvalidToken = errors.New("invalid token")
+)
+
+var (
+	ring               *KeyRing
+	issuer             = defaultIssuer
+	accessTokenExpire  = defaultAccessTokenExpire
+	refreshTokenExpire = defaultRefreshTokenExpire
+)
+
+// Init 加载签名密钥环及 token 配置，修改配置后需要重启生效
+func Init(cfg global.JWTConfig) (err error) {
+	if ring, err = NewKeyRing(cfg); err != nil {
+		return err
+	}
+	if cfg.Issuer != "" {
+		issuer = cfg.Issuer
+	}
+	if cfg.AccessTokenExpire > 0 {
+		accessTokenExpire = cfg.AccessTokenExpire
+	}
+	if cfg.RefreshTokenExpire > 0 {
+		refreshTokenExpire = cfg.RefreshTokenExpire
+	}
+	return nil
+}
 
-func keyFunc(_ *jwt.Token) (i interface{}, err error) {
-	return mySecret, nil
+func keyFunc(token *jwt.Token) (i interface{}, err error) {
+	if ring == nil {
+		return nil, ErrorNotInit
+	}
+	return ring.keyFunc(token)
 }
 
-// AccessTokenExpireDuration JWT access_token 过期时间
-const AccessTokenExpireDuration = time.Hour * 24
+// RefreshTokenExpire refresh token 的有效期，登录会话的过期时间与之相同
+func RefreshTokenExpire() time.Duration {
+	return refreshTokenExpire
+}
 
-// RefreshTokenExpireDuration JWT refresh_token 过期时间
-const RefreshTokenExpireDuration = time.Hour * 24 * 7
+// sign 使用当前签名密钥签发 token
+func sign(claims jwt.Claims) (string, error) {
+	if ring == nil {
+		return "", ErrorNotInit
+	}
+	return ring.sign(claims)
+}
 
 // GenToken 生成JWT
-func GenToken(userID int64) (aToken, rToken string, err error) {
+// tokenVersion 为用户当前的 token 版本，sessionID 为登录会话id，
+// refreshID 为 refresh token 的唯一id（jti），由调用方保存到会话中
+func GenToken(userID int64, role int8, tokenVersion int64, sessionID, refreshID string) (aToken, rToken string, err error) {
+	now := time.Now()
 	// 创建一个自己声明的数据
 	c := MyClaims{
-		userID, // 自定义字段
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(AccessTokenExpireDuration).Unix(), // 过期时间
-			Issuer:    "go_community",                                   // 签发人
+		UserID:       userID, // 自定义字段
+		Role:         role,
+		TokenVersion: tokenVersion,
+		SessionID:    sessionID,
+		TokenType:    TokenTypeAccess,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: now.Add(accessTokenExpire).Unix(), // 过期时间
+			Issuer:    issuer,                            // 签发人
 		},
 	}
 	// 加密并获得完整的编码后的字符串token
-	aToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(mySecret)
-
-	// refresh token 不需要存任何自定义数据
-	rToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(RefreshTokenExpireDuration).Unix(), // 过期时间
-		Issuer:    "go_community",                                    // 签发人
-	}).SignedString(mySecret)
+	if aToken, err = sign(c); err != nil {
+		return "", "", err
+	}
 
-	// 使用指定的secret签名并获得完整的编码后的字符串token
+	// refresh token 绑定用户和会话
+	rToken, err = sign(RefreshClaims{
+		UserID:       userID,
+		TokenVersion: tokenVersion,
+		SessionID:    sessionID,
+		TokenType:    TokenTypeRefresh,
+		StandardClaims: jwt.StandardClaims{
+			Id:        refreshID,
+			Subject:   strconv.FormatInt(userID, 10),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(refreshTokenExpire).Unix(), // 过期时间
+			Issuer:    issuer,                             // 签发人
+		},
+	})
 	return
 }
 
-// ParseToken 解析JWT
+// ParseToken 解析JWT（access token）
 func ParseToken(tokenString string) (claims *MyClaims, err error) {
 	// 解析token
 	var token *jwt.Token
@@ -62,37 +143,31 @@ func ParseToken(tokenString string) (claims *MyClaims, err error) {
 		return
 	}
 	if !token.Valid { // 校验token
-		err = errors.New("invalid token")
+		err = ErrorInvalidToken
+		return
+	}
+	if !claims.VerifyIssuer(issuer, true) || claims.TokenType != TokenTypeAccess || claims.SessionID == "" {
+		err = ErrorInvalidToken
 	}
 	return
 }
 
-// RefreshToken 刷新AccessToken
-func RefreshToken(aToken, rToken string) (newAToken, newRToken string, err error) {
-	// refresh token 无效直接返回
-	if _, err = jwt.Parse(rToken, keyFunc); err != nil {
+// ParseRefreshToken 解析 refresh token，会话是否有效由调用方检查
+func ParseRefreshToken(tokenString string) (claims *RefreshClaims, err error) {
+	var token *jwt.Token
+	claims = new(RefreshClaims)
+	token, err = jwt.ParseWithClaims(tokenString, claims, keyFunc)
+	if err != nil {
 		return
 	}
-
-	// 从旧 access token 中解析出 claims 数据
-	var claims MyClaims
-	_, err = jwt.ParseWithClaims(aToken, &claims, keyFunc)
-	// 如果 err 为 nil，表示 token 仍然有效，不需要刷新
-	if err == nil {
-		err = errors.New("token 仍然有效，不需要刷新")
+	if !token.Valid {
+		err = ErrorInvalidToken
 		return
 	}
-
-	// 检查是否是验证错误
-	v, ok := err.(*jwt.ValidationError)
-	if !ok {
-		// 不是验证错误，可能是其他类型的错误
-		return "", "", errors.New("token 解析失败")
-	}
-
-	// access token 是过期错误并且 refresh token 没有过期时，就创建一个新的 access token
-	if v.Errors == jwt.ValidationErrorExpired {
-		return GenToken(claims.UserID)
+	if !claims.VerifyIssuer(issuer, true) || claims.TokenType != TokenTypeRefresh ||
+		claims.SessionID == "" || claims.Id == "" ||
+		claims.Subject != strconv.FormatInt(claims.UserID, 10) {
+		err = ErrorInvalidToken
 	}
 	return
 }
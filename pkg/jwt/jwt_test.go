@@ -0,0 +1,213 @@
+// jwt 单元测试
+
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"go_community/global"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM 将 DER 数据以 PEM 格式写入临时文件
+func writePEM(t *testing.T, name, typ string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	data := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+// accessClaims 构造有效的 access token 声明
+func accessClaims(userID int64) MyClaims {
+	return MyClaims{
+		UserID:         userID,
+		SessionID:      "s",
+		TokenType:      TokenTypeAccess,
+		StandardClaims: jwt.StandardClaims{Issuer: defaultIssuer},
+	}
+}
+
+// initRing 初始化密钥环，测试结束后恢复
+func initRing(t *testing.T, cfg global.JWTConfig) {
+	old := ring
+	t.Cleanup(func() { ring = old })
+	require.NoError(t, Init(cfg))
+}
+
+func TestKeyRotation(t *testing.T) {
+	hsKey := global.JWTKeyConfig{KID: "hs-old", Algorithm: AlgorithmHS256, Secret: "old-secret"}
+	initRing(t, global.JWTConfig{Keys: []global.JWTKeyConfig{hsKey}})
+
+	oldToken, _, err := GenToken(1, 0, 0, "s1", "r1")
+	require.NoError(t, err)
+	claims, err := ParseToken(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), claims.UserID)
+
+	// 添加新密钥并切换，旧密钥签发的 token 仍然有效
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	require.NoError(t, err)
+	edKey := global.JWTKeyConfig{KID: "ed-new", Algorithm: AlgorithmEdDSA, PrivateKeyFile: writePEM(t, "ed.pem", "PRIVATE KEY", der)}
+	initRing(t, global.JWTConfig{ActiveKID: "ed-new", Keys: []global.JWTKeyConfig{hsKey, edKey}})
+
+	newToken, _, err := GenToken(2, 1, 0, "s2", "r2")
+	require.NoError(t, err)
+	token, err := jwt.Parse(newToken, keyFunc)
+	require.NoError(t, err)
+	assert.Equal(t, "ed-new", token.Header["kid"])
+	assert.Equal(t, "EdDSA", token.Method.Alg())
+
+	_, err = ParseToken(oldToken)
+	assert.NoError(t, err)
+
+	// 删除旧密钥后，旧 token 失效
+	initRing(t, global.JWTConfig{Keys: []global.JWTKeyConfig{edKey}})
+	_, err = ParseToken(oldToken)
+	assert.Error(t, err)
+	_, err = ParseToken(newToken)
+	assert.NoError(t, err)
+}
+
+func TestRS256AndJWKS(t *testing.T) {
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateFile := writePEM(t, "rs.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPrivate))
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	require.NoError(t, err)
+	publicFile := writePEM(t, "rs.pub.pem", "PUBLIC KEY", publicDER)
+
+	initRing(t, global.JWTConfig{Keys: []global.JWTKeyConfig{
+		{KID: "rs-1", Algorithm: AlgorithmRS256, PrivateKeyFile: privateFile},
+		{KID: "rs-0", Algorithm: AlgorithmRS256, PublicKeyFile: publicFile}, // 只用于验证的旧密钥
+		{KID: "hs-0", Algorithm: AlgorithmHS256, Secret: "secret"},
+	}})
+
+	aToken, _, err := GenToken(3, 2, 0, "s3", "r3")
+	require.NoError(t, err)
+	claims, err := ParseToken(aToken)
+	require.NoError(t, err)
+	assert.Equal(t, int8(2), claims.Role)
+
+	// HS256 密钥不公开
+	jwks := PublicKeys()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "rs-1", jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.Equal(t, jwks.Keys[0].N, jwks.Keys[1].N)
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	initRing(t, global.JWTConfig{Keys: []global.JWTKeyConfig{
+		{KID: "hs", Algorithm: AlgorithmHS256, Secret: "secret"},
+	}})
+
+	// kid 对应 HS256 密钥，但头部声明的算法不同
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, accessClaims(1))
+	token.Header["kid"] = "hs"
+	s, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = ParseToken(s)
+	assert.Error(t, err)
+
+	// 未知的 kid
+	token = jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims(1))
+	token.Header["kid"] = "unknown"
+	s, err = token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = ParseToken(s)
+	assert.Error(t, err)
+
+	// 没有 kid 的旧 token 使用当前签名密钥验证
+	token = jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims(1))
+	s, err = token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = ParseToken(s)
+	assert.NoError(t, err)
+}
+
+func TestRefreshToken(t *testing.T) {
+	initRing(t, global.JWTConfig{Keys: []global.JWTKeyConfig{
+		{KID: "hs", Algorithm: AlgorithmHS256, Secret: "secret"},
+	}})
+
+	aToken, rToken, err := GenToken(4, 0, 0, "s4", "r4")
+	require.NoError(t, err)
+
+	claims, err := ParseRefreshToken(rToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), claims.UserID)
+	assert.Equal(t, "s4", claims.SessionID)
+	assert.Equal(t, "r4", claims.Id)
+	assert.Equal(t, "4", claims.Subject)
+
+	// access token 和 refresh token 不能互换使用
+	_, err = ParseRefreshToken(aToken)
+	assert.Error(t, err)
+	_, err = ParseToken(rToken)
+	assert.Error(t, err)
+}
+
+func TestTokenVersion(t *testing.T) {
+	initRing(t, global.JWTConfig{Keys: []global.JWTKeyConfig{
+		{KID: "hs", Algorithm: AlgorithmHS256, Secret: "secret"},
+	}})
+
+	aToken, rToken, err := GenToken(5, 0, 3, "s5", "r5")
+	require.NoError(t, err)
+	claims, err := ParseToken(aToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), claims.TokenVersion)
+	refreshClaims, err := ParseRefreshToken(rToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), refreshClaims.TokenVersion)
+}
+
+func TestNewKeyRingErrors(t *testing.T) {
+	_, err := NewKeyRing(global.JWTConfig{})
+	assert.Equal(t, ErrorNoKeys, err)
+
+	_, err = NewKeyRing(global.JWTConfig{ActiveKID: "missing", Keys: []global.JWTKeyConfig{
+		{KID: "hs", Algorithm: AlgorithmHS256, Secret: "secret"},
+	}})
+	assert.ErrorIs(t, err, ErrorUnknownKey)
+
+	_, err = NewKeyRing(global.JWTConfig{Keys: []global.JWTKeyConfig{
+		{KID: "hs", Algorithm: "none", Secret: "secret"},
+	}})
+	assert.ErrorIs(t, err, ErrorUnknownAlgorithm)
+}
+
+func TestHS256SecretFromEnv(t *testing.T) {
+	// 密钥为空时启动失败，不能使用默认密钥
+	_, err := NewKeyRing(global.JWTConfig{Keys: []global.JWTKeyConfig{
+		{KID: "hs", Algorithm: AlgorithmHS256},
+	}})
+	assert.Error(t, err)
+
+	cfg := global.JWTConfig{Keys: []global.JWTKeyConfig{
+		{KID: "hs", Algorithm: AlgorithmHS256, SecretEnv: "GO_COMMUNITY_TEST_JWT_SECRET"},
+	}}
+	t.Setenv("GO_COMMUNITY_TEST_JWT_SECRET", "")
+	_, err = NewKeyRing(cfg)
+	assert.ErrorContains(t, err, "GO_COMMUNITY_TEST_JWT_SECRET")
+
+	t.Setenv("GO_COMMUNITY_TEST_JWT_SECRET", "env-secret")
+	initRing(t, cfg)
+	aToken, _, err := GenToken(1, 0, 0, "s", "r")
+	require.NoError(t, err)
+	token, err := jwt.Parse(aToken, func(*jwt.Token) (interface{}, error) { return []byte("env-secret"), nil })
+	require.NoError(t, err)
+	assert.True(t, token.Valid)
+}
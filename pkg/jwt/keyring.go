@@ -0,0 +1,224 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"go_community/global"
+	"os"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+/*
+	密钥环
+	1.每个密钥有唯一的 kid，签发 token 时写入头部，验证时根据 kid 选择密钥
+	2.轮换密钥：添加新密钥并设置为 active_kid，旧密钥保留到已签发的 token 全部过期后再删除
+	3.没有 kid 的 token（密钥环之前签发的）使用当前签名密钥验证
+	4.RS256/EdDSA 的公钥通过 JWKS 接口公开，HS256 的密钥不会公开
+*/
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrorNoKeys           = errors.New("未配置 JWT 签名密钥")
+	ErrorUnknownKey       = errors.New("未知的 JWT 签名密钥")
+	ErrorNoSigningKey     = errors.New("JWT 签名密钥缺少私钥")
+	ErrorUnknownAlgorithm = errors.New("不支持的 JWT 签名算法")
+)
+
+// Key 签名密钥
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	algorithm string
+	signKey   interface{}      // 签名使用的密钥，只用于验证的旧密钥为 nil
+	verifyKey interface{}      // 验证使用的密钥
+	publicKey crypto.PublicKey // 公开到 JWKS 的公钥，HS256 为 nil
+}
+
+// KeyRing 密钥环
+type KeyRing struct {
+	keys   map[string]*Key
+	order  []*Key // 按配置顺序保存，用于生成 JWKS
+	active *Key
+}
+
+// NewKeyRing 根据配置加载密钥环
+func NewKeyRing(cfg global.JWTConfig) (*KeyRing, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, ErrorNoKeys
+	}
+	keyRing := &KeyRing{keys: make(map[string]*Key, len(cfg.Keys))}
+	for _, kc := range cfg.Keys {
+		if kc.KID == "" {
+			return nil, errors.New("JWT 签名密钥缺少 kid")
+		}
+		if _, ok := keyRing.keys[kc.KID]; ok {
+			return nil, fmt.Errorf("JWT 签名密钥 kid 重复: %s", kc.KID)
+		}
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("load jwt key %s failed: %w", kc.KID, err)
+		}
+		keyRing.keys[key.ID] = key
+		keyRing.order = append(keyRing.order, key)
+	}
+
+	activeKID := cfg.ActiveKID
+	if activeKID == "" {
+		activeKID = cfg.Keys[0].KID
+	}
+	active, ok := keyRing.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrorUnknownKey, activeKID)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("%w: %s", ErrorNoSigningKey, activeKID)
+	}
+	keyRing.active = active
+	return keyRing, nil
+}
+
+// loadKey 加载单个密钥
+func loadKey(kc global.JWTKeyConfig) (*Key, error) {
+	key := &Key{ID: kc.KID, algorithm: kc.Algorithm}
+	switch kc.Algorithm {
+	case AlgorithmHS256:
+		secret := kc.Secret
+		if secret == "" && kc.SecretEnv != "" {
+			secret = os.Getenv(kc.SecretEnv)
+		}
+		if secret == "" {
+			if kc.SecretEnv != "" {
+				return nil, fmt.Errorf("HS256 密钥不能为空，请设置环境变量 %s", kc.SecretEnv)
+			}
+			return nil, errors.New("HS256 密钥不能为空")
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.signKey = []byte(secret)
+		key.verifyKey = key.signKey
+		return key, nil
+	case AlgorithmRS256, AlgorithmEdDSA:
+		if kc.Algorithm == AlgorithmRS256 {
+			key.Method = jwt.SigningMethodRS256
+		} else {
+			key.Method = SigningMethodEd25519
+		}
+		if kc.PrivateKeyFile != "" {
+			data, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			if err := key.setPrivateKey(data); err != nil {
+				return nil, err
+			}
+		}
+		if kc.PublicKeyFile != "" {
+			data, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			if err := key.setPublicKey(data); err != nil {
+				return nil, err
+			}
+		}
+		if key.verifyKey == nil {
+			return nil, errors.New("缺少私钥或公钥文件")
+		}
+		return key, nil
+	default:
+		return nil, ErrorUnknownAlgorithm
+	}
+}
+
+// setPrivateKey 解析 PEM 格式的私钥，同时导出公钥
+func (k *Key) setPrivateKey(data []byte) error {
+	if k.algorithm == AlgorithmRS256 {
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		k.signKey = privateKey
+		k.verifyKey = &privateKey.PublicKey
+		k.publicKey = &privateKey.PublicKey
+		return nil
+	}
+	parsed, err := parsePEM(data, x509.ParsePKCS8PrivateKey)
+	if err != nil {
+		return err
+	}
+	privateKey, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return errors.New("私钥不是 Ed25519 密钥")
+	}
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	k.signKey = privateKey
+	k.verifyKey = publicKey
+	k.publicKey = publicKey
+	return nil
+}
+
+// setPublicKey 解析 PEM 格式的公钥
+func (k *Key) setPublicKey(data []byte) error {
+	if k.algorithm == AlgorithmRS256 {
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		k.verifyKey = publicKey
+		k.publicKey = publicKey
+		return nil
+	}
+	parsed, err := parsePEM(data, x509.ParsePKIXPublicKey)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return errors.New("公钥不是 Ed25519 密钥")
+	}
+	k.verifyKey = publicKey
+	k.publicKey = publicKey
+	return nil
+}
+
+// parsePEM 解码 PEM 并使用 parse 解析其中的 DER 数据
+func parsePEM(data []byte, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("无效的 PEM 数据")
+	}
+	return parse(block.Bytes)
+}
+
+// sign 使用当前签名密钥签发 token，头部写入 kid
+func (r *KeyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.active.Method, claims)
+	token.Header["kid"] = r.active.ID
+	return token.SignedString(r.active.signKey)
+}
+
+// keyFunc 根据 token 头部的 kid 选择验证密钥，并检查签名算法与密钥是否一致
+func (r *KeyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := r.active
+	if kid, ok := token.Header["kid"]; ok {
+		id, _ := kid.(string)
+		if key, ok = r.keys[id]; !ok {
+			return nil, ErrorUnknownKey
+		}
+	}
+	// 防止算法混淆攻击（例如使用 RSA 公钥作为 HMAC 密钥）
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
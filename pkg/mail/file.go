@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FileMailer 将邮件写入本地目录，每封邮件一个 .eml 文件（开发和测试环境使用）
+type FileMailer struct {
+	dir  string
+	from string
+	seq  uint64
+}
+
+// NewFileMailer 创建写入文件的邮件发送器
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send 将邮件写入文件
+func (m *FileMailer) Send(msg *Message) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+	// 文件名：时间_序号_收件人.eml
+	seq := atomic.AddUint64(&m.seq, 1)
+	name := fmt.Sprintf("%s_%d_%s.eml",
+		time.Now().Format("20060102150405"), seq, strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0644)
+}
+
+// LogMailer 只记录日志，不发送邮件
+type LogMailer struct{}
+
+// NewLogMailer 创建记录日志的邮件发送器
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send 记录邮件内容
+func (m *LogMailer) Send(msg *Message) error {
+	zap.L().Info("mail sent",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body))
+	return nil
+}
@@ -0,0 +1,74 @@
+package mail
+
+import (
+	"errors"
+	"go_community/global"
+	"sync"
+)
+
+/*
+	邮件发送
+	1.Mailer 接口屏蔽具体的发送方式，由配置文件中的 mail.driver 选择：
+		smtp: 通过 SMTP 服务器发送
+		file: 写入本地目录（开发和测试环境）
+		log:  只记录日志（默认）
+	2.业务代码通过包级函数 Send 发送邮件，测试时可以使用 SetMailer 替换
+*/
+
+// 邮件发送方式
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+var ErrorUnknownDriver = errors.New("不支持的邮件发送方式")
+
+// Message 邮件内容
+type Message struct {
+	To      string
+	Subject string
+	Body    string // 纯文本内容
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+var (
+	mu     sync.RWMutex
+	mailer Mailer = NewLogMailer()
+)
+
+// Init 根据配置创建邮件发送器
+func Init(cfg global.MailConfig) error {
+	var m Mailer
+	switch cfg.Driver {
+	case DriverSMTP:
+		m = NewSMTPMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.From)
+	case DriverFile:
+		m = NewFileMailer(cfg.Dir, cfg.From)
+	case DriverLog, "":
+		m = NewLogMailer()
+	default:
+		return ErrorUnknownDriver
+	}
+	SetMailer(m)
+	return nil
+}
+
+// SetMailer 替换邮件发送器
+func SetMailer(m Mailer) {
+	mu.Lock()
+	defer mu.Unlock()
+	mailer = m
+}
+
+// Send 发送邮件
+func Send(msg *Message) error {
+	mu.RLock()
+	m := mailer
+	mu.RUnlock()
+	return m.Send(msg)
+}
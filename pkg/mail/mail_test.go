@@ -0,0 +1,59 @@
+// mail 单元测试
+
+package mail
+
+import (
+	"go_community/global"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMailer 保存发送的邮件
+type memoryMailer struct {
+	sent []*Message
+}
+
+func (m *memoryMailer) Send(msg *Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(dir, "go_community <noreply@example.com>")
+	require.NoError(t, m.Send(&Message{To: "a@example.com", Subject: "验证你的邮箱", Body: "line1\nline2"}))
+	require.NoError(t, m.Send(&Message{To: "a@example.com", Subject: "重置你的密码", Body: "body"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "From: go_community <noreply@example.com>\r\n")
+	assert.Contains(t, content, "To: a@example.com\r\n")
+	// 中文主题使用 RFC 2047 编码
+	assert.Contains(t, content, "Subject: =?UTF-8?b?")
+	assert.True(t, strings.HasSuffix(content, "\r\n\r\nline1\r\nline2"))
+}
+
+func TestInit(t *testing.T) {
+	t.Cleanup(func() { SetMailer(NewLogMailer()) })
+
+	require.NoError(t, Init(global.MailConfig{Driver: DriverFile, Dir: t.TempDir()}))
+	assert.IsType(t, &FileMailer{}, mailer)
+	require.NoError(t, Init(global.MailConfig{}))
+	assert.IsType(t, &LogMailer{}, mailer)
+	assert.Equal(t, ErrorUnknownDriver, Init(global.MailConfig{Driver: "pigeon"}))
+
+	m := &memoryMailer{}
+	SetMailer(m)
+	require.NoError(t, Send(&Message{To: "b@example.com"}))
+	assert.Len(t, m.sent, 1)
+}
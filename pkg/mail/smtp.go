@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPMailer 通过 SMTP 服务器发送邮件（PLAIN 认证，服务器支持时使用 STARTTLS）
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer 创建 SMTP 邮件发送器
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send 发送邮件
+func (m *SMTPMailer) Send(msg *Message) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	return smtp.SendMail(m.addr, auth, from.Address, []string{msg.To}, buildMessage(m.from, msg))
+}
+
+// buildMessage 生成 RFC 5322 格式的邮件
+func buildMessage(from string, msg *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
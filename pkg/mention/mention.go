@@ -0,0 +1,50 @@
+package mention
+
+import (
+	"unicode"
+)
+
+/*
+	@提及解析
+	1.@ 之后连续的字母（包括中文）、数字、下划线和连字符为用户名
+	2.@ 前面是字母或数字时不作为提及（例如邮箱地址 a@b.com）
+	3.结果按首次出现的顺序去重，并限制最大数量，避免一条内容通知过多的用户
+*/
+
+const (
+	MaxNameLength = 64 // 用户名的最大长度，与 user 表的 username 字段一致
+	MaxMentions   = 20 // 一条内容最多解析的提及数量
+)
+
+// isNameRune 判断字符是否可以作为用户名的一部分
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+// Parse 解析文本中 @ 的用户名，按首次出现的顺序去重
+func Parse(text string) []string {
+	runes := []rune(text)
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for i := 0; i < len(runes) && len(names) < MaxMentions; i++ {
+		if runes[i] != '@' {
+			continue
+		}
+		if i > 0 && (unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isNameRune(runes[j]) {
+			j++
+		}
+		if n := j - i - 1; n > 0 && n <= MaxNameLength {
+			name := string(runes[i+1 : j])
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		i = j - 1
+	}
+	return names
+}
@@ -0,0 +1,42 @@
+// mention 单元测试
+
+package mention
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"hello @alice", []string{"alice"}},
+		{"@alice, @bob_1 and @carol-x.", []string{"alice", "bob_1", "carol-x"}},
+		{"@张三 你好，@李四！", []string{"张三", "李四"}},
+		{"@alice @alice @bob", []string{"alice", "bob"}},
+		{"mail me at foo@example.com", []string{}},
+		{"(@alice)", []string{"alice"}},
+		{"@ alone @@bob", []string{"bob"}},
+		{"no mentions", []string{}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Parse(tt.text), tt.text)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	// 超过最大长度的不是用户名
+	assert.Empty(t, Parse("@"+strings.Repeat("a", MaxNameLength+1)))
+	assert.Equal(t, []string{strings.Repeat("a", MaxNameLength)}, Parse("@"+strings.Repeat("a", MaxNameLength)))
+
+	var b strings.Builder
+	for i := 0; i < MaxMentions+5; i++ {
+		fmt.Fprintf(&b, "@user%d ", i)
+	}
+	assert.Len(t, Parse(b.String()), MaxMentions)
+}
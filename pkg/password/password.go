@@ -0,0 +1,196 @@
+package password
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go_community/global"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+/*
+	密码哈希
+	1.新密码使用自适应哈希算法（bcrypt 或 argon2id），每个密码使用随机盐值
+	2.哈希结果带算法前缀：
+		bcrypt:   $2a$<cost>$<salt+hash>
+		argon2id: $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+	3.没有前缀的 32 位十六进制字符串是旧版本的 md5 哈希，验证通过后需要重新哈希
+*/
+
+// 支持的哈希算法
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// 哈希参数的默认值，配置文件中未设置时使用
+const (
+	defaultAlgorithm     = AlgorithmBcrypt
+	defaultBcryptCost    = bcrypt.DefaultCost
+	defaultArgon2Memory  = 64 * 1024 // KiB
+	defaultArgon2Time    = 1
+	defaultArgon2Threads = 4
+	argon2SaltLength     = 16
+	argon2KeyLength      = 32
+)
+
+// legacySecret 旧版本 md5 哈希使用的固定盐值，仅用于验证未迁移的密码
+const legacySecret = "liwenzhou.com"
+
+var (
+	ErrorUnknownAlgorithm = errors.New("不支持的密码哈希算法")
+	ErrorInvalidHash      = errors.New("无效的密码哈希")
+)
+
+// argon2Params argon2id 的参数
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// passwordConfig 读取密码哈希配置，未设置的参数使用默认值
+func passwordConfig() global.PasswordConfig {
+	cfg := global.Conf.Password
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = defaultAlgorithm
+	}
+	if cfg.BcryptCost <= 0 {
+		cfg.BcryptCost = defaultBcryptCost
+	}
+	if cfg.Argon2Memory <= 0 {
+		cfg.Argon2Memory = defaultArgon2Memory
+	}
+	if cfg.Argon2Time <= 0 {
+		cfg.Argon2Time = defaultArgon2Time
+	}
+	if cfg.Argon2Threads <= 0 {
+		cfg.Argon2Threads = defaultArgon2Threads
+	}
+	return cfg
+}
+
+// Hash 使用配置的算法对密码进行哈希
+func Hash(password string) (string, error) {
+	cfg := passwordConfig()
+	switch cfg.Algorithm {
+	case AlgorithmBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	case AlgorithmArgon2id:
+		return hashArgon2id(password, argon2Params{
+			memory:  uint32(cfg.Argon2Memory),
+			time:    uint32(cfg.Argon2Time),
+			threads: uint8(cfg.Argon2Threads),
+		})
+	default:
+		return "", ErrorUnknownAlgorithm
+	}
+}
+
+// Verify 验证密码是否与哈希匹配
+// needRehash 为 true 表示哈希使用的是旧算法或旧参数，验证通过后应使用 Hash 重新哈希并保存
+func Verify(password, encoded string) (ok, needRehash bool, err error) {
+	cfg := passwordConfig()
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return false, false, nil
+		}
+		needRehash = cfg.Algorithm != AlgorithmArgon2id ||
+			params.memory != uint32(cfg.Argon2Memory) ||
+			params.time != uint32(cfg.Argon2Time) ||
+			params.threads != uint8(cfg.Argon2Threads)
+		return true, needRehash, nil
+	case strings.HasPrefix(encoded, "$2"):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return false, false, err
+		}
+		needRehash = cfg.Algorithm != AlgorithmBcrypt || cost != cfg.BcryptCost
+		return true, needRehash, nil
+	case isLegacyHash(encoded):
+		other := legacyHash(password)
+		if subtle.ConstantTimeCompare([]byte(encoded), []byte(other)) != 1 {
+			return false, false, nil
+		}
+		return true, true, nil
+	default:
+		return false, false, ErrorInvalidHash
+	}
+}
+
+// hashArgon2id 使用 argon2id 对密码进行哈希，返回 PHC 格式的字符串
+func hashArgon2id(password string, params argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.memory, params.time, params.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// decodeArgon2id 解析 PHC 格式的 argon2id 哈希
+func decodeArgon2id(encoded string) (params argon2Params, salt, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrorInvalidHash
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrorInvalidHash
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, ErrorInvalidHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, ErrorInvalidHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return params, nil, nil, ErrorInvalidHash
+	}
+	return params, salt, key, nil
+}
+
+// legacyHash 旧版本的密码哈希：md5(固定盐值 + 密码)
+func legacyHash(password string) string {
+	h := md5.New()
+	h.Write([]byte(legacySecret))
+	h.Write([]byte(password))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isLegacyHash 判断是否为旧版本的 md5 哈希
+func isLegacyHash(encoded string) bool {
+	if len(encoded) != md5.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
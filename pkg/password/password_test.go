@@ -0,0 +1,100 @@
+// password 单元测试
+
+package password
+
+import (
+	"go_community/global"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setConfig 临时修改密码哈希配置，测试结束后恢复
+func setConfig(t *testing.T, cfg global.PasswordConfig) {
+	old := global.Conf.Password
+	global.Conf.Password = cfg
+	t.Cleanup(func() { global.Conf.Password = old })
+}
+
+func TestBcrypt(t *testing.T) {
+	setConfig(t, global.PasswordConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 4})
+
+	hash, err := Hash("123456")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$04$"))
+
+	// 相同密码每次使用不同的盐值
+	other, err := Hash("123456")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	ok, needRehash, err := Verify("123456", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, needRehash)
+
+	ok, _, err = Verify("654321", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 修改计算成本后需要重新哈希
+	setConfig(t, global.PasswordConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 5})
+	ok, needRehash, err = Verify("123456", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needRehash)
+}
+
+func TestArgon2id(t *testing.T) {
+	cfg := global.PasswordConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1}
+	setConfig(t, cfg)
+
+	hash, err := Hash("123456")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, needRehash, err := Verify("123456", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, needRehash)
+
+	ok, _, err = Verify("654321", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 切换算法后需要重新哈希
+	setConfig(t, global.PasswordConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 4})
+	ok, needRehash, err = Verify("123456", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needRehash)
+
+	_, _, err = Verify("123456", "$argon2id$v=19$m=1024$bad")
+	assert.Equal(t, ErrorInvalidHash, err)
+}
+
+func TestLegacyMD5(t *testing.T) {
+	setConfig(t, global.PasswordConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 4})
+
+	// 旧版本的 md5 哈希验证通过后总是需要重新哈希
+	ok, needRehash, err := Verify("123456", legacyHash("123456"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needRehash)
+
+	ok, needRehash, err = Verify("654321", legacyHash("123456"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, needRehash)
+
+	_, _, err = Verify("123456", "plain-text")
+	assert.Equal(t, ErrorInvalidHash, err)
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	setConfig(t, global.PasswordConfig{Algorithm: "md5"})
+	_, err := Hash("123456")
+	assert.Equal(t, ErrorUnknownAlgorithm, err)
+}
@@ -0,0 +1,65 @@
+package ranking
+
+import "math"
+
+/*
+	帖子排序算法
+	推荐阅读：http://www.ruanyifeng.com/blog/algorithm/
+
+	1.Reddit hot：票数取对数，时间线性增长，新帖子天然占优，早期的票比后期的票更有分量
+	2.Hacker News：得票数除以时间的 gravity 次方，分数随时间衰减，需要定期重新计算
+	3.Wilson score：赞成率的置信区间下限，票数越少越保守，适合 "best" 排序
+	4.Controversial：赞成票与反对票越接近、总票数越多，争议程度越高
+*/
+
+// Hot Reddit hot 排序算法
+// epoch 为时间起点（Unix 时间戳），divisor 为时间权重（多少秒相当于票数增长一个数量级）
+func Hot(up, down, createTime, epoch int64, divisor float64) float64 {
+	s := float64(up - down)
+	order := math.Log10(math.Max(math.Abs(s), 1))
+	var sign float64
+	if s > 0 {
+		sign = 1
+	} else if s < 0 {
+		sign = -1
+	}
+	seconds := float64(createTime - epoch)
+	return sign*order + seconds/divisor
+}
+
+// Gravity Hacker News 重力衰减排序算法
+// ageHours 为帖子发布至今的小时数，gravity 为重力因子（越大衰减越快）
+func Gravity(up, down int64, ageHours, gravity float64) float64 {
+	if ageHours < 0 {
+		ageHours = 0
+	}
+	return float64(up-down) / math.Pow(ageHours+2, gravity)
+}
+
+// Wilson 赞成率的 Wilson 置信区间下限
+// z 为置信水平对应的统计量，1.96 对应 95% 置信度
+func Wilson(up, down int64, z float64) float64 {
+	n := float64(up + down)
+	if n == 0 {
+		return 0
+	}
+	phat := float64(up) / n
+	z2 := z * z
+	return (phat + z2/(2*n) - z*math.Sqrt((phat*(1-phat)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// Controversy 争议度：总票数的 "平衡度" 次方
+// 平衡度为较少一方的票数与较多一方的票数之比，只有赞成票或只有反对票时争议度为 0
+func Controversy(up, down int64) float64 {
+	if up <= 0 || down <= 0 {
+		return 0
+	}
+	magnitude := float64(up + down)
+	var balance float64
+	if up > down {
+		balance = float64(down) / float64(up)
+	} else {
+		balance = float64(up) / float64(down)
+	}
+	return math.Pow(magnitude, balance)
+}
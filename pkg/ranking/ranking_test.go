@@ -0,0 +1,45 @@
+// ranking 单元测试
+
+package ranking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHot(t *testing.T) {
+	const epoch, divisor = 1134028003, 45000
+
+	// 同一时间发布，净票数越多分数越高，且按数量级增长
+	assert.Greater(t, Hot(100, 0, epoch, epoch, divisor), Hot(10, 0, epoch, epoch, divisor))
+	assert.InDelta(t, 2.0, Hot(100, 0, epoch, epoch, divisor), 1e-9)
+	assert.InDelta(t, -1.0, Hot(0, 10, epoch, epoch, divisor), 1e-9)
+
+	// 票数相同，晚发布的帖子分数更高
+	assert.Greater(t, Hot(10, 0, epoch+3600, epoch, divisor), Hot(10, 0, epoch, epoch, divisor))
+}
+
+func TestGravity(t *testing.T) {
+	// 票数相同，越新的帖子分数越高
+	assert.Greater(t, Gravity(10, 0, 1, 1.8), Gravity(10, 0, 24, 1.8))
+	// 没有净票数时分数为 0
+	assert.Equal(t, 0.0, Gravity(5, 5, 1, 1.8))
+}
+
+func TestWilson(t *testing.T) {
+	assert.Equal(t, 0.0, Wilson(0, 0, 1.96))
+	// 赞成率相同，票数越多下限越高
+	assert.Greater(t, Wilson(100, 10, 1.96), Wilson(10, 1, 1.96))
+	// 下限不会超过实际赞成率
+	assert.Less(t, Wilson(10, 0, 1.96), 1.0)
+}
+
+func TestControversy(t *testing.T) {
+	assert.Equal(t, 0.0, Controversy(10, 0))
+	assert.Equal(t, 0.0, Controversy(0, 10))
+	// 票数越接近，争议度越高
+	assert.Greater(t, Controversy(50, 50), Controversy(90, 10))
+	// 对称
+	assert.Equal(t, Controversy(30, 10), Controversy(10, 30))
+}
@@ -0,0 +1,106 @@
+package sensitive
+
+/*
+	Aho-Corasick 多模式匹配
+	1.所有敏感词构建一棵字典树，按字符（rune）逐层添加，中文和英文统一处理
+	2.广度优先为每个节点计算失败指针：当前字符匹配失败时跳转到最长的可匹配后缀
+	3.节点的输出包括失败指针链上的所有敏感词，扫描一遍文本即可找出所有匹配
+*/
+
+// Match 一次匹配：Pattern 为敏感词的序号，[Start, End) 为在文本中的位置（rune 下标）
+type Match struct {
+	Pattern int
+	Start   int
+	End     int
+}
+
+type acNode struct {
+	children map[rune]int
+	fail     int
+	outputs  []int // 以该节点结尾的敏感词序号（包括失败指针链上的）
+}
+
+// Matcher Aho-Corasick 自动机，构建完成后只读，可以并发使用
+type Matcher struct {
+	nodes    []acNode
+	patterns [][]rune
+}
+
+// NewMatcher 根据敏感词构建自动机，敏感词的序号即在 patterns 中的下标，空字符串会被忽略
+func NewMatcher(patterns []string) *Matcher {
+	m := &Matcher{
+		nodes:    []acNode{{children: make(map[rune]int)}},
+		patterns: make([][]rune, len(patterns)),
+	}
+	for idx, pattern := range patterns {
+		runes := []rune(pattern)
+		m.patterns[idx] = runes
+		if len(runes) == 0 {
+			continue
+		}
+		cur := 0
+		for _, r := range runes {
+			next, ok := m.nodes[cur].children[r]
+			if !ok {
+				next = len(m.nodes)
+				m.nodes = append(m.nodes, acNode{children: make(map[rune]int)})
+				m.nodes[cur].children[r] = next
+			}
+			cur = next
+		}
+		m.nodes[cur].outputs = append(m.nodes[cur].outputs, idx)
+	}
+	m.buildFail()
+	return m
+}
+
+// buildFail 广度优先计算失败指针，并合并失败指针链上的输出
+func (m *Matcher) buildFail() {
+	queue := make([]int, 0, len(m.nodes))
+	for _, child := range m.nodes[0].children {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].children {
+			fail := m.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := m.nodes[fail].children[r]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if next, ok := m.nodes[fail].children[r]; ok && next != child {
+				m.nodes[child].fail = next
+			}
+			m.nodes[child].outputs = append(m.nodes[child].outputs, m.nodes[m.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// FindAll 查找文本中所有的敏感词（包括相互重叠的）
+func (m *Matcher) FindAll(text []rune) []Match {
+	var matches []Match
+	cur := 0
+	for i, r := range text {
+		for cur != 0 {
+			if _, ok := m.nodes[cur].children[r]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if next, ok := m.nodes[cur].children[r]; ok {
+			cur = next
+		}
+		for _, idx := range m.nodes[cur].outputs {
+			matches = append(matches, Match{
+				Pattern: idx,
+				Start:   i + 1 - len(m.patterns[idx]),
+				End:     i + 1,
+			})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,149 @@
+package sensitive
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"unicode"
+)
+
+/*
+	敏感词过滤
+	1.每个词库对应一个文件（每行一个敏感词，# 开头的行为注释）及命中后的处理方式：
+		reject: 拒绝提交
+		mask:   使用 * 替换敏感词
+		review: 正常保存，同时加入人工审核队列
+	2.匹配时忽略大小写，并跳过空白和标点符号，避免 "敏 感 词"、"敏*感*词" 之类的绕过
+	3.同一段文本命中多个词库时，处理方式按 reject > review > mask 的优先级确定
+*/
+
+// Action 命中敏感词后的处理方式
+type Action string
+
+const (
+	ActionReject Action = "reject"
+	ActionMask   Action = "mask"
+	ActionReview Action = "review"
+)
+
+const maskRune = '*'
+
+var ErrorUnknownAction = errors.New("不支持的敏感词处理方式")
+
+// List 敏感词库
+type List struct {
+	Name   string
+	Action Action
+	Words  []string
+}
+
+// Result 过滤结果
+type Result struct {
+	Text   string   // 处理后的文本（mask 的敏感词替换为 *）
+	Words  []string // 命中的敏感词（去重）
+	Reject bool     // 命中 reject 词库
+	Review bool     // 命中 review 词库
+	Masked bool     // 命中 mask 词库
+}
+
+// Hit 是否命中敏感词
+func (r *Result) Hit() bool {
+	return len(r.Words) > 0
+}
+
+// Filter 敏感词过滤器，构建完成后只读，可以并发使用
+type Filter struct {
+	matcher *Matcher
+	actions []Action // 每个敏感词对应词库的处理方式
+	words   []string
+}
+
+// NewFilter 根据词库构建过滤器
+func NewFilter(lists []*List) (*Filter, error) {
+	f := &Filter{}
+	for _, list := range lists {
+		switch list.Action {
+		case ActionReject, ActionMask, ActionReview:
+		default:
+			return nil, ErrorUnknownAction
+		}
+		for _, word := range list.Words {
+			// 与文本使用相同的规则处理敏感词
+			runes, _ := normalize(word)
+			if len(runes) == 0 {
+				continue
+			}
+			f.words = append(f.words, string(runes))
+			f.actions = append(f.actions, list.Action)
+		}
+	}
+	f.matcher = NewMatcher(f.words)
+	return f, nil
+}
+
+// Check 检查文本中的敏感词
+func (f *Filter) Check(text string) *Result {
+	result := &Result{Text: text}
+	runes, positions := normalize(text)
+	matches := f.matcher.FindAll(runes)
+	if len(matches) == 0 {
+		return result
+	}
+
+	original := []rune(text)
+	seen := make(map[int]bool)
+	for _, match := range matches {
+		if !seen[match.Pattern] {
+			seen[match.Pattern] = true
+			result.Words = append(result.Words, f.words[match.Pattern])
+		}
+		switch f.actions[match.Pattern] {
+		case ActionReject:
+			result.Reject = true
+		case ActionReview:
+			result.Review = true
+		case ActionMask:
+			result.Masked = true
+			// 只替换敏感词本身的字符，保留中间的空白和标点
+			for i := match.Start; i < match.End; i++ {
+				original[positions[i]] = maskRune
+			}
+		}
+	}
+	result.Text = string(original)
+	return result
+}
+
+// LoadList 从文件加载词库
+func LoadList(name, file string, action Action) (*List, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	list := &List{Name: name, Action: action}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list.Words = append(list.Words, line)
+	}
+	return list, scanner.Err()
+}
+
+// normalize 去掉空白和标点符号并统一为小写，返回处理后的字符及其在原文中的位置
+func normalize(text string) (runes []rune, positions []int) {
+	idx := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) && !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			runes = append(runes, unicode.ToLower(r))
+			positions = append(positions, idx)
+		}
+		idx++
+	}
+	return runes, positions
+}
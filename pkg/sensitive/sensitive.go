@@ -0,0 +1,120 @@
+package sensitive
+
+import (
+	"go_community/global"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+/*
+	全局过滤器
+	1.Init 根据配置文件中的 sensitive 部分加载词库，配置文件修改后（viper.WatchConfig）重新调用
+	2.同时监听词库文件，文件修改后重新加载；加载失败时继续使用旧的词库
+	3.业务代码通过包级函数 Check 过滤文本
+*/
+
+var (
+	mu      sync.RWMutex
+	enabled bool
+	filter  = &Filter{matcher: NewMatcher(nil)}
+	watcher *fsnotify.Watcher
+)
+
+// Init 加载词库并监听词库文件的修改
+func Init(cfg global.SensitiveConfig) error {
+	if err := Load(cfg); err != nil {
+		return err
+	}
+	return watch(cfg)
+}
+
+// Load 加载配置中的所有词库并替换当前的过滤器
+func Load(cfg global.SensitiveConfig) error {
+	lists := make([]*List, 0, len(cfg.Lists))
+	for _, lc := range cfg.Lists {
+		list, err := LoadList(lc.Name, lc.File, Action(lc.Action))
+		if err != nil {
+			return err
+		}
+		lists = append(lists, list)
+	}
+	f, err := NewFilter(lists)
+	if err != nil {
+		return err
+	}
+	SetFilter(f, cfg.Enable)
+	zap.L().Info("sensitive words loaded", zap.Int("count", len(f.words)))
+	return nil
+}
+
+// SetFilter 替换当前的过滤器
+func SetFilter(f *Filter, enable bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	filter = f
+	enabled = enable
+}
+
+// Check 检查文本中的敏感词，未启用时原样返回
+func Check(text string) *Result {
+	mu.RLock()
+	f, enable := filter, enabled
+	mu.RUnlock()
+	if !enable {
+		return &Result{Text: text}
+	}
+	return f.Check(text)
+}
+
+// watch 监听词库文件所在的目录（编辑器保存文件时通常会先删除再创建），替换之前的监听
+func watch(cfg global.SensitiveConfig) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	files := make(map[string]bool)
+	for _, lc := range cfg.Lists {
+		file := filepath.Clean(lc.File)
+		files[file] = true
+		if err := w.Add(filepath.Dir(file)); err != nil {
+			w.Close()
+			return err
+		}
+	}
+
+	mu.Lock()
+	old := watcher
+	watcher = w
+	mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if !files[filepath.Clean(event.Name)] || !event.Has(fsnotify.Write|fsnotify.Create) {
+					continue
+				}
+				if err := Load(cfg); err != nil {
+					zap.L().Error("reload sensitive words failed",
+						zap.String("file", event.Name),
+						zap.Error(err))
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				zap.L().Error("watch sensitive words failed", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
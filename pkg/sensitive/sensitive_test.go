@@ -0,0 +1,70 @@
+// sensitive 单元测试
+
+package sensitive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher([]string{"he", "she", "his", "hers", ""})
+	matches := m.FindAll([]rune("ushers"))
+	// she、he、hers 相互重叠
+	assert.ElementsMatch(t, []Match{
+		{Pattern: 1, Start: 1, End: 4},
+		{Pattern: 0, Start: 2, End: 4},
+		{Pattern: 3, Start: 2, End: 6},
+	}, matches)
+
+	// 中文
+	m = NewMatcher([]string{"敏感", "感词", "敏感词"})
+	assert.Len(t, m.FindAll([]rune("这是敏感词吗")), 3)
+	assert.Empty(t, m.FindAll([]rune("没有问题")))
+}
+
+func TestFilter(t *testing.T) {
+	f, err := NewFilter([]*List{
+		{Name: "banned", Action: ActionReject, Words: []string{"代开发票"}},
+		{Name: "profanity", Action: ActionMask, Words: []string{"笨蛋", "Fool"}},
+		{Name: "review", Action: ActionReview, Words: []string{"加微信"}},
+	})
+	require.NoError(t, err)
+
+	// 未命中
+	r := f.Check("今天天气不错")
+	assert.False(t, r.Hit())
+	assert.Equal(t, "今天天气不错", r.Text)
+
+	// 替换：忽略大小写，跳过中间的空白和标点，只替换敏感词本身
+	r = f.Check("你这个笨 蛋，FOOL!")
+	assert.True(t, r.Masked)
+	assert.False(t, r.Reject)
+	assert.Equal(t, "你这个* *，****!", r.Text)
+	assert.ElementsMatch(t, []string{"笨蛋", "fool"}, r.Words)
+
+	// 拒绝和审核
+	r = f.Check("代*开*发*票，请加微信")
+	assert.True(t, r.Reject)
+	assert.True(t, r.Review)
+
+	// 不支持的处理方式
+	_, err = NewFilter([]*List{{Name: "x", Action: "block"}})
+	assert.Equal(t, ErrorUnknownAction, err)
+}
+
+func TestLoadList(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(file, []byte("# 注释\n敏感词\n\n  test  \n"), 0644))
+
+	list, err := LoadList("test", file, ActionMask)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"敏感词", "test"}, list.Words)
+
+	_, err = LoadList("test", filepath.Join(t.TempDir(), "missing.txt"), ActionMask)
+	assert.Error(t, err)
+}
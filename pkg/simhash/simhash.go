@@ -0,0 +1,78 @@
+package simhash
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"unicode"
+)
+
+/*
+	SimHash 文本指纹：内容相近的文本指纹的汉明距离也很小，用于识别近似重复的内容
+	1.去掉空白和标点、统一小写后按 shingleSize 个字符切分（同时适用于中文和英文）
+	2.每个片段计算 64 位哈希，对应位为 1 时加权重、为 0 时减权重
+	3.各位累加结果大于 0 的位置 1，得到 64 位指纹
+*/
+
+const shingleSize = 3 // 每个片段的字符数
+
+// Fingerprint 计算文本的 64 位 SimHash 指纹，没有有效字符时返回 0
+func Fingerprint(text string) uint64 {
+	runes := normalize(text)
+	if len(runes) == 0 {
+		return 0
+	}
+	var weights [64]int
+	add := func(shingle []rune) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(string(shingle)))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	if len(runes) <= shingleSize {
+		add(runes)
+	} else {
+		for i := 0; i+shingleSize <= len(runes); i++ {
+			add(runes[i : i+shingleSize])
+		}
+	}
+
+	var fingerprint uint64
+	for i, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return fingerprint
+}
+
+// Distance 两个指纹的汉明距离（不同的位数）
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similar 两个指纹的汉明距离不超过 distance 时视为相似内容
+// 没有指纹（0）的文本无法比较，总是视为不相似
+func Similar(a, b uint64, distance int) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+	return Distance(a, b) <= distance
+}
+
+// normalize 去掉空白和标点符号并统一为小写
+func normalize(text string) []rune {
+	runes := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			continue
+		}
+		runes = append(runes, unicode.ToLower(r))
+	}
+	return runes
+}
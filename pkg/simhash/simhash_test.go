@@ -0,0 +1,47 @@
+// simhash 单元测试
+
+package simhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	// 空白、标点和大小写不影响指纹
+	assert.Equal(t, Fingerprint("Hello, World!"), Fingerprint("hello world"))
+	// 没有有效字符
+	assert.Equal(t, uint64(0), Fingerprint(" ，。!"))
+	// 短文本
+	assert.NotEqual(t, uint64(0), Fingerprint("好"))
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance(0, 0))
+	assert.Equal(t, 64, Distance(0, ^uint64(0)))
+	assert.Equal(t, 2, Distance(0b1010, 0b0000))
+}
+
+func TestSimilar(t *testing.T) {
+	a := Fingerprint("今天在社区里分享一下我学习 Go 语言的心得")
+	assert.True(t, Similar(a, a, 3))
+	assert.False(t, Similar(a, ^a, 3))
+	// 只有表情或标点的文本没有指纹，不能互相视为重复
+	assert.Equal(t, uint64(0), Fingerprint("👍"))
+	assert.Equal(t, uint64(0), Fingerprint("？？？"))
+	assert.False(t, Similar(Fingerprint("👍"), Fingerprint("？？？"), 3))
+	assert.False(t, Similar(0, a, 64))
+}
+
+func TestNearDuplicate(t *testing.T) {
+	base := "今天在社区里分享一下我学习 Go 语言并发编程的心得，goroutine 和 channel 的组合真的非常好用，推荐大家多多练习。"
+	similar := "今天在社区里分享一下我学习 Go 语言并发编程的心得，goroutine 和 channel 的组合真的非常好用，推荐大家多练习！"
+	different := "周末去爬山了，山顶的风景很美，下次打算带上相机拍一些日出的照片，有没有人推荐一下适合新手的镜头？"
+
+	near := Distance(Fingerprint(base), Fingerprint(similar))
+	far := Distance(Fingerprint(base), Fingerprint(different))
+	assert.Less(t, near, far)
+	assert.LessOrEqual(t, near, 10)
+	assert.Greater(t, far, 10)
+}
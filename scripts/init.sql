@@ -10,16 +10,20 @@ CREATE TABLE `user` (
     `id` bigint(20) NOT NULL AUTO_INCREMENT,
     `user_id` bigint(20) NOT NULL,
     `username` varchar(64) COLLATE utf8mb4_general_ci NOT NULL,
-    `password` varchar(64) COLLATE utf8mb4_general_ci NOT NULL,
+    `password` varchar(255) COLLATE utf8mb4_general_ci NOT NULL COMMENT '密码哈希(带算法前缀)',
     `email` varchar(64) COLLATE utf8mb4_general_ci,
+    `email_verified` tinyint(1) unsigned NOT NULL DEFAULT '0' COMMENT '邮箱是否已验证',
     `gender` tinyint(4) NOT NULL DEFAULT '0',
     `avatar` varchar(200) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '用户头像URL',
-    `status` tinyint(1) unsigned NOT NULL DEFAULT '1' COMMENT '状态(1正常,0删除)',
+    `role` tinyint(1) unsigned NOT NULL DEFAULT '0' COMMENT '角色(0普通用户,1版主,2管理员)',
+    `status` tinyint(1) unsigned NOT NULL DEFAULT '1' COMMENT '状态(1正常,0删除,2封禁)',
+    `token_version` int(10) unsigned NOT NULL DEFAULT '0' COMMENT 'token版本(修改密码后递增)',
     `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
     `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     PRIMARY KEY (`id`),
     UNIQUE KEY `idx_username` (`username`) USING BTREE,
-    UNIQUE KEY `idx_user_id` (`user_id`) USING BTREE
+    UNIQUE KEY `idx_user_id` (`user_id`) USING BTREE,
+    UNIQUE KEY `idx_email` (`email`) USING BTREE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
 
 
@@ -29,6 +33,7 @@ CREATE TABLE `community` (
   `community_id` bigint(20) NOT NULL,
   `community_name` varchar(128) COLLATE utf8mb4_general_ci NOT NULL,
   `introduction` varchar(256) COLLATE utf8mb4_general_ci NOT NULL,
+  `owner_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '创建者的用户id',
   `status` tinyint(1) unsigned NOT NULL DEFAULT '1' COMMENT '状态(1正常,0删除)',
   `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -36,10 +41,32 @@ CREATE TABLE `community` (
   UNIQUE KEY `idx_community_id` (`community_id`),
   UNIQUE KEY `idx_community_name` (`community_name`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
-INSERT INTO `community` VALUES ('1', '1', 'Go', 'Golang', 1, '2016-11-01 08:10:10', '2016-11-01 08:10:10');
-INSERT INTO `community` VALUES ('2', '2', 'leetcode', '刷题刷题刷题', 1, '2020-01-01 08:00:00', '2020-01-01 08:00:00');
-INSERT INTO `community` VALUES ('3', '3', 'PUBG', '大吉大利，今晚吃鸡。', 1, '2018-08-07 08:30:00', '2018-08-07 08:30:00');
-INSERT INTO `community` VALUES ('4', '4', 'LOL', '欢迎来到英雄联盟!', 1, '2016-01-01 08:00:00', '2016-01-01 08:00:00');
+INSERT INTO `community` VALUES ('1', '1', 'Go', 'Golang', 0, 1, '2016-11-01 08:10:10', '2016-11-01 08:10:10');
+INSERT INTO `community` VALUES ('2', '2', 'leetcode', '刷题刷题刷题', 0, 1, '2020-01-01 08:00:00', '2020-01-01 08:00:00');
+INSERT INTO `community` VALUES ('3', '3', 'PUBG', '大吉大利，今晚吃鸡。', 0, 1, '2018-08-07 08:30:00', '2018-08-07 08:30:00');
+INSERT INTO `community` VALUES ('4', '4', 'LOL', '欢迎来到英雄联盟!', 0, 1, '2016-01-01 08:00:00', '2016-01-01 08:00:00');
+
+DROP TABLE IF EXISTS `community_moderator`;
+CREATE TABLE `community_moderator` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `community_id` bigint(20) NOT NULL COMMENT '社区id',
+  `user_id` bigint(20) NOT NULL COMMENT '版主的用户id',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_community_user` (`community_id`, `user_id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `community_member`;
+CREATE TABLE `community_member` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `community_id` bigint(20) NOT NULL COMMENT '社区id',
+  `user_id` bigint(20) NOT NULL COMMENT '成员的用户id',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加入时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_community_user` (`community_id`, `user_id`),
+  KEY `idx_user_id` (`user_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
 
 DROP TABLE IF EXISTS `post`;
 CREATE TABLE `post` (
@@ -49,7 +76,7 @@ CREATE TABLE `post` (
   `content` varchar(8192) COLLATE utf8mb4_general_ci NOT NULL COMMENT '内容',
   `author_id` bigint(20) NOT NULL COMMENT '作者的用户id',
   `community_id` bigint(20) NOT NULL COMMENT '所属社区',
-  `status` tinyint(1) unsigned NOT NULL DEFAULT '1' COMMENT '状态(1正常,0删除)',
+  `status` tinyint(1) unsigned NOT NULL DEFAULT '1' COMMENT '状态(1正常,0删除,2隐藏)',
   `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`),
@@ -68,7 +95,7 @@ CREATE TABLE `comment` (
   `author_id` bigint(20) NOT NULL COMMENT '评论作者id',
   `reply_to_uid` bigint(20) NOT NULL DEFAULT '0' COMMENT '被回复人的用户id',
   `content` text NOT NULL COMMENT '评论内容',
-  `status` tinyint(1) unsigned NOT NULL DEFAULT '1' COMMENT '状态(1正常,0删除)',
+  `status` tinyint(1) unsigned NOT NULL DEFAULT '1' COMMENT '状态(1正常,0删除,2隐藏)',
   `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
   `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
@@ -76,4 +103,85 @@ CREATE TABLE `comment` (
   KEY `idx_post_id` (`post_id`),
   KEY `idx_author_Id` (`author_id`),
   KEY `idx_reply_to_uid` (`reply_to_uid`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+
+DROP TABLE IF EXISTS `vote_archive`;
+CREATE TABLE `vote_archive` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `target_id` bigint(20) NOT NULL COMMENT '投票目标id',
+  `target_type` tinyint(1) unsigned NOT NULL COMMENT '投票目标类型(1帖子,2评论)',
+  `up_votes` int(11) NOT NULL DEFAULT '0' COMMENT '赞成票数',
+  `down_votes` int(11) NOT NULL DEFAULT '0' COMMENT '反对票数',
+  `score` double NOT NULL DEFAULT '0' COMMENT '归档时的分数',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_target` (`target_type`, `target_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `report`;
+CREATE TABLE `report` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `report_id` bigint(20) NOT NULL COMMENT '举报id',
+  `target_type` tinyint(1) unsigned NOT NULL COMMENT '举报对象类型(1帖子,2评论,3用户,4社区)',
+  `target_id` bigint(20) NOT NULL COMMENT '举报对象id',
+  `target_user_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '被举报内容的作者id',
+  `reporter_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '举报人id(0为系统)',
+  `reason` tinyint(1) unsigned NOT NULL COMMENT '举报原因(1垃圾广告,2辱骂,3色情低俗,4违法违规,5其他,6敏感词)',
+  `detail` varchar(512) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '补充说明',
+  `status` tinyint(1) unsigned NOT NULL DEFAULT '0' COMMENT '状态(0待处理,1处理中,2已处理)',
+  `handler_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '认领/处理人id',
+  `action` tinyint(1) unsigned NOT NULL DEFAULT '0' COMMENT '处理方式(1隐藏,2删除,3驳回,4封禁作者)',
+  `note` varchar(256) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '处理备注',
+  `claim_time` timestamp NULL DEFAULT NULL COMMENT '认领时间',
+  `resolve_time` timestamp NULL DEFAULT NULL COMMENT '处理时间',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_report_id` (`report_id`),
+  KEY `idx_status` (`status`, `create_time`),
+  KEY `idx_target` (`target_type`, `target_id`),
+  KEY `idx_reporter_id` (`reporter_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `notification`;
+CREATE TABLE `notification` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `notification_id` bigint(20) NOT NULL COMMENT '通知id',
+  `user_id` bigint(20) NOT NULL COMMENT '接收通知的用户id',
+  `actor_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '触发通知的用户id(0为系统)',
+  `type` tinyint(1) unsigned NOT NULL COMMENT '通知类型(1评论,2回复,3提及,4点赞里程碑)',
+  `target_type` tinyint(1) unsigned NOT NULL COMMENT '对象类型(1帖子,2评论)',
+  `target_id` bigint(20) NOT NULL COMMENT '对象id',
+  `post_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '所属帖子id',
+  `content` varchar(256) COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '内容摘要',
+  `is_read` tinyint(1) unsigned NOT NULL DEFAULT '0' COMMENT '是否已读',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_notification_id` (`notification_id`),
+  KEY `idx_user_read` (`user_id`, `is_read`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `mention`;
+CREATE TABLE `mention` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `target_type` tinyint(1) unsigned NOT NULL COMMENT '对象类型(1帖子,2评论)',
+  `target_id` bigint(20) NOT NULL COMMENT '对象id',
+  `user_id` bigint(20) NOT NULL COMMENT '被提及的用户id',
+  `username` varchar(64) COLLATE utf8mb4_general_ci NOT NULL COMMENT '内容中的用户名',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_target_user` (`target_type`, `target_id`, `user_id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+DROP TABLE IF EXISTS `follow`;
+CREATE TABLE `follow` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `follower_id` bigint(20) NOT NULL COMMENT '粉丝的用户id',
+  `followee_id` bigint(20) NOT NULL COMMENT '被关注的用户id',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_follower_followee` (`follower_id`, `followee_id`),
+  KEY `idx_followee` (`followee_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;